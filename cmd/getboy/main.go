@@ -3,13 +3,48 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pfnilsson/getboy/internal/ui"
 )
 
 func main() {
-	program := tea.NewProgram(ui.New(), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	// "run" and "send" execute a single request without the TUI
+	if ui.IsCLICommand(os.Args[1:]) {
+		os.Exit(ui.RunCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	args := os.Args[1:]
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+			ui.PrintUsage(os.Stdout)
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, "error: unknown flag", args[0])
+		ui.PrintUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// Any other arguments are .http or .rest files to open
+	start := ui.New()
+	if len(args) > 0 {
+		for _, arg := range args {
+			// A word that is neither a request file nor a path is likely a mistyped command
+			if _, err := os.Stat(arg); os.IsNotExist(err) && !ui.IsRequestFile(arg) {
+				fmt.Fprintf(os.Stderr, "error: unknown command %q\n", arg)
+				ui.PrintUsage(os.Stderr)
+				os.Exit(2)
+			}
+		}
+		var err error
+		if start, err = ui.Open(args); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(2)
+		}
+	}
+
+	program := tea.NewProgram(start, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := program.Run(); err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(1)
@@ -0,0 +1,307 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// Auth types, as persisted
+const (
+	authNone   = ""
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authAPIKey = "apikey"
+	authOAuth2 = "oauth2"
+)
+
+// authTypes lists the auth types in the order the Auth tab cycles through them
+var authTypes = []string{authNone, authBasic, authBearer, authAPIKey, authOAuth2}
+
+// Where an API key is sent
+const (
+	apiKeyInHeader = "header"
+	apiKeyInQuery  = "query"
+)
+
+// requestAuth is the authorization applied to a request when it is sent
+type requestAuth struct {
+	Type     string `json:"type,omitempty"`
+	Username string `json:"username,omitempty"` // also used by the OAuth password grant
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Key      string `json:"key,omitempty"` // API key header or query parameter name
+	Value    string `json:"value,omitempty"`
+	In       string `json:"in,omitempty"` // apiKeyInHeader or apiKeyInQuery
+
+	OAuth oauthConfig `json:"oauth,omitzero"`
+}
+
+// authTypeLabel returns the name shown in the Auth tab
+func authTypeLabel(t string) string {
+	switch t {
+	case authBasic:
+		return "Basic"
+	case authBearer:
+		return "Bearer token"
+	case authAPIKey:
+		return "API key"
+	case authOAuth2:
+		return "OAuth 2.0"
+	default:
+		return "None"
+	}
+}
+
+// header returns the header the auth adds, if any
+func (a requestAuth) header() (name, value string, ok bool) {
+	switch a.Type {
+	case authBasic:
+		creds := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		return "Authorization", "Basic " + creds, true
+	case authBearer:
+		return "Authorization", "Bearer " + a.Token, true
+	case authAPIKey:
+		if a.In != apiKeyInQuery && a.Key != "" {
+			return a.Key, a.Value, true
+		}
+	}
+	return "", "", false
+}
+
+// applyAuth returns spec with its auth turned into a header or query
+// parameter. Auth replaces a header of the same name typed by hand.
+// OAuth 2.0 must already be resolved to a token by resolveOAuth.
+func applyAuth(spec requestSpec) requestSpec {
+	a := spec.Auth
+	spec.Auth = requestAuth{}
+
+	if a.Type == authAPIKey && a.In == apiKeyInQuery {
+		if a.Key != "" {
+			spec.URL = appendQueryParam(spec.URL, a.Key, a.Value)
+		}
+		return spec
+	}
+
+	name, value, ok := a.header()
+	if !ok {
+		return spec
+	}
+	spec.Headers = withoutHeader(spec.Headers, name)
+	spec.Headers[name] = value
+	return spec
+}
+
+// withoutHeader returns a copy of headers without the named header, in any case
+func withoutHeader(headers map[string]string, name string) map[string]string {
+	out := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		if !strings.EqualFold(k, name) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// appendQueryParam adds a parameter to a URL, keeping the existing query as is
+func appendQueryParam(u, key, value string) string {
+	base, rawQuery, fragment := splitURL(u)
+	param := encodeQuery([]queryPair{{Key: key, Value: value}})
+	if rawQuery != "" {
+		param = rawQuery + "&" + param
+	}
+	return base + "?" + param + fragment
+}
+
+// authField identifies a row in the Auth tab
+type authField int
+
+const (
+	authFieldType authField = iota
+	authFieldUsername
+	authFieldPassword
+	authFieldToken
+	authFieldKey
+	authFieldValue
+	authFieldIn
+	authFieldGrant
+	authFieldTokenURL
+	authFieldAuthURL
+	authFieldRedirectURL
+	authFieldClientID
+	authFieldClientSecret
+	authFieldScope
+)
+
+// authRows returns the rows the Auth tab shows for the selected type
+func (m model) authRows() []authField {
+	switch m.authType {
+	case authBasic:
+		return []authField{authFieldType, authFieldUsername, authFieldPassword}
+	case authBearer:
+		return []authField{authFieldType, authFieldToken}
+	case authAPIKey:
+		return []authField{authFieldType, authFieldKey, authFieldValue, authFieldIn}
+	case authOAuth2:
+		rows := []authField{authFieldType, authFieldGrant, authFieldTokenURL}
+		if m.oauthGrant == grantAuthorizationCode {
+			rows = append(rows, authFieldAuthURL, authFieldRedirectURL)
+		}
+		rows = append(rows, authFieldClientID, authFieldClientSecret, authFieldScope)
+		if m.oauthGrant == grantPassword {
+			rows = append(rows, authFieldUsername, authFieldPassword)
+		}
+		return rows
+	default:
+		return []authField{authFieldType}
+	}
+}
+
+// selectedAuthField returns the focused row of the Auth tab
+func (m model) selectedAuthField() authField {
+	rows := m.authRows()
+	if m.authIdx >= 0 && m.authIdx < len(rows) {
+		return rows[m.authIdx]
+	}
+	return authFieldType
+}
+
+// authInput returns the text input for a row, or nil for rows that are switches
+func (m *model) authInput(f authField) *textinput.Model {
+	switch f {
+	case authFieldUsername:
+		return &m.authUsername
+	case authFieldPassword:
+		return &m.authPassword
+	case authFieldToken:
+		return &m.authToken
+	case authFieldKey:
+		return &m.authKey
+	case authFieldValue:
+		return &m.authValue
+	case authFieldTokenURL:
+		return &m.oauthTokenURL
+	case authFieldAuthURL:
+		return &m.oauthAuthURL
+	case authFieldRedirectURL:
+		return &m.oauthRedirectURL
+	case authFieldClientID:
+		return &m.oauthClientID
+	case authFieldClientSecret:
+		return &m.oauthClientSecret
+	case authFieldScope:
+		return &m.oauthScope
+	}
+	return nil
+}
+
+// cycleAuthField steps the type, API key location or OAuth grant rows
+func (m *model) cycleAuthField(f authField) {
+	switch f {
+	case authFieldType:
+		for i, t := range authTypes {
+			if t == m.authType {
+				m.authType = authTypes[(i+1)%len(authTypes)]
+				break
+			}
+		}
+		m.authIdx = 0
+	case authFieldIn:
+		if m.authIn == apiKeyInQuery {
+			m.authIn = apiKeyInHeader
+		} else {
+			m.authIn = apiKeyInQuery
+		}
+	case authFieldGrant:
+		for i, g := range oauthGrants {
+			if g == m.oauthGrant {
+				m.oauthGrant = oauthGrants[(i+1)%len(oauthGrants)]
+				return
+			}
+		}
+		m.oauthGrant = oauthGrants[1] // Unset means client credentials
+	}
+}
+
+// currentAuth captures the Auth tab as a requestAuth, keeping only the
+// fields the selected type uses
+func (m model) currentAuth() requestAuth {
+	switch m.authType {
+	case authBasic:
+		return requestAuth{Type: authBasic, Username: m.authUsername.Value(), Password: m.authPassword.Value()}
+	case authBearer:
+		return requestAuth{Type: authBearer, Token: m.authToken.Value()}
+	case authAPIKey:
+		in := m.authIn
+		if in == "" {
+			in = apiKeyInHeader
+		}
+		return requestAuth{Type: authAPIKey, Key: m.authKey.Value(), Value: m.authValue.Value(), In: in}
+	case authOAuth2:
+		grant := m.oauthGrant
+		if grant == "" {
+			grant = grantClientCredentials
+		}
+		a := requestAuth{Type: authOAuth2, OAuth: oauthConfig{
+			Grant:        grant,
+			TokenURL:     m.oauthTokenURL.Value(),
+			ClientID:     m.oauthClientID.Value(),
+			ClientSecret: m.oauthClientSecret.Value(),
+			Scope:        m.oauthScope.Value(),
+		}}
+		switch grant {
+		case grantPassword:
+			a.Username = m.authUsername.Value()
+			a.Password = m.authPassword.Value()
+		case grantAuthorizationCode:
+			a.OAuth.AuthURL = m.oauthAuthURL.Value()
+			a.OAuth.RedirectURL = m.oauthRedirectURL.Value()
+		}
+		return a
+	}
+	return requestAuth{}
+}
+
+// setAuth loads a request's auth into the Auth tab
+func (m *model) setAuth(a requestAuth) {
+	m.authType = a.Type
+	m.authIn = a.In
+	m.authUsername.SetValue(a.Username)
+	m.authPassword.SetValue(a.Password)
+	m.authToken.SetValue(a.Token)
+	m.authKey.SetValue(a.Key)
+	m.authValue.SetValue(a.Value)
+	m.oauthGrant = a.OAuth.Grant
+	m.oauthTokenURL.SetValue(a.OAuth.TokenURL)
+	m.oauthAuthURL.SetValue(a.OAuth.AuthURL)
+	m.oauthRedirectURL.SetValue(a.OAuth.RedirectURL)
+	m.oauthClientID.SetValue(a.OAuth.ClientID)
+	m.oauthClientSecret.SetValue(a.OAuth.ClientSecret)
+	m.oauthScope.SetValue(a.OAuth.Scope)
+	m.authIdx = 0
+}
+
+// forgetOAuthToken drops the cached token for the editor's OAuth settings,
+// so the next send fetches a new one
+func (m *model) forgetOAuthToken() {
+	if m.authType != authOAuth2 {
+		return
+	}
+	spec, _ := resolveRequest(m.currentRequest(), m.requestVars())
+	if err := storeOAuthToken(spec.Auth, oauthToken{}); err != nil {
+		m.err = fmt.Errorf("forget token: %w", err)
+		return
+	}
+	m.status = "Forgot cached OAuth token"
+}
+
+// newAuthInput creates a single-line input for an Auth tab field
+func newAuthInput(placeholder string) textinput.Model {
+	in := textinput.New()
+	in.Placeholder = placeholder
+	in.CharLimit = 4096
+	in.Prompt = ""
+	return in
+}
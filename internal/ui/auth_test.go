@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestApplyAuth tests turning each auth type into a header or query parameter
+func TestApplyAuth(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        requestSpec
+		wantURL     string
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "none",
+			spec:        requestSpec{URL: "https://a.com", Headers: map[string]string{"A": "1"}},
+			wantURL:     "https://a.com",
+			wantHeaders: map[string]string{"A": "1"},
+		},
+		{
+			name:        "basic",
+			spec:        requestSpec{URL: "https://a.com", Auth: requestAuth{Type: authBasic, Username: "alice", Password: "s3cret"}},
+			wantURL:     "https://a.com",
+			wantHeaders: map[string]string{"Authorization": "Basic YWxpY2U6czNjcmV0"},
+		},
+		{
+			name: "bearer replaces a typed header",
+			spec: requestSpec{
+				URL:     "https://a.com",
+				Headers: map[string]string{"authorization": "old", "A": "1"},
+				Auth:    requestAuth{Type: authBearer, Token: "abc"},
+			},
+			wantURL:     "https://a.com",
+			wantHeaders: map[string]string{"Authorization": "Bearer abc", "A": "1"},
+		},
+		{
+			name:        "api key header",
+			spec:        requestSpec{URL: "https://a.com", Auth: requestAuth{Type: authAPIKey, Key: "X-API-Key", Value: "k1", In: apiKeyInHeader}},
+			wantURL:     "https://a.com",
+			wantHeaders: map[string]string{"X-API-Key": "k1"},
+		},
+		{
+			name:    "api key query",
+			spec:    requestSpec{URL: "https://a.com/x?q=a%20b#top", Auth: requestAuth{Type: authAPIKey, Key: "api_key", Value: "k 1", In: apiKeyInQuery}},
+			wantURL: "https://a.com/x?q=a%20b&api_key=k+1#top",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyAuth(tt.spec)
+			if got.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got.URL, tt.wantURL)
+			}
+			if len(got.Headers) != 0 || len(tt.wantHeaders) != 0 {
+				if !reflect.DeepEqual(got.Headers, tt.wantHeaders) {
+					t.Errorf("headers = %v, want %v", got.Headers, tt.wantHeaders)
+				}
+			}
+		})
+	}
+}
+
+// TestAuthSentWithVariables tests that auth values are expanded and sent
+func TestAuthSentWithVariables(t *testing.T) {
+	var gotAuth, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.URL.Query().Get("key")
+	}))
+	defer server.Close()
+
+	vars := map[string]string{"TOKEN": "t0k"}
+	msg := doHTTP(requestSpec{Method: "GET", URL: server.URL, Auth: requestAuth{Type: authBearer, Token: "${TOKEN}"}}, vars)().(httpDoneMsg)
+	if msg.Err != nil {
+		t.Fatalf("request failed: %v", msg.Err)
+	}
+	if gotAuth != "Bearer t0k" {
+		t.Errorf("Authorization = %q, want the expanded token", gotAuth)
+	}
+
+	msg = doHTTP(requestSpec{Method: "GET", URL: server.URL, Auth: requestAuth{Type: authAPIKey, Key: "key", Value: "${TOKEN}", In: apiKeyInQuery}}, vars)().(httpDoneMsg)
+	if msg.Err != nil {
+		t.Fatalf("request failed: %v", msg.Err)
+	}
+	if gotKey != "t0k" {
+		t.Errorf("key query parameter = %q, want the expanded value", gotKey)
+	}
+
+	msg = doHTTP(requestSpec{Method: "GET", URL: server.URL, Auth: requestAuth{Type: authBasic, Username: "${USER}"}}, vars)().(httpDoneMsg)
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "USER") {
+		t.Errorf("undefined auth variables should be reported, got %v", msg.Err)
+	}
+}
+
+// TestAuthTab tests editing auth in the editor and restoring it from history
+func TestAuthTab(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.pane = paneEditor
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(key("u"))
+	if m.activeTab != tabAuth || m.editorPart != edAuth {
+		t.Fatalf("u should open the Auth tab, got tab %v part %v", m.activeTab, m.editorPart)
+	}
+
+	press(key(" ")) // None -> Basic
+	if m.authType != authBasic {
+		t.Fatalf("auth type = %q, want basic", m.authType)
+	}
+	press(key("j"))
+	press(key("i"))
+	press(key("alice"))
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(key("j"))
+	press(key("i"))
+	press(key("${PASS}"))
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := requestAuth{Type: authBasic, Username: "alice", Password: "${PASS}"}
+	if got := m.currentRequest().Auth; got != want {
+		t.Fatalf("auth = %+v, want %+v", got, want)
+	}
+	if view := m.viewAuthTab(); strings.Contains(view, "${PASS}") {
+		t.Error("the password should be masked")
+	}
+
+	m.url.SetValue("https://a.com")
+	m.addToHistoryAndSave(m.currentRequest())
+	m.setAuth(requestAuth{})
+	m.loadItem(historyToItems(m.history)[0])
+	if got := m.currentRequest().Auth; got != want {
+		t.Errorf("auth loaded from history = %+v, want %+v", got, want)
+	}
+
+	// Switching type keeps only the fields that type uses
+	press(key("u"))
+	press(key(" "))
+	if got := m.currentRequest().Auth; got.Type != authBearer || got.Username != "" {
+		t.Errorf("after switching to bearer auth = %+v", got)
+	}
+}
+
+// TestExportBasicAuth tests that snippets use each tool's Basic auth option
+func TestExportBasicAuth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.url.SetValue("https://a.com")
+	m.setHeadersFromMap(map[string]string{"Authorization": "stale"})
+	m.setAuth(requestAuth{Type: authBasic, Username: "${USER}", Password: "p"})
+	spec := m.exportRequest(false)
+
+	for f, want := range map[snippetFormat]string{
+		snippetCurl:   "-u '${USER}:p'",
+		snippetGo:     `req.SetBasicAuth("${USER}", "p")`,
+		snippetPython: `auth=("${USER}", "p")`,
+		snippetFetch:  `"Authorization": "Basic " + btoa("${USER}:p")`,
+		snippetHTTPie: "-a '${USER}:p'",
+	} {
+		got := renderSnippet(f, spec)
+		if !strings.Contains(got, want) || strings.Contains(got, "stale") {
+			t.Errorf("%s snippet should contain %q and not the typed header:\n%s", f, want, got)
+		}
+	}
+
+	parsed, err := parseCurl(curlSnippet(spec))
+	if err != nil || parsed.Auth != spec.Auth {
+		t.Errorf("curl round trip auth = %+v (%v), want %+v", parsed.Auth, err, spec.Auth)
+	}
+}
@@ -0,0 +1,476 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Body types, as persisted. Raw bodies default to JSON, so requests saved
+// before body types existed keep their meaning.
+const (
+	bodyJSON       = ""
+	bodyXML        = "xml"
+	bodyText       = "text"
+	bodyNone       = "none"
+	bodyURLEncoded = "urlencoded"
+	bodyMultipart  = "multipart"
+	bodyBinary     = "binary"
+)
+
+// bodyTypes lists the body types in the order the Body tab cycles through them
+var bodyTypes = []string{bodyNone, bodyJSON, bodyXML, bodyText, bodyURLEncoded, bodyMultipart, bodyBinary}
+
+// bodyTypeLabel returns the name shown in the Body tab
+func bodyTypeLabel(t string) string {
+	switch t {
+	case bodyNone:
+		return "None"
+	case bodyXML:
+		return "Raw (XML)"
+	case bodyText:
+		return "Raw (text)"
+	case bodyURLEncoded:
+		return "Form URL-encoded"
+	case bodyMultipart:
+		return "Multipart form"
+	case bodyBinary:
+		return "Binary file"
+	default:
+		return "Raw (JSON)"
+	}
+}
+
+// isRawBody reports whether the body type is typed into the textarea
+func isRawBody(t string) bool {
+	return t == bodyJSON || t == bodyXML || t == bodyText
+}
+
+// isFormBody reports whether the body type is edited as key/value rows
+func isFormBody(t string) bool {
+	return t == bodyURLEncoded || t == bodyMultipart
+}
+
+// formField is a field of a URL-encoded or multipart body
+type formField struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	File  bool   `json:"file,omitempty"` // multipart only: Value is a file path to upload
+}
+
+// rawContentType returns the Content-Type a raw body is sent with
+func rawContentType(t string) string {
+	switch t {
+	case bodyXML:
+		return "application/xml"
+	case bodyText:
+		return "text/plain"
+	default:
+		return "application/json"
+	}
+}
+
+// hasBody reports whether the request sends a body
+func (spec requestSpec) hasBody() bool {
+	switch {
+	case isRawBody(spec.BodyType):
+		return spec.Body != ""
+	case isFormBody(spec.BodyType):
+		return len(spec.Form) > 0
+	case spec.BodyType == bodyBinary:
+		return spec.BodyFile != ""
+	}
+	return false
+}
+
+// requestBody builds the body of a request and the Content-Type it implies.
+// A multipart Content-Type must be used as is, since it carries the boundary.
+// A binary body is a *fileBody and a multipart body a *multipartStream, both
+// streamed from disk, which the caller must close if the request is never sent.
+func requestBody(spec requestSpec) (io.Reader, string, error) {
+	if !spec.hasBody() {
+		return nil, "", nil
+	}
+	switch spec.BodyType {
+	case bodyURLEncoded:
+		return strings.NewReader(encodeForm(spec.Form)), "application/x-www-form-urlencoded", nil
+	case bodyMultipart:
+		body, contentType := multipartBody(spec.Form, "")
+		return body, contentType, nil
+	case bodyBinary:
+		f, err := openFileBody(spec.BodyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return f, contentTypeByExtension(spec.BodyFile), nil
+	}
+	return strings.NewReader(spec.Body), rawContentType(spec.BodyType), nil
+}
+
+// fileBody streams a file as a request body without reading it into memory
+type fileBody struct {
+	*os.File
+	path string
+	size int64
+}
+
+// openFileBody opens a body file and records its size for Content-Length
+func openFileBody(path string) (*fileBody, error) {
+	f, err := os.Open(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("body file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("body file: %w", err)
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, fmt.Errorf("body file: %s is a directory", path)
+	}
+	return &fileBody{File: f, path: path, size: info.Size()}, nil
+}
+
+// attachFileBody sets the length of a streamed file body and lets redirects
+// that keep the body resend it by reopening the file
+func attachFileBody(req *http.Request, f *fileBody) {
+	req.ContentLength = f.size
+	if f.size == 0 {
+		// A zero length with a body would be sent chunked
+		_ = f.Close()
+		req.Body = http.NoBody
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		if f.size == 0 {
+			return http.NoBody, nil
+		}
+		return openFileBody(f.path)
+	}
+}
+
+// bodyFileInfo describes the file a binary body would send, after expanding
+// variables in its path
+func bodyFileInfo(path string, vars map[string]string) string {
+	if path == "" {
+		return ""
+	}
+	path, missing := expandVars(path, vars)
+	if len(missing) > 0 {
+		return "undefined variable: " + strings.Join(missing, ", ")
+	}
+	info, err := os.Stat(expandHome(path))
+	switch {
+	case err != nil:
+		return "file not found"
+	case info.IsDir():
+		return "is a directory"
+	}
+	return formatBytes(info.Size()) + ", " + contentTypeByExtension(path)
+}
+
+// encodeForm encodes fields as application/x-www-form-urlencoded, in order
+func encodeForm(fields []formField) string {
+	var pairs []queryPair
+	for _, f := range fields {
+		if f.Key != "" {
+			pairs = append(pairs, queryPair{Key: f.Key, Value: f.Value})
+		}
+	}
+	return encodeQuery(pairs)
+}
+
+// multipartStream is a multipart/form-data body written by a goroutine as
+// the request reads it, so file parts are streamed from disk
+type multipartStream struct {
+	*io.PipeReader
+	fields   []formField
+	boundary string
+}
+
+// multipartBody encodes fields as multipart/form-data, reading file fields
+// from disk while the body is sent. A file that can't be read fails the
+// request. An empty boundary picks a random one.
+func multipartBody(fields []formField, boundary string) (*multipartStream, string) {
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	if boundary != "" {
+		_ = w.SetBoundary(boundary) // Only ever one from an earlier writer
+	}
+	contentType := w.FormDataContentType()
+	go func() {
+		pw.CloseWithError(writeMultipart(w, fields))
+	}()
+	return &multipartStream{PipeReader: pr, fields: fields, boundary: w.Boundary()}, contentType
+}
+
+// writeMultipart writes the parts of a multipart body and its end boundary
+func writeMultipart(w *multipart.Writer, fields []formField) error {
+	for _, f := range fields {
+		if f.Key == "" {
+			continue
+		}
+		if !f.File {
+			if err := w.WriteField(f.Key, f.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeFilePart(w, f.Key, f.Value); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// attachMultipartBody lets redirects that keep the body resend it, with the
+// boundary already in the Content-Type
+func attachMultipartBody(req *http.Request, s *multipartStream) {
+	req.GetBody = func() (io.ReadCloser, error) {
+		body, _ := multipartBody(s.fields, s.boundary)
+		return body, nil
+	}
+}
+
+// writeFilePart adds a file to a multipart body, typed by its extension
+func writeFilePart(w *multipart.Writer, name, path string) error {
+	file, err := os.Open(expandHome(path))
+	if err != nil {
+		return fmt.Errorf("form file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     name,
+		"filename": filepath.Base(path),
+	}))
+	h.Set("Content-Type", contentTypeByExtension(path))
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	return err
+}
+
+// contentTypeByExtension guesses a file's media type from its extension
+func contentTypeByExtension(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// formRow is a key/value row of a form body
+type formRow struct {
+	paramRow
+	file bool // multipart value is a file path
+}
+
+// newFormRow creates an empty form row
+func newFormRow() formRow {
+	r := formRow{paramRow: newParamRow()}
+	r.key.Placeholder = "field"
+	return r
+}
+
+// setFormFile marks a row as a file upload or a text value
+func (r *formRow) setFormFile(file bool) {
+	r.file = file
+	if file {
+		r.value.Placeholder = "path/to/file"
+	} else {
+		r.value.Placeholder = "value"
+	}
+}
+
+// cycleBodyType steps the Body tab to the next body type
+func (m *model) cycleBodyType() {
+	for i, t := range bodyTypes {
+		if t == m.bodyType {
+			m.bodyType = bodyTypes[(i+1)%len(bodyTypes)]
+			break
+		}
+	}
+	m.formIdx = 0
+	m.formField = headerKey
+}
+
+// currentBody fills spec with the Body tab, keeping only what the selected
+// type sends
+func (m model) currentBody(spec *requestSpec) {
+	spec.BodyType = m.bodyType
+	switch {
+	case isRawBody(m.bodyType):
+		spec.Body = m.body.Value()
+	case isFormBody(m.bodyType):
+		for _, r := range m.formRows {
+			if r.key.Value() == "" && r.value.Value() == "" {
+				continue
+			}
+			spec.Form = append(spec.Form, formField{
+				Key:   r.key.Value(),
+				Value: r.value.Value(),
+				File:  r.file && m.bodyType == bodyMultipart,
+			})
+		}
+	case m.bodyType == bodyBinary:
+		spec.BodyFile = m.bodyFile.Value()
+	}
+}
+
+// setBody loads a request's body into the Body tab
+func (m *model) setBody(spec requestSpec) {
+	m.bodyType = spec.BodyType
+	m.body.SetValue(spec.Body)
+	m.bodyFile.SetValue(spec.BodyFile)
+	m.formRows = nil
+	for _, f := range spec.Form {
+		r := newFormRow()
+		r.key.SetValue(f.Key)
+		r.value.SetValue(f.Value)
+		r.setFormFile(f.File)
+		m.formRows = append(m.formRows, r)
+	}
+	if len(m.formRows) == 0 {
+		m.formRows = []formRow{newFormRow()}
+	}
+	m.formIdx = 0
+	m.formField = headerKey
+}
+
+// addFormRow adds a form row after the current one
+func (m *model) addFormRow() {
+	idx := m.formIdx + 1
+	m.formRows = append(m.formRows[:idx], append([]formRow{newFormRow()}, m.formRows[idx:]...)...)
+	m.formIdx = idx
+	m.formField = headerKey
+}
+
+// deleteFormRow removes the current form row, clearing it if it is the last
+func (m *model) deleteFormRow() {
+	if len(m.formRows) <= 1 {
+		m.formRows = []formRow{newFormRow()}
+		m.formIdx = 0
+		return
+	}
+	m.formRows = append(m.formRows[:m.formIdx], m.formRows[m.formIdx+1:]...)
+	m.formIdx = min(m.formIdx, len(m.formRows)-1)
+}
+
+// focusBody focuses the Body tab input for the selected type
+func (m *model) focusBody() {
+	switch {
+	case isRawBody(m.bodyType):
+		m.body.Focus()
+	case isFormBody(m.bodyType):
+		if m.formIdx >= 0 && m.formIdx < len(m.formRows) {
+			if m.formField == headerKey {
+				m.formRows[m.formIdx].key.Focus()
+			} else {
+				m.formRows[m.formIdx].value.Focus()
+			}
+		}
+	case m.bodyType == bodyBinary:
+		m.bodyFile.Focus()
+	}
+}
+
+// blurBody removes focus from every Body tab input
+func (m *model) blurBody() {
+	m.body.Blur()
+	m.bodyFile.Blur()
+	for i := range m.formRows {
+		m.formRows[i].key.Blur()
+		m.formRows[i].value.Blur()
+	}
+}
+
+// updateBodyInsert handles keys while editing the Body tab
+func (m *model) updateBodyInsert(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case isRawBody(m.bodyType):
+		// Convert tab key to actual tab character
+		if msg.String() == "tab" {
+			m.body.InsertString("\t")
+			return nil
+		}
+		m.body, cmd = m.body.Update(msg)
+	case m.bodyType == bodyBinary:
+		if msg.String() == "enter" {
+			m.insertMode = false
+			m.applyFocus()
+			return nil
+		}
+		m.bodyFile, cmd = m.bodyFile.Update(msg)
+	case isFormBody(m.bodyType):
+		switch msg.String() {
+		case "enter":
+			m.insertMode = false
+		case "tab":
+			// Move from key to value, or value to next row's key
+			if m.formField == headerKey {
+				m.formField = headerValue
+			} else if m.formIdx < len(m.formRows)-1 {
+				m.formField = headerKey
+				m.formIdx++
+			}
+		case "shift+tab":
+			// Move from value to key, or key to previous row's value
+			if m.formField == headerValue {
+				m.formField = headerKey
+			} else if m.formIdx > 0 {
+				m.formIdx--
+				m.formField = headerValue
+			}
+		case "up":
+			m.formIdx = max(m.formIdx-1, 0)
+		case "down":
+			m.formIdx = min(m.formIdx+1, len(m.formRows)-1)
+		default:
+			row := &m.formRows[m.formIdx]
+			if m.formField == headerKey {
+				row.key, cmd = row.key.Update(msg)
+			} else {
+				row.value, cmd = row.value.Update(msg)
+			}
+			return cmd
+		}
+		m.applyFocus()
+	default:
+		// Nothing to type into
+		m.insertMode = false
+		m.applyFocus()
+	}
+	return cmd
+}
+
+// newBodyFileInput creates the path input of binary bodies
+func newBodyFileInput() textinput.Model {
+	in := textinput.New()
+	in.Placeholder = "path/to/file"
+	in.CharLimit = 4096
+	in.Prompt = ""
+	return in
+}
@@ -0,0 +1,307 @@
+package ui
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// echoBodyServer returns a server that replies with the Content-Type and body it received
+func echoBodyServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Header.Get("Content-Type") + "\n" + string(b)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestRequestBodyTypes tests the body and Content-Type sent for each body type
+func TestRequestBodyTypes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	file := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(file, []byte("\x00\x01raw"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := echoBodyServer(t)
+
+	tests := []struct {
+		name string
+		spec requestSpec
+		want string
+	}{
+		{"json", requestSpec{Body: `{"a":1}`}, "application/json\n{\"a\":1}"},
+		{"xml", requestSpec{BodyType: bodyXML, Body: "<a/>"}, "application/xml\n<a/>"},
+		{"text", requestSpec{BodyType: bodyText, Body: "hi"}, "text/plain\nhi"},
+		{"none keeps the raw body unsent", requestSpec{BodyType: bodyNone, Body: "stale"}, "\n"},
+		{"urlencoded", requestSpec{BodyType: bodyURLEncoded, Form: []formField{{Key: "q", Value: "a b&c"}, {Key: "n", Value: "${N}"}}}, "application/x-www-form-urlencoded\nq=a+b%26c&n=7"},
+		{"binary", requestSpec{BodyType: bodyBinary, BodyFile: "${DIR}/data.bin"}, "application/octet-stream\n\x00\x01raw"},
+		{"typed header wins", requestSpec{BodyType: bodyText, Body: "hi", Headers: map[string]string{"Content-Type": "text/csv"}}, "text/csv\nhi"},
+	}
+	vars := map[string]string{"N": "7", "DIR": dir}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			spec.Method, spec.URL = "POST", server.URL
+			msg := doHTTP(spec, vars)().(httpDoneMsg)
+			if msg.Err != nil {
+				t.Fatalf("request failed: %v", msg.Err)
+			}
+			if msg.Body != tt.want {
+				t.Errorf("server saw %q, want %q", msg.Body, tt.want)
+			}
+		})
+	}
+
+	msg := doHTTP(requestSpec{Method: "POST", URL: server.URL, BodyType: bodyBinary, BodyFile: "missing.bin"}, nil)().(httpDoneMsg)
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "body file") {
+		t.Errorf("missing file error = %v", msg.Err)
+	}
+}
+
+// TestMultipartBody tests text and file parts and the boundary in the Content-Type
+func TestMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	if err := os.WriteFile(filepath.Join(dir, "me.png"), []byte("PNG"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type part struct{ name, filename, contentType, data string }
+	var parts []part
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+			return
+		}
+		mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "multipart/form-data" || params["boundary"] == "" {
+			http.Error(w, "bad Content-Type", http.StatusBadRequest)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			b, _ := io.ReadAll(p)
+			parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(b)})
+		}
+	}))
+	defer server.Close()
+
+	// A typed multipart Content-Type would lack the boundary, so it is replaced
+	spec := requestSpec{
+		Method:   "POST",
+		URL:      server.URL,
+		BodyType: bodyMultipart,
+		Headers:  map[string]string{"Content-Type": "multipart/form-data"},
+		Form: []formField{
+			{Key: "name", Value: "${NAME}"},
+			{Key: "avatar", Value: "~/me.png", File: true},
+		},
+	}
+	msg := doHTTP(spec, map[string]string{"NAME": "Ada"})().(httpDoneMsg)
+	if msg.Err != nil || msg.StatusCode != http.StatusOK {
+		t.Fatalf("request failed: %v %s", msg.Err, msg.Body)
+	}
+	want := []part{{"name", "", "", "Ada"}, {"avatar", "me.png", "image/png", "PNG"}}
+	if len(parts) != len(want) {
+		t.Fatalf("parts = %+v, want %+v", parts, want)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("part %d = %+v, want %+v", i, parts[i], want[i])
+		}
+	}
+
+	// A redirect that keeps the body sends it again with the same boundary
+	parts = nil
+	spec.URL = server.URL + "/moved"
+	spec.Settings.FollowRedirects = boolPtr(true)
+	if msg := doHTTP(spec, map[string]string{"NAME": "Ada"})().(httpDoneMsg); msg.Err != nil || msg.StatusCode != http.StatusOK || len(parts) != len(want) {
+		t.Errorf("redirected request = %v %d with parts %+v", msg.Err, msg.StatusCode, parts)
+	}
+
+	spec.Form[1].Value = "~/missing.png"
+	if msg := doHTTP(spec, map[string]string{"NAME": "Ada"})().(httpDoneMsg); msg.Err == nil || !strings.Contains(msg.Err.Error(), "form file") {
+		t.Errorf("missing file error = %v", msg.Err)
+	}
+}
+
+// TestBodyTab tests switching body types and editing form rows
+func TestBodyTab(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneEditor
+	m.activeTab = tabBody
+	m.resetEditorPartForTab()
+	m.applyFocus()
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	typeText := func(s string) {
+		for _, r := range s {
+			press(key(string(r)))
+		}
+	}
+
+	m.body.SetValue(`{"a":1}`)
+	for m.bodyType != bodyMultipart {
+		press(key(" "))
+	}
+	if !strings.Contains(m.View(), "Multipart form") {
+		t.Errorf("Body tab should show the body type:\n%s", m.View())
+	}
+
+	press(key("i"))
+	typeText("name")
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("Ada")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(key("a"))
+	press(key("f"))
+	press(key("i"))
+	typeText("file")
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("a.txt")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	spec := m.currentRequest()
+	want := []formField{{Key: "name", Value: "Ada"}, {Key: "file", Value: "a.txt", File: true}}
+	if spec.BodyType != bodyMultipart || spec.Body != "" || len(spec.Form) != 2 || spec.Form[0] != want[0] || spec.Form[1] != want[1] {
+		t.Fatalf("request body = %q %q %+v, want the multipart form only", spec.BodyType, spec.Body, spec.Form)
+	}
+
+	// The form survives history and loads back into the tab
+	m.addToHistoryAndSave(spec)
+	entries, err := loadHistory()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("history = %+v (%v)", entries, err)
+	}
+	m2 := New().(model)
+	m2.setRequest(entries[0].spec())
+	if got := m2.currentRequest(); len(got.Form) != 2 || got.Form[1] != want[1] {
+		t.Errorf("reloaded form = %+v", got.Form)
+	}
+
+	press(key(" "))
+	if m.bodyType != bodyBinary || m.currentRequest().Form != nil {
+		t.Errorf("binary body = %q with form %+v", m.bodyType, m.currentRequest().Form)
+	}
+}
+
+// TestExportBodyTypes tests that snippets send forms and files the way each tool expects
+func TestExportBodyTypes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.setRequest(requestSpec{Method: "POST", URL: "https://a.com/upload"})
+	m.setBody(requestSpec{BodyType: bodyMultipart, Form: []formField{{Key: "name", Value: "Ada"}, {Key: "doc", Value: "a.pdf", File: true}}})
+	multi := m.exportRequest(false)
+
+	for f, want := range map[snippetFormat][]string{
+		snippetCurl:   {"--form-string name=Ada", "-F doc=@a.pdf"},
+		snippetGo:     {`form.WriteField("name", "Ada")`, `os.Open("a.pdf")`, "form.FormDataContentType()"},
+		snippetPython: {`("name", (None, "Ada"))`, `("doc", open("a.pdf", "rb"))`},
+		snippetFetch:  {`form.append("name", "Ada")`, `fs.openAsBlob("a.pdf")`},
+		snippetHTTPie: {"--multipart", "name=Ada", "doc@a.pdf"},
+	} {
+		got := renderSnippet(f, multi)
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s snippet should contain %q:\n%s", f, w, got)
+			}
+		}
+		if strings.Contains(got, "multipart/form-data") {
+			t.Errorf("%s snippet should leave the multipart Content-Type to the tool:\n%s", f, got)
+		}
+	}
+
+	m.setBody(requestSpec{BodyType: bodyBinary, BodyFile: "dump.bin"})
+	binary := m.exportRequest(false)
+	for _, spec := range []requestSpec{multi, binary} {
+		src := goSnippet(spec)
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+			t.Errorf("Go snippet does not parse: %v\n%s", err, src)
+		}
+		parsed, err := parseCurl(curlSnippet(spec))
+		if err != nil || parsed.BodyType != spec.BodyType || len(parsed.Form) != len(spec.Form) || parsed.BodyFile != spec.BodyFile {
+			t.Errorf("curl round trip = %+v (%v), want %+v", parsed, err, spec)
+		}
+	}
+	if got := renderSnippet(snippetHTTPie, binary); !strings.Contains(got, "< dump.bin") {
+		t.Errorf("HTTPie snippet should redirect the file:\n%s", got)
+	}
+}
+
+// TestFileBodyStreams tests that a file body is sent with its length and
+// type and resent across a redirect
+func TestFileBodyStreams(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	data := strings.Repeat("fixture", 1000)
+	if err := os.WriteFile(filepath.Join(dir, "dump.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty.png"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		if r.ContentLength != int64(len(b)) || len(r.TransferEncoding) > 0 {
+			http.Error(w, "not sent with Content-Length", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Content-Type") + " " + strconv.Itoa(len(b))))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path, file, want string
+	}{
+		{"/new", "${DIR}/dump.json", "application/json 7000"},
+		{"/old", "~/dump.json", "application/json 7000"},
+		{"/new", "~/empty.png", "image/png 0"},
+	}
+	for _, tt := range tests {
+		spec := requestSpec{Method: "PUT", URL: server.URL + tt.path, BodyType: bodyBinary, BodyFile: tt.file}
+		msg := doHTTP(spec, map[string]string{"DIR": dir})().(httpDoneMsg)
+		if msg.Err != nil {
+			t.Fatalf("%s: request failed: %v", tt.file, msg.Err)
+		}
+		if msg.Body != tt.want {
+			t.Errorf("%s %s: server saw %q, want %q", tt.path, tt.file, msg.Body, tt.want)
+		}
+	}
+
+	if got := bodyFileInfo("${DIR}/dump.json", map[string]string{"DIR": dir}); got != "6.8 KB, application/json" {
+		t.Errorf("file info = %q", got)
+	}
+	if got := bodyFileInfo("${NOPE}/x", nil); !strings.Contains(got, "NOPE") {
+		t.Errorf("file info with an undefined variable = %q", got)
+	}
+}
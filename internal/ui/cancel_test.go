@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestDoHTTPContextCancel tests that cancelling the context aborts the request
+func TestDoHTTPContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := doHTTPContext(ctx, 7, requestSpec{Method: "GET", URL: server.URL}, nil, nil)
+
+	done := make(chan httpDoneMsg)
+	go func() { done <- cmd().(httpDoneMsg) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case msg := <-done:
+		if !errors.Is(msg.Err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", msg.Err)
+		}
+		if msg.ID != 7 {
+			t.Errorf("ID = %d, want 7", msg.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request did not stop after cancel")
+	}
+}
+
+// TestCancelKeys tests that ctrl+x and esc cancel a loading request
+func TestCancelKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  tea.KeyMsg
+	}{
+		{"ctrl+x", tea.KeyMsg{Type: tea.KeyCtrlX}},
+		{"esc", tea.KeyMsg{Type: tea.KeyEsc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("HOME", tmpDir)
+
+			m := New().(model)
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			m = updated.(model)
+			m.pane = paneEditor
+			m.url.SetValue("https://example.com")
+
+			updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = updated.(model)
+			if !m.loading {
+				t.Fatal("loading should be true after sending")
+			}
+			sentID := m.reqID
+
+			updated, _ = m.Update(tt.key)
+			m = updated.(model)
+			if m.loading {
+				t.Error("loading should be false after cancel")
+			}
+			if m.status != "Request cancelled" {
+				t.Errorf("status = %q, want %q", m.status, "Request cancelled")
+			}
+			if !strings.Contains(m.viewResponse(), "Request cancelled") {
+				t.Error("response pane should say the request was cancelled")
+			}
+
+			// The cancelled request's response arrives late and is ignored
+			updated, _ = m.Update(httpDoneMsg{ID: sentID, Err: context.Canceled})
+			m = updated.(model)
+			if m.err != nil {
+				t.Errorf("stale response should be ignored, got err %v", m.err)
+			}
+			if !strings.Contains(m.viewResponse(), "Request cancelled") {
+				t.Error("stale response should not overwrite the response pane")
+			}
+		})
+	}
+}
+
+// TestSupersededResponseIgnored tests that an older response can't overwrite a newer one
+func TestSupersededResponseIgnored(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.url.SetValue("https://example.com/first")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	firstID := m.reqID
+
+	m.url.SetValue("https://example.com/second")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	secondID := m.reqID
+
+	updated, _ = m.Update(httpDoneMsg{ID: secondID, Status: "200 OK", Body: "second"})
+	m = updated.(model)
+	updated, _ = m.Update(httpDoneMsg{ID: firstID, Status: "500 Internal Server Error", Body: "first"})
+	m = updated.(model)
+
+	if m.status != "200 OK" {
+		t.Errorf("status = %q, want the newer response's %q", m.status, "200 OK")
+	}
+	if m.resp == nil || m.resp.Body != "second" {
+		t.Error("response pane should keep the newer response")
+	}
+}
+
+// TestEscWithoutLoadingDoesNothing tests that esc only cancels while loading
+func TestEscWithoutLoadingDoesNothing(t *testing.T) {
+	m := New().(model)
+	m.pane = paneResponse
+	m.view.SetContent("previous response")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.status == "Request cancelled" {
+		t.Error("esc should not cancel when nothing is loading")
+	}
+}
@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Exit codes for the non-interactive commands
+const (
+	exitOK     = 0
+	exitFailed = 1 // request failed or the response was not 2xx
+	exitUsage  = 2
+)
+
+const (
+	usageOpen = "usage: getboy [file.http ...]"
+	usageRun  = "usage: getboy run [--json] [-e env] <collection/folder/name>"
+	usageSend = "usage: getboy send [-X method] [-H 'Key: Value']... [-d body] [--json] [-e env] <url>"
+)
+
+// PrintUsage writes how to start the TUI and run the non-interactive commands
+func PrintUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, usageOpen)
+	_, _ = fmt.Fprintln(w, usageRun)
+	_, _ = fmt.Fprintln(w, usageSend)
+}
+
+// IsCLICommand reports whether args name a non-interactive subcommand
+func IsCLICommand(args []string) bool {
+	return len(args) > 0 && (args[0] == "run" || args[0] == "send")
+}
+
+// RunCLI executes a non-interactive subcommand and returns the exit code.
+// args starts with the subcommand name.
+func RunCLI(args []string, stdout, stderr io.Writer) int {
+	if !IsCLICommand(args) {
+		PrintUsage(stderr)
+		return exitUsage
+	}
+
+	opts := cliOptions{}
+	fs := flag.NewFlagSet("getboy "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&opts.json, "json", false, "print the response as JSON")
+	fs.StringVar(&opts.env, "e", "", "environment to use instead of the active one")
+	usage := usageRun
+	if args[0] == "send" {
+		usage = usageSend
+		fs.StringVar(&opts.method, "X", "", "HTTP method (default GET, or POST with -d)")
+		fs.Var(&opts.headers, "H", "request header, repeatable")
+		fs.StringVar(&opts.body, "d", "", "request body")
+	}
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var spec requestSpec
+	var colVars map[string]string
+	switch args[0] {
+	case "run":
+		spec, colVars, err = savedRequestSpec(positional[0])
+	case "send":
+		spec = opts.sendSpec(positional[0])
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	env, vars, err := cliEnvironment(opts.env)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+	vars = mergeVars(colVars, vars)
+	settings, _ := loadSettings() // Ignore error, use the built-in defaults
+	spec.URL = ensureScheme(spec.URL)
+	spec.Settings = spec.Settings.over(settings)
+
+	msg := executeRequest(context.Background(), spec, vars, newCookieJar(env))
+	if msg.Err != nil {
+		_, _ = fmt.Fprintln(stderr, "error:", msg.Err)
+		return exitFailed
+	}
+
+	if opts.json {
+		err = writeResponseJSON(stdout, msg)
+	} else {
+		err = writeResponseText(stdout, msg)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitFailed
+	}
+	if msg.Truncated {
+		_, _ = fmt.Fprintf(stderr, "error: only the first %s of the body were printed; set a download path on the request to save all of it\n", formatBytes(maxBufferedBody))
+		return exitFailed
+	}
+
+	if msg.StatusCode < 200 || msg.StatusCode > 299 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// cliOptions holds the flags of the non-interactive commands
+type cliOptions struct {
+	json    bool
+	env     string
+	method  string
+	headers headerFlags
+	body    string
+}
+
+// headerFlags collects repeated -H flags
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(v string) error {
+	if !strings.Contains(v, ":") {
+		return fmt.Errorf("header %q must be in 'Key: Value' form", v)
+	}
+	*h = append(*h, v)
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// sendSpec builds the request described by the send flags
+func (o cliOptions) sendSpec(url string) requestSpec {
+	spec := requestSpec{Method: strings.ToUpper(o.method), URL: url, Body: o.body}
+	if spec.Method == "" {
+		spec.Method = "GET"
+		if o.body != "" {
+			spec.Method = "POST"
+		}
+	}
+	if len(o.headers) > 0 {
+		spec.Headers = map[string]string{}
+		for _, h := range o.headers {
+			k, v, _ := strings.Cut(h, ":")
+			spec.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return spec
+}
+
+// savedRequestSpec looks up a saved request by path, or by name if it is
+// unique, and returns it with its collection's variables
+func savedRequestSpec(name string) (requestSpec, map[string]string, error) {
+	cols, err := loadCollections()
+	if err != nil {
+		return requestSpec{}, nil, err
+	}
+
+	path := splitPath(name)
+	if len(path) == 0 {
+		return requestSpec{}, nil, fmt.Errorf("request name cannot be empty")
+	}
+	if req, ok := lookupRequest(cols, path); ok {
+		return req.requestSpec, collectionVars(cols, path), nil
+	}
+	if len(path) == 1 {
+		path := []string{defaultCollectionName, path[0]}
+		if req, ok := lookupRequest(cols, path); ok {
+			return req.requestSpec, collectionVars(cols, path), nil
+		}
+	}
+
+	// Fall back to a request anywhere whose path ends with the given one
+	var matches []string
+	for _, n := range flattenTree(cols, allExpanded(cols)) {
+		if n.kind == nodeRequest && hasPathSuffix(n.path, path) {
+			matches = append(matches, n.key())
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return requestSpec{}, nil, fmt.Errorf("no saved request named %q", name)
+	case 1:
+		path := splitPath(matches[0])
+		req, _ := lookupRequest(cols, path)
+		return req.requestSpec, collectionVars(cols, path), nil
+	default:
+		return requestSpec{}, nil, fmt.Errorf("%q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// allExpanded marks every collection and folder as expanded
+func allExpanded(cols []*collection) map[string]bool {
+	expanded := map[string]bool{}
+	var walk func(f *folder, path []string)
+	walk = func(f *folder, path []string) {
+		expanded[joinPath(path...)] = true
+		for _, sub := range f.Folders {
+			walk(sub, append(slices.Clone(path), sub.Name))
+		}
+	}
+	for _, c := range cols {
+		walk(&c.folder, []string{c.Name})
+	}
+	return expanded
+}
+
+// hasPathSuffix reports whether path ends with suffix
+func hasPathSuffix(path, suffix []string) bool {
+	if len(suffix) > len(path) {
+		return false
+	}
+	offset := len(path) - len(suffix)
+	for i, s := range suffix {
+		if path[offset+i] != s {
+			return false
+		}
+	}
+	return true
+}
+
+// cliEnvironment returns the name and variables of the named environment,
+// or the active one
+func cliEnvironment(name string) (string, map[string]string, error) {
+	envs, err := loadEnvironments()
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		return envs.Active, envs.activeVars(), nil
+	}
+	for _, e := range envs.Environments {
+		if e.Name == name {
+			return e.Name, e.Variables, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no environment named %q", name)
+}
+
+// writeResponseText prints the status line, headers and body
+func writeResponseText(w io.Writer, msg httpDoneMsg) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", msg.Proto, msg.Status)
+	for _, k := range slices.Sorted(maps.Keys(msg.Headers)) {
+		for _, v := range msg.Headers[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	b.WriteString("\n")
+	if msg.SavedTo != "" {
+		fmt.Fprintf(&b, "Saved %s to %s\n", formatBytes(int64(msg.Size)), msg.SavedTo)
+	}
+	b.WriteString(msg.Body)
+	if msg.Body != "" && !strings.HasSuffix(msg.Body, "\n") {
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cliResponse is the --json output of the non-interactive commands
+type cliResponse struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"status_code"`
+	Proto      string      `json:"proto"`
+	URL        string      `json:"url"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	SavedTo    string      `json:"saved_to,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty"` // Body holds only the first maxBufferedBody bytes
+	DurationMs int64       `json:"duration_ms"`
+}
+
+// writeResponseJSON prints the response as a JSON object
+func writeResponseJSON(w io.Writer, msg httpDoneMsg) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cliResponse{
+		Status:     msg.Status,
+		StatusCode: msg.StatusCode,
+		Proto:      msg.Proto,
+		URL:        msg.FinalURL,
+		Headers:    msg.Headers,
+		Body:       msg.Body,
+		SavedTo:    msg.SavedTo,
+		Truncated:  msg.Truncated,
+		DurationMs: msg.Timing.Total.Milliseconds(),
+	})
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEchoServer returns a server that echoes the method, a header and the body,
+// answering 404 for /missing
+func newEchoServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		_, _ = w.Write([]byte(r.Header.Get("X-Token") + "|" + string(body)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCLI runs a subcommand and returns its exit code and output
+func runCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := RunCLI(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestCLISend tests sending an ad-hoc request from flags
+func TestCLISend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newEchoServer(t)
+
+	// Flags may follow the URL
+	code, out, errOut := runCLI("send", "-X", "put", server.URL, "-H", "X-Token: abc", "-d", `{"a":1}`)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want 0 (stderr: %s)", code, errOut)
+	}
+	if !strings.HasPrefix(out, "HTTP/1.1 200 OK\n") {
+		t.Errorf("output should start with the status line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "X-Method: PUT\n") {
+		t.Errorf("output should list response headers, got:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "\n\nabc|{\"a\":1}\n") {
+		t.Errorf("output should end with the body, got:\n%s", out)
+	}
+
+	// A body without -X defaults to POST
+	_, out, _ = runCLI("send", "-d", "x", server.URL)
+	if !strings.Contains(out, "X-Method: POST") {
+		t.Errorf("-d without -X should POST, got:\n%s", out)
+	}
+}
+
+// TestCLIExitCodes tests the exit code for non-2xx responses and bad usage
+func TestCLIExitCodes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newEchoServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"non-2xx", []string{"send", server.URL + "/missing"}, exitFailed},
+		{"connection error", []string{"send", "http://127.0.0.1:1"}, exitFailed},
+		{"missing url", []string{"send"}, exitUsage},
+		{"bad header", []string{"send", "-H", "nocolon", server.URL}, exitUsage},
+		{"unknown saved request", []string{"run", "nope"}, exitUsage},
+		{"unknown environment", []string{"send", "-e", "prod", server.URL}, exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, _ := runCLI(tt.args...); code != tt.want {
+				t.Errorf("exit code = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+// TestCLIJSON tests the --json output
+func TestCLIJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newEchoServer(t)
+
+	code, out, _ := runCLI("send", "--json", "-H", "X-Token: t", server.URL)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	var resp cliResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if resp.StatusCode != 200 || resp.Body != "t|" || resp.Headers.Get("X-Method") != "GET" {
+		t.Errorf("unexpected JSON response: %+v", resp)
+	}
+}
+
+// TestCLITruncatedBody tests that a body too large to buffer fails the
+// command instead of being printed as if it were whole
+func TestCLITruncatedBody(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.CopyN(w, zeroReader{}, maxBufferedBody+1)
+	}))
+	defer server.Close()
+
+	for _, args := range [][]string{{"send", server.URL}, {"send", "--json", server.URL}} {
+		code, _, errOut := runCLI(args...)
+		if code != exitFailed || !strings.Contains(errOut, "only the first 64.0 MB") {
+			t.Errorf("%v: exit code %d, stderr %q", args, code, errOut)
+		}
+	}
+}
+
+// zeroReader reads endless zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// TestCLIRun tests running a saved request with environment variables
+func TestCLIRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newEchoServer(t)
+
+	spec := requestSpec{Method: "POST", URL: "${BASE}/echo", Body: "hi", Headers: map[string]string{"X-Token": "${TOKEN}"}}
+	cols, c, _ := saveRequestAt(nil, []string{"API", "users", "create"}, spec)
+	if err := writeCollection(c); err != nil {
+		t.Fatalf("writeCollection failed: %v", err)
+	}
+	cols, c, _ = saveRequestAt(cols, []string{"Other", "create"}, spec)
+	c.Variables = map[string]string{"TOKEN": "other-token"}
+	if err := writeCollection(c); err != nil {
+		t.Fatalf("writeCollection failed: %v", err)
+	}
+	envs := environmentStore{
+		Active: "local",
+		Environments: []environment{
+			{Name: "local", Variables: map[string]string{"BASE": server.URL, "TOKEN": "local-token"}},
+			{Name: "ci", Variables: map[string]string{"BASE": server.URL, "TOKEN": "ci-token"}},
+			{Name: "bare", Variables: map[string]string{"BASE": server.URL}},
+		},
+	}
+	if err := saveEnvironments(envs); err != nil {
+		t.Fatalf("saveEnvironments failed: %v", err)
+	}
+
+	code, out, errOut := runCLI("run", "API/users/create")
+	if code != exitOK || !strings.Contains(out, "local-token|hi") {
+		t.Errorf("run by path: exit %d, output:\n%s%s", code, out, errOut)
+	}
+
+	// A unique suffix is enough, and -e picks another environment
+	code, out, _ = runCLI("run", "-e", "ci", "users/create")
+	if code != exitOK || !strings.Contains(out, "ci-token|hi") {
+		t.Errorf("run by suffix: exit %d, output:\n%s", code, out)
+	}
+
+	// Collection variables fill in what the environment doesn't define
+	code, out, _ = runCLI("run", "-e", "bare", "Other/create")
+	if code != exitOK || !strings.Contains(out, "other-token|hi") {
+		t.Errorf("run with collection variables: exit %d, output:\n%s", code, out)
+	}
+
+	code, _, errOut = runCLI("run", "create")
+	if code != exitUsage || !strings.Contains(errOut, "ambiguous") {
+		t.Errorf("ambiguous name: exit %d, stderr: %s", code, errOut)
+	}
+}
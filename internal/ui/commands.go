@@ -1,53 +1,142 @@
 package ui
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"io"
 	"net/http"
-	"time"
+	"net/http/httptrace"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func doHTTP(method, url, body string, headers map[string]string) tea.Cmd {
+func doHTTP(spec requestSpec, vars map[string]string) tea.Cmd {
+	return doHTTPContext(context.Background(), 0, spec, vars, nil)
+}
+
+// doHTTPContext sends a request that is abandoned when ctx is cancelled.
+// The response is tagged with id so superseded responses can be discarded.
+func doHTTPContext(ctx context.Context, id int, spec requestSpec, vars map[string]string, jar http.CookieJar) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
-		defer cancel()
+		msg := executeRequest(ctx, spec, vars, jar)
+		msg.ID = id
+		return msg
+	}
+}
 
-		// Expand env vars in body
-		expandedBody := expandEnvVars(body)
+// executeRequest resolves variables, sends the request and reads the response.
+// Cookies are sent from and stored in jar unless the settings turn it off.
+func executeRequest(ctx context.Context, spec requestSpec, vars map[string]string, jar http.CookieJar) httpDoneMsg {
+	// Substitute variables in every part of the request before sending
+	spec, err := resolveRequest(spec, vars)
+	if err != nil {
+		return httpDoneMsg{Err: err}
+	}
+	client := newHTTPClient(spec.Settings)
+	if jar != nil && spec.Settings.cookies() {
+		client.Jar = jar
+	}
+	if spec.Auth, err = resolveOAuth(ctx, spec.Auth, client); err != nil {
+		return httpDoneMsg{Err: err}
+	}
+	spec = applyAuth(spec)
 
-		var reader io.Reader
-		if expandedBody != "" {
-			reader = bytes.NewBufferString(expandedBody)
-		}
-		req, err := http.NewRequestWithContext(ctx, method, url, reader)
-		if err != nil {
-			return httpDoneMsg{Err: err}
+	body, contentType, err := requestBody(spec)
+	if err != nil {
+		return httpDoneMsg{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, spec.Method, ensureScheme(spec.URL), body)
+	if err != nil {
+		if c, ok := body.(io.Closer); ok {
+			_ = c.Close()
 		}
+		return httpDoneMsg{Err: err}
+	}
+	switch body := body.(type) {
+	case *fileBody:
+		attachFileBody(req, body)
+	case *multipartStream:
+		attachMultipartBody(req, body)
+	}
 
-		// Set headers with env var expansion
-		for k, v := range headers {
-			req.Header.Set(k, expandEnvVars(v))
-		}
+	for k, v := range spec.Headers {
+		req.Header.Set(k, v)
+	}
 
-		// Default Content-Type for body if not already set
-		if expandedBody != "" && req.Header.Get("Content-Type") == "" {
-			req.Header.Set("Content-Type", "application/json")
+	// Downloads time out when data stops arriving, not after a fixed time
+	var idle *idleTimeout
+	if spec.Settings.DownloadTo != "" {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		idle = newIdleTimeout(spec.Settings.timeout(), cancel)
+		defer idle.stop()
+	}
+	stalled := func(err error) error {
+		if cause := context.Cause(ctx); errors.Is(cause, errDownloadStalled) {
+			return cause
 		}
+		return err
+	}
+
+	// Time each phase and record which address the connection went to
+	tracer := newTimingTracer()
+	req = req.WithContext(httptrace.WithClientTrace(ctx, tracer.clientTrace()))
 
-		client := &http.Client{Timeout: 12 * time.Second}
-		resp, err := client.Do(req)
+	// Default Content-Type for the body type if not already set. Multipart
+	// bodies always use their own, which carries the boundary.
+	if contentType != "" && (req.Header.Get("Content-Type") == "" || spec.BodyType == bodyMultipart) {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpDoneMsg{Err: stalled(err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	resp.Body = idle.body(resp.Body)
+
+	var b []byte
+	var savedTo string
+	var truncated bool
+	size := 0
+	if spec.Settings.DownloadTo != "" {
+		// Large responses go straight to disk instead of into memory
+		n, path, err := downloadResponse(resp, spec.Settings.DownloadTo)
 		if err != nil {
-			return httpDoneMsg{Err: err}
+			return httpDoneMsg{Err: stalled(err)}
 		}
-		defer func() { _ = resp.Body.Close() }()
-
-		b, err := io.ReadAll(resp.Body)
+		size, savedTo = int(n), path
+	} else {
+		// Keep at most maxBufferedBody bytes, reading one more to detect overflow
+		b, err = io.ReadAll(io.LimitReader(resp.Body, maxBufferedBody+1))
 		if err != nil {
 			return httpDoneMsg{Err: err}
 		}
-		return httpDoneMsg{Status: resp.Status, Body: string(b)}
+		if len(b) > maxBufferedBody {
+			b, truncated = b[:maxBufferedBody], true
+		}
+		size = len(b)
+	}
+	timing := tracer.finish()
+	msg := httpDoneMsg{
+		Status:        resp.Status,
+		Body:          string(b),
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		Headers:       resp.Header,
+		ContentLength: resp.ContentLength,
+		Size:          size,
+		SavedTo:       savedTo,
+		Truncated:     truncated,
+		FinalURL:      resp.Request.URL.String(),
+		RemoteAddr:    tracer.connAddr(),
+		SentAt:        tracer.start,
+		Timing:        timing,
+	}
+	if resp.TLS != nil {
+		msg.TLSVersion = tls.VersionName(resp.TLS.Version)
 	}
+	return msg
 }
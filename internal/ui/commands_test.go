@@ -22,7 +22,7 @@ func TestDoHTTP(t *testing.T) {
 		defer server.Close()
 
 		// Execute the command
-		cmd := doHTTP("GET", server.URL, "", nil)
+		cmd := doHTTP(requestSpec{Method: "GET", URL: server.URL}, nil)
 		msg := cmd()
 
 		// Check the result
@@ -69,7 +69,7 @@ func TestDoHTTP(t *testing.T) {
 
 		// Execute the command
 		requestBody := `{"test":"data"}`
-		cmd := doHTTP("POST", server.URL, requestBody, nil)
+		cmd := doHTTP(requestSpec{Method: "POST", URL: server.URL, Body: requestBody}, nil)
 		msg := cmd()
 
 		// Check the result
@@ -88,7 +88,7 @@ func TestDoHTTP(t *testing.T) {
 	})
 
 	t.Run("handles invalid URL", func(t *testing.T) {
-		cmd := doHTTP("GET", "://invalid-url", "", nil)
+		cmd := doHTTP(requestSpec{Method: "GET", URL: "://invalid-url"}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -108,7 +108,7 @@ func TestDoHTTP(t *testing.T) {
 		}))
 		defer server.Close()
 
-		cmd := doHTTP("GET", server.URL, "", nil)
+		cmd := doHTTP(requestSpec{Method: "GET", URL: server.URL}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -147,7 +147,7 @@ func TestDoHTTP(t *testing.T) {
 			"Authorization":   "Bearer mytoken",
 			"X-Custom-Header": "custom-value",
 		}
-		cmd := doHTTP("GET", server.URL, "", headers)
+		cmd := doHTTP(requestSpec{Method: "GET", URL: server.URL, Headers: headers}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -177,7 +177,7 @@ func TestDoHTTP(t *testing.T) {
 		headers := map[string]string{
 			"Authorization": "Bearer ${TEST_TOKEN}",
 		}
-		cmd := doHTTP("GET", server.URL, "", headers)
+		cmd := doHTTP(requestSpec{Method: "GET", URL: server.URL, Headers: headers}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -208,7 +208,7 @@ func TestDoHTTP(t *testing.T) {
 		defer server.Close()
 
 		body := `{"key":"${TEST_VALUE}"}`
-		cmd := doHTTP("POST", server.URL, body, nil)
+		cmd := doHTTP(requestSpec{Method: "POST", URL: server.URL, Body: body}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -233,7 +233,7 @@ func TestDoHTTP(t *testing.T) {
 		headers := map[string]string{
 			"Content-Type": "text/plain",
 		}
-		cmd := doHTTP("POST", server.URL, "plain text body", headers)
+		cmd := doHTTP(requestSpec{Method: "POST", URL: server.URL, Body: "plain text body", Headers: headers}, nil)
 		msg := cmd()
 
 		result, ok := msg.(httpDoneMsg)
@@ -244,4 +244,138 @@ func TestDoHTTP(t *testing.T) {
 			t.Errorf("unexpected error: %v", result.Err)
 		}
 	})
+
+	t.Run("environment variables take precedence over OS", func(t *testing.T) {
+		t.Setenv("TEST_TOKEN", "os_token")
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			if auth != "Bearer env_token" {
+				t.Errorf("Authorization = %q, want %q", auth, "Bearer env_token")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		headers := map[string]string{
+			"Authorization": "Bearer ${TEST_TOKEN}",
+		}
+		vars := map[string]string{"TEST_TOKEN": "env_token"}
+		cmd := doHTTP(requestSpec{Method: "GET", URL: server.URL, Headers: headers}, vars)
+		msg := cmd()
+
+		result, ok := msg.(httpDoneMsg)
+		if !ok {
+			t.Fatalf("expected httpDoneMsg, got %T", msg)
+		}
+		if result.Err != nil {
+			t.Errorf("unexpected error: %v", result.Err)
+		}
+	})
+}
+
+// TestDoHTTPVariableExpansion tests that variables are expanded in every part of the request
+func TestDoHTTPVariableExpansion(t *testing.T) {
+	t.Run("expands URL, query and header names", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/users/7" {
+				t.Errorf("path = %q, want %q", r.URL.Path, "/users/7")
+			}
+			if r.URL.Query().Get("fields") != "id,name" {
+				t.Errorf("fields = %q, want %q", r.URL.Query().Get("fields"), "id,name")
+			}
+			if r.Header.Get("X-Tenant") != "acme" {
+				t.Errorf("X-Tenant = %q, want %q", r.Header.Get("X-Tenant"), "acme")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		vars := map[string]string{
+			"BASE_URL": server.URL,
+			"ID":       "7",
+			"FIELDS":   "id,name",
+			"TENANT_H": "X-Tenant",
+		}
+		spec := requestSpec{
+			Method:  "GET",
+			URL:     "${BASE_URL}/users/${ID}?fields=${FIELDS}",
+			Headers: map[string]string{"${TENANT_H}": "acme"},
+		}
+		result, ok := doHTTP(spec, vars)().(httpDoneMsg)
+		if !ok {
+			t.Fatal("expected httpDoneMsg")
+		}
+		if result.Err != nil {
+			t.Errorf("unexpected error: %v", result.Err)
+		}
+	})
+
+	t.Run("undefined variables fail before sending", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		spec := requestSpec{Method: "GET", URL: server.URL + "/${UNDEFINED_TEST_VAR}"}
+		result, ok := doHTTP(spec, nil)().(httpDoneMsg)
+		if !ok {
+			t.Fatal("expected httpDoneMsg")
+		}
+		if result.Err == nil || result.Err.Error() != "undefined variables: UNDEFINED_TEST_VAR" {
+			t.Errorf("err = %v, want undefined variables error", result.Err)
+		}
+		if called {
+			t.Error("request should not be sent with undefined variables")
+		}
+	})
+}
+
+// TestDoHTTPResponseMetadata tests that response headers and metadata are captured
+func TestDoHTTPResponseMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	result, ok := doHTTP(requestSpec{Method: "GET", URL: server.URL + "/old"}, nil)().(httpDoneMsg)
+	if !ok {
+		t.Fatal("expected httpDoneMsg")
+	}
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, want %d", result.StatusCode, http.StatusCreated)
+	}
+	if result.Proto != "HTTP/1.1" {
+		t.Errorf("Proto = %q, want %q", result.Proto, "HTTP/1.1")
+	}
+	if result.Headers.Get("Cache-Control") != "max-age=60" {
+		t.Errorf("Cache-Control = %q", result.Headers.Get("Cache-Control"))
+	}
+	if len(result.Headers.Values("Set-Cookie")) != 2 {
+		t.Errorf("Set-Cookie values = %v, want 2", result.Headers.Values("Set-Cookie"))
+	}
+	if result.Size != 5 {
+		t.Errorf("Size = %d, want 5", result.Size)
+	}
+	if result.FinalURL != server.URL+"/new" {
+		t.Errorf("FinalURL = %q, want %q", result.FinalURL, server.URL+"/new")
+	}
+	if result.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("RemoteAddr = %q, want %q", result.RemoteAddr, server.Listener.Addr().String())
+	}
+	if result.TLSVersion != "" {
+		t.Errorf("TLSVersion = %q, want empty for plain HTTP", result.TLSVersion)
+	}
 }
@@ -0,0 +1,371 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfnilsson/getboy/internal/ui/theme"
+)
+
+const cookiesFileName = "cookies.json"
+
+// storedCookie is a cookie kept in a jar
+type storedCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitzero"` // zero for session cookies
+	Secure   bool      `json:"secure,omitempty"`
+	HTTPOnly bool      `json:"http_only,omitempty"`
+	HostOnly bool      `json:"host_only,omitempty"` // sent to Domain only, not its subdomains
+}
+
+// expired reports whether the cookie has expired
+func (c storedCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// sameCookie reports whether a and b are the same cookie, which a new value replaces
+func sameCookie(a, b storedCookie) bool {
+	return a.Name == b.Name && a.Domain == b.Domain && a.Path == b.Path
+}
+
+// cookiesMu serializes access to the cookie file
+var cookiesMu sync.Mutex
+
+// loadCookieStore reads every environment's cookies from disk, keyed by
+// environment name ("" when no environment is active)
+func loadCookieStore() (map[string][]storedCookie, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, cookiesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]storedCookie{}, nil
+		}
+		return nil, err
+	}
+	store := map[string][]storedCookie{}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// saveCookieStore writes every environment's cookies to disk
+func saveCookieStore(store map[string][]storedCookie) error {
+	dir, err := getDataDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, cookiesFileName), data, 0600)
+}
+
+// loadCookies returns an environment's unexpired cookies, sorted by domain
+func loadCookies(env string) ([]storedCookie, error) {
+	cookiesMu.Lock()
+	defer cookiesMu.Unlock()
+	store, err := loadCookieStore()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	cookies := slices.DeleteFunc(store[env], func(c storedCookie) bool { return c.expired(now) })
+	slices.SortStableFunc(cookies, func(a, b storedCookie) int {
+		return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Path, b.Path))
+	})
+	return cookies, nil
+}
+
+// updateCookies replaces an environment's cookies with the result of fn
+func updateCookies(env string, fn func([]storedCookie) []storedCookie) error {
+	cookiesMu.Lock()
+	defer cookiesMu.Unlock()
+	store, err := loadCookieStore()
+	if err != nil {
+		return err
+	}
+	cookies := fn(store[env])
+	if len(cookies) == 0 {
+		delete(store, env)
+	} else {
+		store[env] = cookies
+	}
+	return saveCookieStore(store)
+}
+
+// cookieJar is an http.CookieJar persisted to disk per environment
+type cookieJar struct {
+	env string
+}
+
+// newCookieJar returns the jar of an environment
+func newCookieJar(env string) *cookieJar {
+	return &cookieJar{env: env}
+}
+
+// SetCookies stores the cookies a response set, following RFC 6265
+// without a public suffix list
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := canonicalHost(u.Host)
+	now := time.Now()
+	_ = updateCookies(j.env, func(stored []storedCookie) []storedCookie { // Ignore error, the jar is best-effort
+		for _, c := range cookies {
+			sc, ok := newStoredCookie(c, host, u.Path, now)
+			if !ok {
+				continue
+			}
+			stored = slices.DeleteFunc(stored, func(o storedCookie) bool { return sameCookie(o, sc) })
+			if !sc.expired(now) {
+				stored = append(stored, sc)
+			}
+		}
+		return slices.DeleteFunc(stored, func(c storedCookie) bool { return c.expired(now) })
+	})
+}
+
+// Cookies returns the cookies to send to a URL
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	stored, err := loadCookies(j.env)
+	if err != nil {
+		return nil
+	}
+	host := canonicalHost(u.Host)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var matched []storedCookie
+	for _, c := range stored {
+		if c.Secure && u.Scheme != "https" {
+			continue
+		}
+		if (c.HostOnly && host != c.Domain) || (!c.HostOnly && !domainMatch(host, c.Domain)) {
+			continue
+		}
+		if !pathMatch(path, c.Path) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	// More specific paths first
+	slices.SortStableFunc(matched, func(a, b storedCookie) int { return cmp.Compare(len(b.Path), len(a.Path)) })
+
+	out := make([]*http.Cookie, len(matched))
+	for i, c := range matched {
+		out[i] = &http.Cookie{Name: c.Name, Value: c.Value}
+	}
+	return out
+}
+
+// newStoredCookie validates a Set-Cookie from host and fills in its scope
+func newStoredCookie(c *http.Cookie, host, reqPath string, now time.Time) (storedCookie, bool) {
+	sc := storedCookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HTTPOnly: c.HttpOnly,
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	switch {
+	case domain == "":
+		sc.Domain, sc.HostOnly = host, true
+	case net.ParseIP(host) != nil:
+		// IP addresses only take cookies for themselves
+		if domain != host {
+			return storedCookie{}, false
+		}
+		sc.Domain, sc.HostOnly = host, true
+	case !strings.Contains(domain, ".") && domain != host, !domainMatch(host, domain):
+		// Reject cookies for other sites or a bare top-level domain
+		return storedCookie{}, false
+	default:
+		sc.Domain = domain
+	}
+
+	if sc.Path == "" || sc.Path[0] != '/' {
+		sc.Path = defaultCookiePath(reqPath)
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		sc.Expires = now // Deletes the cookie
+	case c.MaxAge > 0:
+		sc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		sc.Expires = c.Expires
+	}
+	return sc, true
+}
+
+// canonicalHost returns the lowercase host without its port
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// domainMatch reports whether host is domain or one of its subdomains
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch reports whether a request path is within a cookie path
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath returns the directory of the request path
+func defaultCookiePath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// openCookies shows the cookie manager for the active environment
+func (m *model) openCookies() {
+	m.openModal(modalCookies)
+	m.reloadCookies()
+}
+
+// reloadCookies reads the active environment's cookies into the manager
+func (m *model) reloadCookies() {
+	cookies, err := loadCookies(m.envs.Active)
+	if err != nil {
+		m.err = fmt.Errorf("cookies: %w", err)
+	}
+	m.cookies = cookies
+	m.modalIdx = min(m.modalIdx, max(len(m.cookies)-1, 0))
+}
+
+// updateCookieManager handles keys in the cookie manager
+func (m *model) updateCookieManager(key string) {
+	switch key {
+	case "esc", "q", "c":
+		m.modal = modalNone
+	case "up", "k":
+		if m.modalIdx > 0 {
+			m.modalIdx--
+		}
+	case "down", "j":
+		if m.modalIdx < len(m.cookies)-1 {
+			m.modalIdx++
+		}
+	case "e", "enter":
+		if m.modalIdx < len(m.cookies) {
+			c := m.cookies[m.modalIdx]
+			m.openPrompt(promptCookieValue, fmt.Sprintf("Value of %s (%s):", c.Name, c.Domain), c.Value)
+		}
+	case "d":
+		if m.modalIdx < len(m.cookies) {
+			c := m.cookies[m.modalIdx]
+			m.changeCookies(func(cs []storedCookie) []storedCookie {
+				return slices.DeleteFunc(cs, func(o storedCookie) bool { return sameCookie(o, c) })
+			})
+		}
+	case "D":
+		// Delete every cookie of the selected cookie's domain
+		if m.modalIdx < len(m.cookies) {
+			domain := m.cookies[m.modalIdx].Domain
+			m.changeCookies(func(cs []storedCookie) []storedCookie {
+				return slices.DeleteFunc(cs, func(o storedCookie) bool { return o.Domain == domain })
+			})
+		}
+	}
+}
+
+// setCookieValue replaces the value of the cookie selected in the manager
+func (m *model) setCookieValue(value string) {
+	if m.modalIdx >= len(m.cookies) {
+		return
+	}
+	c := m.cookies[m.modalIdx]
+	m.changeCookies(func(cs []storedCookie) []storedCookie {
+		for i := range cs {
+			if sameCookie(cs[i], c) {
+				cs[i].Value = value
+			}
+		}
+		return cs
+	})
+}
+
+// changeCookies edits the active environment's jar and refreshes the manager
+func (m *model) changeCookies(fn func([]storedCookie) []storedCookie) {
+	if err := updateCookies(m.envs.Active, fn); err != nil {
+		m.err = fmt.Errorf("cookies: %w", err)
+		return
+	}
+	m.reloadCookies()
+}
+
+// viewCookieManager renders the jar's cookies grouped by domain
+func (m model) viewCookieManager(width int) string {
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+	domainStyle := lipgloss.NewStyle().Bold(true)
+
+	env := m.envs.Active
+	if env == "" {
+		env = "no environment"
+	}
+	lines := []string{faintStyle.Render("Jar: " + env), ""}
+
+	if len(m.cookies) == 0 {
+		lines = append(lines, faintStyle.Render("  No cookies"))
+	}
+	domain := ""
+	for i, c := range m.cookies {
+		if c.Domain != domain {
+			domain = c.Domain
+			lines = append(lines, domainStyle.Render(domain))
+		}
+		expires := "session"
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Local().Format("2006-01-02 15:04")
+		}
+		line := fmt.Sprintf("%s=%s  %s  %s", c.Name, c.Value, c.Path, expires)
+		if c.Secure {
+			line += "  secure"
+		}
+		line = truncateURL(line, max(width-8, 10))
+		if i == m.modalIdx {
+			line = selectedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", faintStyle.Render("j/k: select  e: edit value  d: delete  D: delete domain  esc: close"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cookieNames returns the sorted names of the cookies the jar sends to rawURL
+func cookieNames(jar *cookieJar, rawURL string) string {
+	u, _ := url.Parse(rawURL)
+	var names []string
+	for _, c := range jar.Cookies(u) {
+		names = append(names, c.Name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ",")
+}
+
+// TestCookieJarScope tests domain, path, secure and expiry rules
+func TestCookieJarScope(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	jar := newCookieJar("dev")
+	from, _ := url.Parse("https://api.example.com:8443/v1/login")
+	jar.SetCookies(from, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "shared", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "1", Path: "/", Secure: true},
+		{Name: "deep", Value: "1", Path: "/v1/users"},
+		{Name: "other", Value: "1", Domain: "other.com"},
+		{Name: "tld", Value: "1", Domain: "com"},
+		{Name: "gone", Value: "1", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.example.com/v1/x", "host,secure,shared"},
+		{"http://api.example.com/v1/x", "host,shared"},
+		{"https://api.example.com/v1/users/7", "deep,host,secure,shared"},
+		{"https://api.example.com/v10", "secure,shared"},
+		{"https://www.example.com/v1/x", "shared"},
+		{"https://other.com/", ""},
+	}
+	for _, tt := range tests {
+		if got := cookieNames(jar, tt.url); got != tt.want {
+			t.Errorf("cookies for %s = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	// Max-Age=0 deletes a cookie
+	jar.SetCookies(from, []*http.Cookie{{Name: "host", MaxAge: -1}})
+	if got := cookieNames(jar, "https://api.example.com/v1/x"); strings.Contains(got, "host") {
+		t.Errorf("deleted cookie still sent: %q", got)
+	}
+}
+
+// TestCookieJarPerEnvironment tests that session cookies persist per
+// environment and can be left out of a request
+func TestCookieJarPerEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Cookie")))
+	}))
+	defer server.Close()
+
+	send := func(path, env string, s requestSettings) string {
+		msg := executeRequest(context.Background(), requestSpec{Method: "GET", URL: server.URL + path, Settings: s}, nil, newCookieJar(env))
+		if msg.Err != nil {
+			t.Fatalf("request failed: %v", msg.Err)
+		}
+		return msg.Body
+	}
+
+	send("/login", "dev", requestSettings{})
+	if got := send("/me", "dev", requestSettings{}); got != "session=abc" {
+		t.Errorf("dev sent Cookie %q, want the session cookie", got)
+	}
+	if got := send("/me", "prod", requestSettings{}); got != "" {
+		t.Errorf("prod sent Cookie %q, want none", got)
+	}
+	if got := send("/me", "dev", requestSettings{Cookies: boolPtr(false)}); got != "" {
+		t.Errorf("with the jar off sent Cookie %q, want none", got)
+	}
+
+	cookies, err := loadCookies("dev")
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Errorf("stored dev cookies = %+v (%v)", cookies, err)
+	}
+}
+
+// TestCookieManager tests editing and deleting cookies in the manager
+func TestCookieManager(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	err := updateCookies("", func([]storedCookie) []storedCookie {
+		return []storedCookie{
+			{Name: "a", Value: "1", Domain: "a.com", Path: "/", HostOnly: true},
+			{Name: "b", Value: "2", Domain: "a.com", Path: "/", HostOnly: true},
+			{Name: "c", Value: "3", Domain: "b.com", Path: "/", HostOnly: true},
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(key("c"))
+	if m.modal != modalCookies || len(m.cookies) != 3 {
+		t.Fatalf("c should open the cookie manager, modal = %v, cookies = %d", m.modal, len(m.cookies))
+	}
+	if view := m.View(); !strings.Contains(view, "a.com") || !strings.Contains(view, "c=3") {
+		t.Errorf("manager should list cookies by domain:\n%s", view)
+	}
+
+	press(key("j"))
+	press(key("e"))
+	m.prompt.SetValue("two")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.cookies[1].Value != "two" {
+		t.Errorf("edited value = %q, want two", m.cookies[1].Value)
+	}
+
+	press(key("D"))
+	cookies, _ := loadCookies("")
+	if len(cookies) != 1 || cookies[0].Domain != "b.com" {
+		t.Errorf("after deleting a.com cookies = %+v", cookies)
+	}
+
+	press(key("d"))
+	if len(m.cookies) != 0 {
+		t.Errorf("d should delete the selected cookie, left %+v", m.cookies)
+	}
+}
@@ -0,0 +1,474 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// isCurlCommand reports whether s looks like a pasted curl command
+func isCurlCommand(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "curl" || strings.HasPrefix(s, "curl ") || strings.HasPrefix(s, "curl\t") ||
+		strings.HasPrefix(s, "curl\\") || strings.HasPrefix(s, "curl\n") || strings.HasPrefix(s, "curl\r")
+}
+
+// shellSplit splits a command line into words the way a POSIX shell would:
+// single and double quotes, $'...' escapes, backslash escapes and
+// backslash-newline line continuations are all handled.
+func shellSplit(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	rs := []rune(strings.ReplaceAll(s, "\r\n", "\n"))
+
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\\':
+			if i+1 >= len(rs) {
+				cur.WriteRune(r)
+				inWord = true
+				continue
+			}
+			i++
+			if rs[i] == '\n' {
+				continue // Line continuation
+			}
+			cur.WriteRune(rs[i])
+			inWord = true
+		case r == '\'':
+			end := indexRune(rs, '\'', i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			cur.WriteString(string(rs[i+1 : end]))
+			i = end
+			inWord = true
+		case r == '$' && i+1 < len(rs) && rs[i+1] == '\'':
+			n, err := readANSIQuoted(rs[i+2:], &cur)
+			if err != nil {
+				return nil, err
+			}
+			i += 1 + n // Leaves i on the closing quote
+			inWord = true
+		case r == '"':
+			n, err := readDoubleQuoted(rs[i+1:], &cur)
+			if err != nil {
+				return nil, err
+			}
+			i += n // Leaves i on the closing quote
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// indexRune returns the index of r in rs at or after from, or -1
+func indexRune(rs []rune, r rune, from int) int {
+	for i := from; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// readDoubleQuoted reads a double quoted string up to and including the
+// closing quote, returning how many runes were consumed
+func readDoubleQuoted(rs []rune, out *strings.Builder) (int, error) {
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(rs) {
+				switch rs[i+1] {
+				case '\n':
+					i++ // Line continuation
+					continue
+				case '"', '\\', '$', '`':
+					i++
+					out.WriteRune(rs[i])
+					continue
+				}
+			}
+			out.WriteRune('\\')
+		default:
+			out.WriteRune(rs[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// readANSIQuoted reads a $'...' string up to and including the closing
+// quote, returning how many runes were consumed
+func readANSIQuoted(rs []rune, out *strings.Builder) (int, error) {
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '\'' {
+			return i + 1, nil
+		}
+		if rs[i] != '\\' || i+1 >= len(rs) {
+			out.WriteRune(rs[i])
+			continue
+		}
+		i++
+		switch rs[i] {
+		case 'n':
+			out.WriteRune('\n')
+		case 't':
+			out.WriteRune('\t')
+		case 'r':
+			out.WriteRune('\r')
+		case 'x', 'u', 'U':
+			digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[rs[i]]
+			end := i + 1
+			for end < len(rs) && end-i-1 < digits && isHexDigit(rs[end]) {
+				end++
+			}
+			v, err := strconv.ParseUint(string(rs[i+1:end]), 16, 32)
+			if err != nil {
+				out.WriteRune('\\')
+				out.WriteRune(rs[i])
+				continue
+			}
+			if rs[i] == 'x' {
+				out.WriteByte(byte(v))
+			} else {
+				out.WriteRune(rune(v))
+			}
+			i = end - 1
+		default:
+			// \\, \', \" and anything unknown map to the character itself
+			out.WriteRune(rs[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+// isHexDigit reports whether r is a hexadecimal digit
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// curlShortOptions maps curl's single-letter options to their long form.
+// Letters not listed here are switches we don't act on.
+var curlShortOptions = map[byte]string{
+	'X': "--request", 'H': "--header", 'd': "--data", 'u': "--user",
+	'b': "--cookie", 'A': "--user-agent", 'e': "--referer", 'm': "--max-time",
+	'k': "--insecure", 'L': "--location", 'G': "--get", 'I': "--head",
+	'F': "--form", 'T': "--upload-file",
+	// Options that take a value we ignore, so the value isn't mistaken for the URL
+	'o': "--output", 'x': "--proxy", 'w': "--write-out", 'E': "--cert",
+	'r': "--range", 'c': "--cookie-jar", 'K': "--config", 'U': "--proxy-user",
+	'Y': "--speed-limit", 'y': "--speed-time", 'z': "--time-cond", 'C': "--continue-at",
+	'D': "--dump-header",
+}
+
+// curlValueOptions are the long curl options that take a value
+var curlValueOptions = map[string]bool{
+	"--request": true, "--header": true, "--data": true, "--data-raw": true,
+	"--data-binary": true, "--data-ascii": true, "--data-urlencode": true, "--json": true,
+	"--user": true, "--cookie": true, "--user-agent": true, "--referer": true,
+	"--max-time": true, "--max-redirs": true, "--url": true, "--form": true,
+	"--form-string": true, "--upload-file": true, "--output": true, "--proxy": true,
+	"--write-out": true, "--cert": true, "--key": true, "--cacert": true,
+	"--range": true, "--cookie-jar": true, "--config": true, "--proxy-user": true,
+	"--speed-limit": true, "--speed-time": true, "--time-cond": true, "--continue-at": true,
+	"--connect-timeout": true, "--resolve": true, "--retry": true, "--interface": true,
+	"--limit-rate": true, "--max-filesize": true, "--dump-header": true, "--oauth2-bearer": true,
+	"--cert-type": true, "--key-type": true, "--capath": true, "--unix-socket": true,
+	"--proxy-header": true, "--output-dir": true,
+}
+
+// curlURL picks the URL among a curl command's positional arguments: the
+// first with a scheme, else the first naming a host, else the first
+func curlURL(args []string) string {
+	best, bestRank := "", 3
+	for _, a := range args {
+		if rank := curlURLRank(a); rank < bestRank {
+			best, bestRank = a, rank
+		}
+	}
+	return best
+}
+
+// curlURLRank ranks how much an argument looks like a URL, lowest first
+func curlURLRank(s string) int {
+	if strings.Contains(s, "://") || strings.HasPrefix(s, "${") {
+		return 0
+	}
+	host, _, _ := strings.Cut(s, "/")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" || net.ParseIP(host) != nil || strings.Contains(host, ".") {
+		return 1
+	}
+	return 2
+}
+
+// parseCurl turns a curl command line into a request
+func parseCurl(cmd string) (requestSpec, error) {
+	words, err := shellSplit(cmd)
+	if err != nil {
+		return requestSpec{}, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return requestSpec{}, fmt.Errorf("not a curl command")
+	}
+
+	var (
+		spec     = requestSpec{Headers: map[string]string{}}
+		data     []string
+		form     []formField
+		bodyFile string
+		upload   bool
+		getData  bool
+		head     bool
+		urls     []string // positional arguments, one of which is the URL
+	)
+
+	// Expand combined and attached short options (-sSL, -XPOST) into long ones
+	var args []string
+	expectValue := false
+	for _, w := range words[1:] {
+		if expectValue {
+			// The value of the previous option, even if it starts with '-'
+			args = append(args, w)
+			expectValue = false
+			continue
+		}
+		if len(w) < 2 || w[0] != '-' || w[1] == '-' {
+			args = append(args, w)
+			expectValue = curlValueOptions[w]
+			continue
+		}
+		for j := 1; j < len(w); j++ {
+			long, ok := curlShortOptions[w[j]]
+			if !ok {
+				continue
+			}
+			args = append(args, long)
+			if curlValueOptions[long] {
+				if j+1 < len(w) {
+					args = append(args, w[j+1:])
+				} else {
+					expectValue = true
+				}
+				break
+			}
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			urls = append(urls, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			urls = append(urls, arg)
+			continue
+		}
+
+		// Long options may carry their value after '='
+		name, value, hasValue := strings.Cut(arg, "=")
+		if curlValueOptions[name] {
+			if !hasValue {
+				if i+1 >= len(args) {
+					return requestSpec{}, fmt.Errorf("%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+		}
+
+		switch name {
+		case "--request":
+			spec.Method = strings.ToUpper(value)
+		case "--header":
+			k, v, _ := strings.Cut(value, ":")
+			if k = strings.TrimSpace(k); k != "" {
+				spec.Headers[k] = strings.TrimSpace(v)
+			}
+		case "--data", "--data-ascii", "--data-binary":
+			if path, ok := strings.CutPrefix(value, "@"); ok {
+				bodyFile = path
+				continue
+			}
+			if name != "--data-binary" {
+				value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+			}
+			data = append(data, value)
+		case "--data-raw":
+			data = append(data, value)
+		case "--data-urlencode":
+			data = append(data, curlURLEncode(value))
+		case "--json":
+			data = append(data, value)
+			setDefaultHeader(spec.Headers, "Content-Type", "application/json")
+			setDefaultHeader(spec.Headers, "Accept", "application/json")
+		case "--user":
+			user, pass, _ := strings.Cut(value, ":")
+			spec.Auth = requestAuth{Type: authBasic, Username: user, Password: pass}
+		case "--cookie":
+			if strings.Contains(value, "=") {
+				spec.Headers["Cookie"] = value
+			}
+		case "--oauth2-bearer":
+			spec.Auth = requestAuth{Type: authBearer, Token: value}
+		case "--user-agent":
+			spec.Headers["User-Agent"] = value
+		case "--referer":
+			spec.Headers["Referer"] = value
+		case "--url":
+			spec.URL = value
+		case "--form", "--form-string":
+			k, v, ok := strings.Cut(value, "=")
+			if !ok {
+				return requestSpec{}, fmt.Errorf("%s needs name=value, got %q", name, value)
+			}
+			f := formField{Key: k, Value: v}
+			if name == "--form" {
+				if strings.HasPrefix(v, "<") {
+					return requestSpec{}, fmt.Errorf("reading a form field from a file (%s) is not supported", value)
+				}
+				if path, ok := strings.CutPrefix(v, "@"); ok {
+					// Drop ;type= and ;filename= modifiers
+					path, _, _ = strings.Cut(path, ";")
+					f = formField{Key: k, Value: path, File: true}
+				}
+			}
+			form = append(form, f)
+		case "--upload-file":
+			bodyFile, upload = value, true
+		case "--insecure":
+			spec.Settings.Insecure = boolPtr(true)
+		case "--location":
+			spec.Settings.FollowRedirects = boolPtr(true)
+		case "--max-redirs":
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				spec.Settings.MaxRedirects = &n
+			}
+		case "--max-time":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f >= 0 {
+				n := int(math.Ceil(f))
+				spec.Settings.TimeoutSec = &n
+			}
+		case "--no-keepalive":
+			spec.Settings.KeepAlive = boolPtr(false)
+		case "--get":
+			getData = true
+		case "--head":
+			head = true
+		}
+	}
+
+	if spec.URL == "" {
+		spec.URL = curlURL(urls)
+	}
+	if spec.URL == "" {
+		return requestSpec{}, fmt.Errorf("no URL in curl command")
+	}
+
+	bodies := 0
+	for _, set := range []bool{len(form) > 0, len(data) > 0, bodyFile != ""} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return requestSpec{}, fmt.Errorf("mixing form fields, data and files in one body is not supported")
+	}
+	switch {
+	case len(form) > 0:
+		spec.BodyType, spec.Form = bodyMultipart, form
+		// The multipart Content-Type is set when sending, with its boundary
+		spec.Headers = withoutHeader(spec.Headers, "Content-Type")
+	case bodyFile != "":
+		spec.BodyType, spec.BodyFile = bodyBinary, bodyFile
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case getData && body != "":
+		// -G sends the data as query parameters instead of a body
+		sep := "?"
+		if strings.Contains(spec.URL, "?") {
+			sep = "&"
+		}
+		spec.URL += sep + body
+	case body != "":
+		spec.Body = body
+		// curl sends data as a form unless told otherwise
+		setDefaultHeader(spec.Headers, "Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if spec.Method == "" {
+		switch {
+		case head:
+			spec.Method = "HEAD"
+		case upload:
+			spec.Method = "PUT"
+		case spec.hasBody():
+			spec.Method = "POST"
+		default:
+			spec.Method = "GET"
+		}
+	}
+	if len(spec.Headers) == 0 {
+		spec.Headers = nil
+	}
+	return spec, nil
+}
+
+// setDefaultHeader sets a header unless it is already present in any case
+func setDefaultHeader(headers map[string]string, key, value string) {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return
+		}
+	}
+	headers[key] = value
+}
+
+// curlURLEncode applies --data-urlencode's rules: "name=content" encodes
+// only the content, anything else is encoded as a whole
+func curlURLEncode(value string) string {
+	if name, content, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return escapeQueryKeepVars(content)
+		}
+		return name + "=" + escapeQueryKeepVars(content)
+	}
+	return escapeQueryKeepVars(value)
+}
+
+// importCurl replaces the editor contents with a parsed curl command
+func (m *model) importCurl(cmd string) {
+	spec, err := parseCurl(cmd)
+	if err != nil {
+		m.err = fmt.Errorf("curl import: %w", err)
+		return
+	}
+	m.err = nil
+	m.setRequest(spec)
+	m.syncParamsFromURL()
+	m.loadedPath = ""
+	m.status = "Imported curl command"
+}
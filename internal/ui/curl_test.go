@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestShellSplit tests quoting, escapes and line continuations
+func TestShellSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"plain", "curl  -s\thttps://a.com", []string{"curl", "-s", "https://a.com"}},
+		{"single quotes", `curl -H 'X-A: b c' 'it"s'`, []string{"curl", "-H", "X-A: b c", `it"s`}},
+		{"double quotes", `curl -d "say \"hi\" \$HOME \n"`, []string{"curl", "-d", `say "hi" $HOME \n`}},
+		{"ansi-c quotes", `curl --data-raw $'{"a":"it\'s"}\n'`, []string{"curl", "--data-raw", "{\"a\":\"it's\"}\n"}},
+		{"ansi-c hex", `$'\x41é'x`, []string{"Aéx"}},
+		{"backslash escape", `a\ b`, []string{"a b"}},
+		{"adjacent quotes join", `'a'"b"c`, []string{"abc"}},
+		{"empty quotes", `curl ''`, []string{"curl", ""}},
+		{"line continuation", "curl 'https://a.com' \\\n  -H 'A: 1' \\\r\n  -k", []string{"curl", "https://a.com", "-H", "A: 1", "-k"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellSplit(tt.in)
+			if err != nil {
+				t.Fatalf("shellSplit(%q) error: %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shellSplit(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{`curl 'open`, `curl "open`, `curl $'open`} {
+		if _, err := shellSplit(in); err == nil {
+			t.Errorf("shellSplit(%q) should fail on the unterminated quote", in)
+		}
+	}
+}
+
+// TestParseCurl tests turning curl commands into requests
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want requestSpec
+	}{
+		{
+			name: "get",
+			in:   "curl https://api.example.com/users?page=2",
+			want: requestSpec{Method: "GET", URL: "https://api.example.com/users?page=2"},
+		},
+		{
+			name: "devtools copy as bash",
+			in: "curl 'https://api.example.com/items' \\\n" +
+				"  -H 'accept: application/json' \\\n" +
+				"  -H 'content-type: application/json' \\\n" +
+				"  --data-raw $'{\"name\":\"it\\'s\"}' \\\n" +
+				"  --compressed",
+			want: requestSpec{
+				Method:  "POST",
+				URL:     "https://api.example.com/items",
+				Body:    `{"name":"it's"}`,
+				Headers: map[string]string{"accept": "application/json", "content-type": "application/json"},
+			},
+		},
+		{
+			name: "attached method and combined switches",
+			in:   `curl -sSLkXDELETE https://a.com/1 -m 2.5`,
+			want: requestSpec{
+				Method: "DELETE",
+				URL:    "https://a.com/1",
+				Settings: requestSettings{
+					FollowRedirects: boolPtr(true),
+					Insecure:        boolPtr(true),
+					TimeoutSec:      intPtr(3),
+				},
+			},
+		},
+		{
+			name: "basic auth and form data",
+			in:   `curl -u alice:s3cret -d a=1 --data b=2 https://a.com/login`,
+			want: requestSpec{
+				Method:  "POST",
+				URL:     "https://a.com/login",
+				Body:    "a=1&b=2",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Auth:    requestAuth{Type: authBasic, Username: "alice", Password: "s3cret"},
+			},
+		},
+		{
+			name: "data that looks like a flag",
+			in:   `curl -d -1 --url https://a.com`,
+			want: requestSpec{
+				Method:  "POST",
+				URL:     "https://a.com",
+				Body:    "-1",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name: "get with data",
+			in:   `curl -G https://a.com/search?x=1 --data-urlencode 'q=a b&c'`,
+			want: requestSpec{Method: "GET", URL: "https://a.com/search?x=1&q=a+b%26c"},
+		},
+		{
+			name: "json",
+			in:   `curl --json '{"a":1}' -X PUT https://a.com`,
+			want: requestSpec{
+				Method:  "PUT",
+				URL:     "https://a.com",
+				Body:    `{"a":1}`,
+				Headers: map[string]string{"Content-Type": "application/json", "Accept": "application/json"},
+			},
+		},
+		{
+			name: "head with ignored output",
+			in:   `curl -I -o /dev/null -w '%{http_code}' https://a.com`,
+			want: requestSpec{Method: "HEAD", URL: "https://a.com"},
+		},
+		{
+			name: "variables are kept",
+			in:   `curl "${BASE_URL}/me" -H "Authorization: Bearer ${TOKEN}"`,
+			want: requestSpec{
+				Method:  "GET",
+				URL:     "${BASE_URL}/me",
+				Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+			},
+		},
+		{
+			name: "multipart form",
+			in:   `curl -F 'name=a b' -F 'avatar=@~/me.png;type=image/png' --form-string 'note=@not a file' -H 'Content-Type: multipart/form-data' https://a.com`,
+			want: requestSpec{
+				Method:   "POST",
+				URL:      "https://a.com",
+				BodyType: bodyMultipart,
+				Form: []formField{
+					{Key: "name", Value: "a b"},
+					{Key: "avatar", Value: "~/me.png", File: true},
+					{Key: "note", Value: "@not a file"},
+				},
+			},
+		},
+		{
+			name: "body from a file",
+			in:   `curl --data-binary @body.json -H 'Content-Type: application/json' https://a.com`,
+			want: requestSpec{
+				Method:   "POST",
+				URL:      "https://a.com",
+				BodyType: bodyBinary,
+				BodyFile: "body.json",
+				Headers:  map[string]string{"Content-Type": "application/json"},
+			},
+		},
+		{
+			name: "dump header",
+			in:   `curl -D headers.txt https://api.example.com`,
+			want: requestSpec{Method: "GET", URL: "https://api.example.com"},
+		},
+		{
+			name: "long options with ignored values",
+			in: `curl --dump-header h.txt --cert-type PEM --key-type PEM --capath /etc/ssl ` +
+				`--unix-socket /run/d.sock --proxy-header 'X-P: 1' --output-dir out https://a.com`,
+			want: requestSpec{Method: "GET", URL: "https://a.com"},
+		},
+		{
+			name: "oauth2 bearer",
+			in:   `curl --oauth2-bearer t0k https://a.com`,
+			want: requestSpec{Method: "GET", URL: "https://a.com", Auth: requestAuth{Type: authBearer, Token: "t0k"}},
+		},
+		{
+			name: "url after an unknown option's value",
+			in:   `curl --trace trace.log https://a.com/x`,
+			want: requestSpec{Method: "GET", URL: "https://a.com/x"},
+		},
+		{
+			name: "host without a scheme",
+			in:   `curl --no-buffer localhost:8080/health`,
+			want: requestSpec{Method: "GET", URL: "localhost:8080/health"},
+		},
+		{
+			name: "upload file",
+			in:   `curl -T ./dump.bin https://a.com/upload`,
+			want: requestSpec{Method: "PUT", URL: "https://a.com/upload", BodyType: bodyBinary, BodyFile: "./dump.bin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCurl(tt.in)
+			if err != nil {
+				t.Fatalf("parseCurl error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCurl =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"wget https://a.com", "curl -s", "curl -F 'f=<a.txt' https://a.com", "curl -F f=1 -d a=1 https://a.com", "curl -H"} {
+		if _, err := parseCurl(in); err == nil {
+			t.Errorf("parseCurl(%q) should fail", in)
+		}
+	}
+}
+
+// TestImportCurlFromURLField tests pasting a curl command into the URL field
+func TestImportCurlFromURLField(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.editorPart = edURL
+	m.insertMode = true
+	m.applyFocus()
+	m.url.SetValue(`curl -X PATCH 'https://a.com/items?id=7' -H 'X-Trace: 1' -d '{"done":true}'`)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if m.err != nil {
+		t.Fatalf("unexpected error: %v", m.err)
+	}
+	if m.methodValue() != "PATCH" || m.url.Value() != "https://a.com/items?id=7" {
+		t.Errorf("method/url = %s %s", m.methodValue(), m.url.Value())
+	}
+	if m.body.Value() != `{"done":true}` {
+		t.Errorf("body = %q", m.body.Value())
+	}
+	if m.getHeaders()["X-Trace"] != "1" {
+		t.Errorf("headers = %v, want X-Trace", m.getHeaders())
+	}
+	if len(m.params) != 1 || m.params[0].key.Value() != "id" || m.params[0].value.Value() != "7" {
+		t.Error("query params should be filled in from the URL")
+	}
+	if m.loading {
+		t.Error("importing should not send the request")
+	}
+}
+
+// TestImportCurlDialog tests the import dialog with a multi-line command
+func TestImportCurlDialog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneEditor
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	m = updated.(model)
+	if m.modal != modalImportCurl {
+		t.Fatal("C should open the curl import dialog")
+	}
+
+	paste := "curl https://a.com/upload \\\n  -H 'A: 1' \\\n  -k"
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(paste), Paste: true})
+	m = updated.(model)
+	if m.curlInput.Value() != paste {
+		t.Fatalf("dialog value = %q, want the pasted command", m.curlInput.Value())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.modal != modalNone {
+		t.Fatal("enter should import and close the dialog")
+	}
+	if m.url.Value() != "https://a.com/upload" || m.getHeaders()["A"] != "1" || !m.reqSettings.insecure() {
+		t.Errorf("imported url=%q headers=%v insecure=%v", m.url.Value(), m.getHeaders(), m.reqSettings.insecure())
+	}
+}
+
+// TestInsecureSetting tests that -k style settings accept self-signed certificates
+func TestInsecureSetting(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // The rejected handshake is expected
+	server.StartTLS()
+	defer server.Close()
+
+	msg := doHTTP(requestSpec{Method: "GET", URL: server.URL}, nil)().(httpDoneMsg)
+	if msg.Err == nil {
+		t.Error("self-signed certificate should be rejected by default")
+	}
+
+	msg = doHTTP(requestSpec{Method: "GET", URL: server.URL, Settings: requestSettings{Insecure: boolPtr(true)}}, nil)().(httpDoneMsg)
+	if msg.Err != nil || msg.Body != "secure" {
+		t.Errorf("insecure request: body %q, err %v", msg.Body, msg.Err)
+	}
+}
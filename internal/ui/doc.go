@@ -1,4 +1,5 @@
 // Package ui implements the Bubble Tea TUI for getboy.
 // It contains the model (state), update loop, view rendering, layout,
-// styles, and non-blocking commands used by the program.
+// styles, and non-blocking commands used by the program, plus the
+// non-interactive run and send subcommands that share its request logic.
 package ui
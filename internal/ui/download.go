@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// defaultDownloadName is used when neither the response nor its URL names a file
+const defaultDownloadName = "response"
+
+// downloadResponse streams a response body to disk, returning the number of
+// bytes written and the file they went to. A target that is a directory, or
+// ends in a separator, receives the file under the response's file name.
+func downloadResponse(resp *http.Response, target string) (int64, string, error) {
+	dest := expandHome(target)
+	if info, err := os.Stat(dest); (err == nil && info.IsDir()) || strings.HasSuffix(target, "/") {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return 0, "", fmt.Errorf("download: %w", err)
+		}
+		dest = filepath.Join(dest, responseFileName(resp.Header, resp.Request.URL.Path))
+	}
+
+	n, err := replaceFile(dest, resp.Body)
+	if err != nil {
+		return 0, "", fmt.Errorf("download: %w", err)
+	}
+	return n, dest, nil
+}
+
+// replaceFile streams r into a temporary file and renames it over dest, so
+// a failed write leaves any existing file at dest untouched
+func replaceFile(dest string, r io.Reader) (int64, error) {
+	f, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), dest)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+// errDownloadStalled aborts a download that stopped receiving data
+var errDownloadStalled = errors.New("download stalled")
+
+// idleTimeout cancels a request when no data arrives for a while, so a
+// download can take as long as it needs while it keeps making progress
+type idleTimeout struct {
+	timer *time.Timer
+	d     time.Duration
+}
+
+// newIdleTimeout starts a timeout of d that calls cancel when it expires,
+// or returns nil if d is zero
+func newIdleTimeout(d time.Duration, cancel context.CancelCauseFunc) *idleTimeout {
+	if d <= 0 {
+		return nil
+	}
+	return &idleTimeout{timer: time.AfterFunc(d, func() {
+		cancel(fmt.Errorf("%w: no data for %s", errDownloadStalled, d))
+	}), d: d}
+}
+
+// body returns b, restarting the timeout whenever data is read from it
+func (t *idleTimeout) body(b io.ReadCloser) io.ReadCloser {
+	if t == nil {
+		return b
+	}
+	return idleBody{b, t}
+}
+
+// stop turns the timeout off
+func (t *idleTimeout) stop() {
+	if t != nil {
+		t.timer.Stop()
+	}
+}
+
+// idleBody is a response body that restarts an idleTimeout as it is read
+type idleBody struct {
+	io.ReadCloser
+	t *idleTimeout
+}
+
+func (b idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.t.timer.Reset(b.t.d)
+	}
+	return n, err
+}
+
+// responseFileName picks a file name for a response body, from its
+// Content-Disposition or else the last segment of the URL path
+func responseFileName(headers http.Header, urlPath string) string {
+	if _, params, err := mime.ParseMediaType(headers.Get("Content-Disposition")); err == nil {
+		// Never let the server choose the directory
+		if name := filepath.Base(params["filename"]); params["filename"] != "" && name != "." && name != "/" {
+			return name
+		}
+	}
+	if name := path.Base(urlPath); name != "." && name != "/" {
+		return name
+	}
+	return defaultDownloadName
+}
+
+// openSaveResponse asks where to save the last response body
+func (m *model) openSaveResponse() {
+	switch {
+	case m.resp == nil:
+		m.status = "No response to save"
+	case m.resp.Err != nil:
+		m.status = "The request failed, there is no body to save"
+	case m.resp.SavedTo != "":
+		m.status = "The body was already saved to " + m.resp.SavedTo
+	default:
+		name := responseFileName(m.resp.Headers, urlPath(m.resp.FinalURL))
+		m.openPrompt(promptSaveResponse, "Save body to:", name)
+	}
+}
+
+// urlPath returns the path of a URL, or an empty string if it doesn't parse
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// saveResponseBody writes the raw bytes of the last response to a file.
+// Variables in the path are expanded from the active environment.
+func (m *model) saveResponseBody(target string) {
+	if m.resp == nil || target == "" {
+		return
+	}
+	switch {
+	case m.resp.Truncated && m.resp.Restored:
+		m.err = fmt.Errorf("save response: history kept only the first %s of the body; send the request again to save it all", formatBytes(int64(len(m.resp.Body))))
+		return
+	case m.resp.Truncated:
+		m.err = fmt.Errorf("save response: only the first %s of the body were received; set a download path in the Settings tab to save it all", formatBytes(maxBufferedBody))
+		return
+	}
+	target, missing := expandVars(target, m.requestVars())
+	if len(missing) > 0 {
+		m.err = undefinedVarsError{names: missing}
+		return
+	}
+	dest := expandHome(target)
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, responseFileName(m.resp.Headers, urlPath(m.resp.FinalURL)))
+	}
+	if _, err := replaceFile(dest, strings.NewReader(m.resp.Body)); err != nil {
+		m.err = fmt.Errorf("save response: %w", err)
+		return
+	}
+	m.err = nil
+	m.status = fmt.Sprintf("Saved %s to %s", formatBytes(int64(len(m.resp.Body))), dest)
+}
+
+// newDownloadToInput creates the Settings tab's download path input
+func newDownloadToInput() textinput.Model {
+	in := textinput.New()
+	in.Placeholder = "off (path or directory/ to stream the body to)"
+	in.CharLimit = 4096
+	in.Prompt = ""
+	return in
+}
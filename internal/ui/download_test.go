@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestResponseFileName tests naming a downloaded body
+func TestResponseFileName(t *testing.T) {
+	tests := []struct {
+		disposition, path, want string
+	}{
+		{`attachment; filename="report.csv"`, "/export", "report.csv"},
+		{`attachment; filename="../../etc/passwd"`, "/x", "passwd"},
+		{"", "/files/image.png", "image.png"},
+		{"", "/", defaultDownloadName},
+		{"", "", defaultDownloadName},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.disposition != "" {
+			h.Set("Content-Disposition", tt.disposition)
+		}
+		if got := responseFileName(h, tt.path); got != tt.want {
+			t.Errorf("responseFileName(%q, %q) = %q, want %q", tt.disposition, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestDownloadToDisk tests streaming response bodies to a file or directory
+func TestDownloadToDisk(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	payload := bytes.Repeat([]byte{0, 1, 2, 0xff}, 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/export" {
+			w.Header().Set("Content-Disposition", `attachment; filename="export.csv"`)
+		}
+		_, _ = w.Write(payload)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		url, target, want string
+	}{
+		{server.URL + "/blob", "${OUT}/blob.bin", filepath.Join(dir, "blob.bin")},
+		{server.URL + "/export", "~/downloads/", filepath.Join(dir, "downloads", "export.csv")},
+		{server.URL + "/files/a.bin", dir, filepath.Join(dir, "a.bin")},
+	}
+	for _, tt := range tests {
+		spec := requestSpec{Method: "GET", URL: tt.url, Settings: requestSettings{DownloadTo: tt.target}}
+		msg := doHTTP(spec, map[string]string{"OUT": dir})().(httpDoneMsg)
+		if msg.Err != nil {
+			t.Fatalf("%s: request failed: %v", tt.target, msg.Err)
+		}
+		if msg.SavedTo != tt.want || msg.Body != "" || msg.Size != len(payload) {
+			t.Errorf("%s: saved %d bytes to %q with body %d, want %d to %q", tt.target, msg.Size, msg.SavedTo, len(msg.Body), len(payload), tt.want)
+		}
+		if got, err := os.ReadFile(tt.want); err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("%s: file holds %d bytes (%v), want the %d byte payload", tt.target, len(got), err, len(payload))
+		}
+	}
+
+	spec := requestSpec{Method: "GET", URL: server.URL, Settings: requestSettings{DownloadTo: filepath.Join(dir, "missing", "x")}}
+	if msg := doHTTP(spec, nil)().(httpDoneMsg); msg.Err == nil || !strings.Contains(msg.Err.Error(), "download") {
+		t.Errorf("download into a missing directory error = %v", msg.Err)
+	}
+
+	// The download path is never inherited from the global settings
+	if got := (requestSettings{}).over(requestSettings{DownloadTo: "/tmp/x"}); got.DownloadTo != "" {
+		t.Errorf("inherited download path %q", got.DownloadTo)
+	}
+}
+
+// TestDownloadFailures tests that slow downloads aren't cut off by the
+// request timeout, stalled ones are, and a failed download keeps the file
+// it would have replaced
+func TestDownloadFailures(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			// Longer than the timeout in total, but never idle for that long
+			for range 6 {
+				_, _ = w.Write([]byte("chunk\n"))
+				w.(http.Flusher).Flush()
+				time.Sleep(300 * time.Millisecond)
+			}
+		case "/stall":
+			_, _ = w.Write([]byte("start"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/cut":
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("partial"))
+		}
+	}))
+	defer server.Close()
+	download := func(path, target string) httpDoneMsg {
+		spec := requestSpec{Method: "GET", URL: server.URL + path, Settings: requestSettings{TimeoutSec: intPtr(1), DownloadTo: target}}
+		return doHTTP(spec, nil)().(httpDoneMsg)
+	}
+
+	if msg := download("/slow", filepath.Join(dir, "slow.txt")); msg.Err != nil || msg.Size != 36 {
+		t.Errorf("slow download = %d bytes, %v", msg.Size, msg.Err)
+	}
+	if msg := download("/stall", filepath.Join(dir, "stall.txt")); msg.Err == nil || !strings.Contains(msg.Err.Error(), "stalled") {
+		t.Errorf("stalled download error = %v", msg.Err)
+	}
+
+	existing := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(existing, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg := download("/cut", existing); msg.Err == nil {
+		t.Error("a cut off download should fail")
+	}
+	if got, err := os.ReadFile(existing); err != nil || string(got) != "original" {
+		t.Errorf("existing file = %q, %v", got, err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 2 {
+		t.Errorf("failed downloads should leave no partial files, got %d files", len(files))
+	}
+}
+
+// TestSaveResponseBody tests saving the shown response from the response pane
+func TestSaveResponseBody(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	body := "id,name\n1,\x00binary\n"
+	press(key("3"))
+	updated, _ = m.Update(httpDoneMsg{Status: "200 OK", StatusCode: 200, Body: body, FinalURL: "https://a.com/v1/users.csv?page=2"})
+	m = updated.(model)
+
+	press(key("s"))
+	if m.promptKind != promptSaveResponse || m.prompt.Value() != "users.csv" {
+		t.Fatalf("s should prompt with the URL's file name, got %v %q", m.promptKind, m.prompt.Value())
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got, err := os.ReadFile(filepath.Join(dir, "users.csv")); err != nil || string(got) != body {
+		t.Errorf("saved %q (%v), want the raw body", got, err)
+	}
+
+	// Saving again replaces the file without leaving temporary files behind
+	m.resp = &httpDoneMsg{Status: "200 OK", Body: "new"}
+	m.saveResponseBody("users.csv")
+	if got, err := os.ReadFile(filepath.Join(dir, "users.csv")); err != nil || string(got) != "new" {
+		t.Errorf("saved %q (%v), want the new body", got, err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(dir, ".*.part")); len(parts) > 0 {
+		t.Errorf("temporary files left behind: %v", parts)
+	}
+
+	// A body that was cut off isn't saved as if it were whole
+	for _, resp := range []httpDoneMsg{{Body: "part", Truncated: true}, {Body: "part", Truncated: true, Restored: true}} {
+		m.resp, m.err = &resp, nil
+		m.saveResponseBody("part.txt")
+		if _, err := os.Stat(filepath.Join(dir, "part.txt")); m.err == nil || err == nil {
+			t.Errorf("saving a truncated body should fail, got %v", m.err)
+		}
+	}
+
+	// The Settings tab only sets a download path per request
+	m.pane = paneEditor
+	m.activeTab = tabSettings
+	m.resetEditorPartForTab()
+	m.settingsIdx = setDownloadTo
+	press(key("i"))
+	for _, r := range "~/out/" {
+		press(key(string(r)))
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.currentRequest().Settings.DownloadTo; got != "~/out/" {
+		t.Errorf("request download path = %q", got)
+	}
+	press(key("g"))
+	press(key("i"))
+	if m.insertMode {
+		t.Error("the global settings should not take a download path")
+	}
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pfnilsson/getboy/internal/ui/theme"
 )
@@ -18,10 +20,14 @@ func (m model) viewEditor() string {
 		content = m.viewHeadersTab()
 	case tabBody:
 		content = m.viewBodyTab()
+	case tabAuth:
+		content = m.viewAuthTab()
+	case tabSettings:
+		content = m.viewSettingsTab()
 	}
 
 	// Define tabs with keybind hints
-	tabs := []string{"[O]verview", "[P]arams", "[H]eaders", "[B]ody"}
+	tabs := []string{"[O]verview", "[P]arams", "[H]eaders", "[B]ody", "A[u]th", "Se[t]tings"}
 
 	edBox := titledPaneWithTabs(
 		content,
@@ -86,6 +92,14 @@ func (m model) viewOverviewTab() string {
 
 // viewParamsTab renders the params tab with key-value pairs (like headers but no raw mode)
 func (m model) viewParamsTab() string {
+	// Available height: editor height - pane borders (2) - scroll indicators (2) - padding (1)
+	return m.viewKeyValueRows(m.params, m.paramIdx, m.paramField, m.activeTab == tabParams, m.editorHeight()-5, nil)
+}
+
+// viewKeyValueRows renders key-value rows as boxed inputs, scrolled to keep
+// the selected row visible. sep returns the text between a row's key and
+// value, " = " when nil.
+func (m model) viewKeyValueRows(rows []paramRow, selIdx int, field headerField, active bool, availableHeight int, sep func(i int) string) string {
 	faintStyle := lipgloss.NewStyle().Faint(true)
 
 	var lines []string
@@ -101,16 +115,14 @@ func (m model) viewParamsTab() string {
 
 	// Key-value mode with scrolling
 	rowHeight := 3
-	// Available height: editor height - pane borders (2) - scroll indicators (2) - padding (1)
-	availableHeight := m.editorHeight() - 5
-	totalParams := len(m.params)
+	totalParams := len(rows)
 
 	visibleRows := max(availableHeight/rowHeight, 1)
 
 	// Calculate scroll window to keep selected row visible
 	startIdx := 0
 	if totalParams > visibleRows {
-		startIdx = min(max(m.paramIdx-visibleRows/2, 0), totalParams-visibleRows)
+		startIdx = min(max(selIdx-visibleRows/2, 0), totalParams-visibleRows)
 	}
 	endIdx := min(startIdx+visibleRows, totalParams)
 
@@ -126,8 +138,8 @@ func (m model) viewParamsTab() string {
 	}
 
 	for i := startIdx; i < endIdx; i++ {
-		p := m.params[i]
-		isSelected := m.pane == paneEditor && m.activeTab == tabParams && m.paramIdx == i
+		p := rows[i]
+		isSelected := m.pane == paneEditor && active && selIdx == i
 
 		prefix := "  "
 		if isSelected && !m.insertMode {
@@ -139,7 +151,7 @@ func (m model) viewParamsTab() string {
 		valBoxStyle := inputBoxStyle
 
 		if isSelected {
-			if m.paramField == headerKey {
+			if field == headerKey {
 				keyBoxStyle = activeInputBoxStyle
 			} else {
 				valBoxStyle = activeInputBoxStyle
@@ -157,11 +169,16 @@ func (m model) viewParamsTab() string {
 		keyBox := keyBoxStyle.Width(keyWidth).Render(keyView)
 		valBox := valBoxStyle.Width(valWidth).Render(valView)
 
+		sepText := " = "
+		if sep != nil {
+			sepText = sep(i)
+		}
+
 		// Separator styled to align vertically with boxes
 		separator := lipgloss.NewStyle().
 			Height(3).
 			AlignVertical(lipgloss.Center).
-			Render(" = ")
+			Render(sepText)
 
 		// Prefix styled to align vertically
 		prefixStyled := lipgloss.NewStyle().
@@ -304,19 +321,182 @@ func (m model) viewHeadersTab() string {
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
-// viewBodyTab renders the body tab with the request body textarea
+// viewBodyTab renders the body type and the editor for it
 func (m model) viewBodyTab() string {
-	isEditing := m.pane == paneEditor && m.insertMode && m.activeTab == tabBody
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	typeLine := "Type: ◀ " + bodyTypeLabel(m.bodyType) + " ▶"
+	if m.pane == paneEditor && m.activeTab == tabBody && !m.insertMode {
+		typeLine = selectedStyle.Render(typeLine)
+	}
 
-	if isEditing {
+	var content string
+	switch {
+	case isFormBody(m.bodyType):
+		rows := make([]paramRow, len(m.formRows))
+		for i, r := range m.formRows {
+			rows[i] = r.paramRow
+		}
+		// The type line takes one more row than the params tab
+		content = m.viewKeyValueRows(rows, m.formIdx, m.formField, m.activeTab == tabBody, m.editorHeight()-6, func(i int) string {
+			if m.bodyType == bodyMultipart && m.formRows[i].file {
+				return " @ "
+			}
+			return " = "
+		})
+	case m.bodyType == bodyBinary:
+		content = "File: " + m.bodyFile.View()
+		if info := bodyFileInfo(m.bodyFile.Value(), m.requestVars()); info != "" {
+			content += "\n      " + faintStyle.Render(info)
+		}
+		content += "\n\n" + faintStyle.Render("Streamed from disk; the path can use ${VAR} and ~/")
+	case m.bodyType == bodyNone:
+		content = faintStyle.Render("This request has no body")
+	case m.pane == paneEditor && m.insertMode && m.activeTab == tabBody:
 		// Show plain textarea when editing
-		return m.body.View()
+		content = m.body.View()
+	case m.body.Value() == "":
+		content = m.body.View() // Show placeholder
+	default:
+		// Show syntax-highlighted content when not editing
+		content = m.highlightBodyContent(m.body.Value())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, typeLine, content)
+}
+
+// viewAuthTab renders the auth type and the fields it uses
+func (m model) viewAuthTab() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	in := "header"
+	if m.authIn == apiKeyInQuery {
+		in = "query parameter"
+	}
+
+	type authRow struct{ label, value string }
+	var rows []authRow
+	width := 0
+	for _, f := range m.authRows() {
+		var r authRow
+		switch f {
+		case authFieldType:
+			r = authRow{"Type:", "◀ " + authTypeLabel(m.authType) + " ▶"}
+		case authFieldUsername:
+			r = authRow{"Username:", m.authUsername.View()}
+		case authFieldPassword:
+			r = authRow{"Password:", m.authPassword.View()}
+		case authFieldToken:
+			r = authRow{"Token:", m.authToken.View()}
+		case authFieldKey:
+			r = authRow{"Key:", m.authKey.View()}
+		case authFieldValue:
+			r = authRow{"Value:", m.authValue.View()}
+		case authFieldIn:
+			r = authRow{"Add to:", in}
+		case authFieldGrant:
+			r = authRow{"Grant:", "◀ " + grantLabel(m.oauthGrant) + " ▶"}
+		case authFieldTokenURL:
+			r = authRow{"Token URL:", m.oauthTokenURL.View()}
+		case authFieldAuthURL:
+			r = authRow{"Auth URL:", m.oauthAuthURL.View()}
+		case authFieldRedirectURL:
+			r = authRow{"Redirect URL:", m.oauthRedirectURL.View()}
+		case authFieldClientID:
+			r = authRow{"Client ID:", m.oauthClientID.View()}
+		case authFieldClientSecret:
+			r = authRow{"Client secret:", m.oauthClientSecret.View()}
+		case authFieldScope:
+			r = authRow{"Scope:", m.oauthScope.View()}
+		}
+		width = max(width, len(r.label))
+		rows = append(rows, r)
 	}
 
-	// Show syntax-highlighted content when not editing
-	content := m.body.Value()
-	if content == "" {
-		return m.body.View() // Show placeholder
+	var lines []string
+	for i, r := range rows {
+		prefix := "  "
+		label := r.label + strings.Repeat(" ", width-len(r.label))
+		if m.pane == paneEditor && m.activeTab == tabAuth && m.authIdx == i && !m.insertMode {
+			prefix = "> "
+			label = selectedStyle.Render(label)
+		}
+		lines = append(lines, prefix+label+" "+r.value)
+	}
+
+	if m.authType == authOAuth2 {
+		lines = append(lines, "", faintStyle.Render("  Tokens are fetched on send and cached until they expire (r: forget token)"))
+	}
+	if m.authType != authNone {
+		lines = append(lines, "", faintStyle.Render("  Values can use ${VAR}; auth replaces a header of the same name"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// viewSettingsTab renders the client settings for this request or globally
+func (m model) viewSettingsTab() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	// Scope indicator: [Request / Global]
+	var scope string
+	if m.settingsGlobal {
+		scope = "  [" + faintStyle.Render("Request") + " / " + selectedStyle.Render("Global") + "]"
+	} else {
+		scope = "  [" + selectedStyle.Render("Request") + " / " + faintStyle.Render("Global") + "]"
+	}
+
+	s := m.editedSettings()
+	effective := s.over(m.settings)
+	if m.settingsGlobal {
+		effective = m.settings
+	}
+
+	rows := []struct {
+		label string
+		value string
+	}{
+		{"Timeout (s):     ", m.timeoutInput.View()},
+		{"Follow redirects:", m.toggleLabel(s.FollowRedirects, effective.followRedirects())},
+		{"Max redirects:   ", m.maxRedirectsInput.View()},
+		{"Keep-alive:      ", m.toggleLabel(s.KeepAlive, effective.keepAlive())},
+		{"Skip TLS verify: ", m.toggleLabel(s.Insecure, effective.insecure())},
+		{"Cookie jar:      ", m.toggleLabel(s.Cookies, effective.cookies())},
+		{"Download to:     ", m.downloadToInput.View()},
+	}
+	if m.settingsGlobal {
+		rows[setDownloadTo].value = faintStyle.Render("set per request")
+	}
+
+	lines := []string{scope, ""}
+	for i, r := range rows {
+		prefix := "  "
+		label := r.label
+		if m.pane == paneEditor && m.activeTab == tabSettings && m.settingsIdx == settingsField(i) && !m.insertMode {
+			prefix = "> "
+			label = selectedStyle.Render(label)
+		}
+		lines = append(lines, prefix+label+" "+r.value)
+	}
+
+	if !m.settingsGlobal {
+		lines = append(lines, "", faintStyle.Render("  Empty fields use the global settings (g)"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// toggleLabel renders an on/off setting, showing inherited values faintly
+func (m model) toggleLabel(v *bool, effective bool) string {
+	state := "off"
+	if effective {
+		state = "on"
+	}
+	if v != nil {
+		return state
+	}
+	if m.settingsGlobal {
+		return lipgloss.NewStyle().Faint(true).Render(state + " (default)")
 	}
-	return m.highlightBodyContent(content)
+	return lipgloss.NewStyle().Faint(true).Render(state + " (global)")
 }
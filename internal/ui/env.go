@@ -1,19 +1,140 @@
 package ui
 
 import (
+	"fmt"
+	"maps"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 )
 
 // envVarPattern matches ${VAR_NAME} patterns
 var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
-// expandEnvVars replaces ${VAR_NAME} patterns with their values from system environment.
-// Undefined variables are replaced with empty string.
-func expandEnvVars(s string) string {
-	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+// braceVarPattern matches {{var}} references, as Postman and .http files write them
+var braceVarPattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// fromBraceVars rewrites {{var}} references as ${var}
+func fromBraceVars(s string) string {
+	return braceVarPattern.ReplaceAllString(s, "$${$1}")
+}
+
+// toBraceVars rewrites ${var} references as {{var}}
+func toBraceVars(s string) string {
+	return envVarPattern.ReplaceAllString(s, "{{$1}}")
+}
+
+// expandVars replaces ${VAR_NAME} patterns, looking each variable up in vars
+// first and falling back to the system environment.
+// Undefined variables are left in place and returned in missing.
+func expandVars(s string, vars map[string]string) (expanded string, missing []string) {
+	expanded = envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
 		// Extract variable name from ${VAR_NAME}
 		varName := envVarPattern.FindStringSubmatch(match)[1]
-		return os.Getenv(varName)
+		if v, ok := vars[varName]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(varName); ok {
+			return v
+		}
+		missing = append(missing, varName)
+		return match
 	})
+	return expanded, missing
+}
+
+// mergeVars layers the environment's variables over a collection's
+func mergeVars(collection, env map[string]string) map[string]string {
+	if len(collection) == 0 {
+		return env
+	}
+	merged := maps.Clone(collection)
+	maps.Copy(merged, env)
+	return merged
+}
+
+// undefinedVarsError reports variables a request references but no
+// environment defines
+type undefinedVarsError struct {
+	names []string
+}
+
+func (e undefinedVarsError) Error() string {
+	return fmt.Sprintf("undefined variables: %s", strings.Join(e.names, ", "))
+}
+
+// resolveRequest substitutes variables in every part of a request: URL,
+// query parameters, header names and values, body, form fields, the body
+// file path, auth credentials and the download path.
+// It fails listing all undefined variables instead of sending placeholders;
+// the returned request then has those placeholders left in place.
+func resolveRequest(spec requestSpec, vars map[string]string) (requestSpec, error) {
+	var missing []string
+	expand := func(s string) string {
+		out, miss := expandVars(s, vars)
+		missing = append(missing, miss...)
+		return out
+	}
+
+	resolved := spec
+	resolved.URL = expandURL(spec.URL, expand)
+	resolved.Body = expand(spec.Body)
+	resolved.BodyFile = expand(spec.BodyFile)
+	resolved.Settings.DownloadTo = expand(spec.Settings.DownloadTo)
+	if spec.Form != nil {
+		resolved.Form = make([]formField, len(spec.Form))
+		for i, f := range spec.Form {
+			resolved.Form[i] = formField{Key: expand(f.Key), Value: expand(f.Value), File: f.File}
+		}
+	}
+	if spec.Headers != nil {
+		resolved.Headers = make(map[string]string, len(spec.Headers))
+		for k, v := range spec.Headers {
+			resolved.Headers[expand(k)] = expand(v)
+		}
+	}
+	resolved.Auth = requestAuth{
+		Type:     spec.Auth.Type,
+		Username: expand(spec.Auth.Username),
+		Password: expand(spec.Auth.Password),
+		Token:    expand(spec.Auth.Token),
+		Key:      expand(spec.Auth.Key),
+		Value:    expand(spec.Auth.Value),
+		In:       spec.Auth.In,
+		OAuth: oauthConfig{
+			Grant:        spec.Auth.OAuth.Grant,
+			TokenURL:     expand(spec.Auth.OAuth.TokenURL),
+			AuthURL:      expand(spec.Auth.OAuth.AuthURL),
+			ClientID:     expand(spec.Auth.OAuth.ClientID),
+			ClientSecret: expand(spec.Auth.OAuth.ClientSecret),
+			Scope:        expand(spec.Auth.OAuth.Scope),
+			RedirectURL:  expand(spec.Auth.OAuth.RedirectURL),
+		},
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return resolved, undefinedVarsError{names: slices.Compact(missing)}
+	}
+	return resolved, nil
+}
+
+// expandURL expands variables in a URL. Query parameters holding placeholders
+// are expanded after decoding and re-encoded, so values containing '&' or
+// spaces stay intact.
+func expandURL(u string, expand func(string) string) string {
+	base, rawQuery, fragment := splitURL(u)
+	out := expand(base)
+	if strings.Contains(rawQuery, "${") || strings.Contains(strings.ToUpper(rawQuery), "%24%7B") {
+		pairs := parseQuery(rawQuery)
+		for i := range pairs {
+			pairs[i].Key = expand(pairs[i].Key)
+			pairs[i].Value = expand(pairs[i].Value)
+		}
+		out += "?" + encodeQuery(pairs)
+	} else if rawQuery != "" {
+		out += "?" + rawQuery
+	}
+	return out + expand(fragment)
 }
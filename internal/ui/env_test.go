@@ -2,10 +2,14 @@ package ui
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
-func TestExpandEnvVars(t *testing.T) {
+func TestExpandVars(t *testing.T) {
 	// Set up test environment variables
 	_ = os.Setenv("TEST_VAR", "test_value")
 	_ = os.Setenv("API_KEY", "secret123")
@@ -47,9 +51,9 @@ func TestExpandEnvVars(t *testing.T) {
 			expected: "prefix test_value",
 		},
 		{
-			name:     "undefined variable becomes empty",
+			name:     "undefined variable is left in place",
 			input:    "prefix ${UNDEFINED_VAR} suffix",
-			expected: "prefix  suffix",
+			expected: "prefix ${UNDEFINED_VAR} suffix",
 		},
 		{
 			name:     "empty variable value",
@@ -90,14 +94,92 @@ func TestExpandEnvVars(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := expandEnvVars(tt.input)
+			result, _ := expandVars(tt.input, nil)
 			if result != tt.expected {
-				t.Errorf("expandEnvVars(%q) = %q, want %q", tt.input, result, tt.expected)
+				t.Errorf("expandVars(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
 		})
 	}
 }
 
+func TestExpandVarsReportsMissing(t *testing.T) {
+	_, missing := expandVars("${NOT_DEFINED_A}/${NOT_DEFINED_B}", map[string]string{})
+	if len(missing) != 2 || missing[0] != "NOT_DEFINED_A" || missing[1] != "NOT_DEFINED_B" {
+		t.Errorf("missing = %v, want [NOT_DEFINED_A NOT_DEFINED_B]", missing)
+	}
+}
+
+func TestResolveRequest(t *testing.T) {
+	vars := map[string]string{
+		"BASE_URL": "https://api.example",
+		"TOKEN":    "secret",
+		"HDR":      "X-Trace",
+		"Q":        "a b&c",
+		"ID":       "42",
+	}
+
+	t.Run("expands every part", func(t *testing.T) {
+		spec := requestSpec{
+			Method:  "POST",
+			URL:     "${BASE_URL}/users/${ID}?q=${Q}&page=1",
+			Body:    `{"id":"${ID}"}`,
+			Headers: map[string]string{"Authorization": "Bearer ${TOKEN}", "${HDR}": "on"},
+		}
+		got, err := resolveRequest(spec, vars)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.URL != "https://api.example/users/42?q=a+b%26c&page=1" {
+			t.Errorf("URL = %q", got.URL)
+		}
+		if got.Body != `{"id":"42"}` {
+			t.Errorf("Body = %q", got.Body)
+		}
+		if got.Headers["Authorization"] != "Bearer secret" {
+			t.Errorf("Authorization = %q", got.Headers["Authorization"])
+		}
+		if got.Headers["X-Trace"] != "on" {
+			t.Errorf("expanded header name missing, headers = %v", got.Headers)
+		}
+		// The input is not modified
+		if spec.Headers["Authorization"] != "Bearer ${TOKEN}" {
+			t.Error("resolveRequest should not modify its input")
+		}
+	})
+
+	t.Run("expands encoded placeholders in query", func(t *testing.T) {
+		got, err := resolveRequest(requestSpec{URL: "https://x/?id=%24%7BID%7D"}, vars)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.URL != "https://x/?id=42" {
+			t.Errorf("URL = %q", got.URL)
+		}
+	})
+
+	t.Run("leaves queries without placeholders untouched", func(t *testing.T) {
+		got, _ := resolveRequest(requestSpec{URL: "https://x/?debug&b=%2F"}, vars)
+		if got.URL != "https://x/?debug&b=%2F" {
+			t.Errorf("URL = %q", got.URL)
+		}
+	})
+
+	t.Run("lists undefined variables", func(t *testing.T) {
+		spec := requestSpec{
+			URL:     "${MISSING_HOST}/x?y=${MISSING_Q}",
+			Headers: map[string]string{"${MISSING_H}": "${MISSING_HOST}"},
+		}
+		_, err := resolveRequest(spec, vars)
+		if err == nil {
+			t.Fatal("expected error for undefined variables")
+		}
+		want := "undefined variables: MISSING_H, MISSING_HOST, MISSING_Q"
+		if err.Error() != want {
+			t.Errorf("error = %q, want %q", err.Error(), want)
+		}
+	})
+}
+
 func TestEnvVarPattern(t *testing.T) {
 	// Test the regex pattern matches correctly
 	tests := []struct {
@@ -127,3 +209,138 @@ func TestEnvVarPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandVarsPrefersEnvironment(t *testing.T) {
+	t.Setenv("BASE_URL", "http://os.example")
+	t.Setenv("ONLY_OS", "from-os")
+
+	vars := map[string]string{
+		"BASE_URL": "http://staging.example",
+		"EMPTY":    "",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"${BASE_URL}/users", "http://staging.example/users"},
+		{"${ONLY_OS}", "from-os"},
+		{"[${EMPTY}]", "[]"},
+		{"${BASE_URL}", "http://staging.example"},
+	}
+	for _, tt := range tests {
+		if got, _ := expandVars(tt.input, vars); got != tt.expected {
+			t.Errorf("expandVars(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Without environment variables the OS environment is used
+	if got, _ := expandVars("${BASE_URL}", nil); got != "http://os.example" {
+		t.Errorf("expandVars with nil vars = %q, want %q", got, "http://os.example")
+	}
+}
+
+func TestSaveAndLoadEnvironments(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	store := environmentStore{
+		Active: "staging",
+		Environments: []environment{
+			{Name: "local", Variables: map[string]string{"BASE_URL": "http://localhost:8080"}},
+			{Name: "staging", Variables: map[string]string{"BASE_URL": "https://staging.example"}},
+		},
+	}
+	if err := saveEnvironments(store); err != nil {
+		t.Fatalf("saveEnvironments failed: %v", err)
+	}
+	path := filepath.Join(tmpDir, ".getboy", "environments.json")
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("environments file not created: %v", err)
+	}
+	// Files written by older versions are tightened on the next save
+	if err := saveEnvironments(store); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("environments mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := loadEnvironments()
+	if err != nil {
+		t.Fatalf("loadEnvironments failed: %v", err)
+	}
+	if loaded.Active != "staging" {
+		t.Errorf("active = %q, want %q", loaded.Active, "staging")
+	}
+	if loaded.activeVars()["BASE_URL"] != "https://staging.example" {
+		t.Errorf("active BASE_URL = %q", loaded.activeVars()["BASE_URL"])
+	}
+}
+
+func TestEnvironmentPicker(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.envs = environmentStore{Environments: []environment{
+		{Name: "local", Variables: map[string]string{"BASE_URL": "http://localhost"}},
+		{Name: "prod", Variables: map[string]string{"BASE_URL": "https://prod"}},
+	}}
+
+	// 'e' opens the picker on "No environment"
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	if m.modal != modalEnvironments {
+		t.Fatalf("modal = %v, want modalEnvironments", m.modal)
+	}
+	if !strings.Contains(m.View(), "prod") {
+		t.Error("picker should list environments")
+	}
+
+	// Move down twice and activate "prod"
+	for range 2 {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		m = updated.(model)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if m.modal != modalNone {
+		t.Error("picker should close after enter")
+	}
+	if m.envs.Active != "prod" {
+		t.Errorf("active = %q, want %q", m.envs.Active, "prod")
+	}
+	if !strings.Contains(m.View(), "env: prod") {
+		t.Error("footer should show the active environment")
+	}
+
+	// The choice is persisted
+	loaded, _ := loadEnvironments()
+	if loaded.Active != "prod" {
+		t.Errorf("persisted active = %q, want %q", loaded.Active, "prod")
+	}
+
+	// Picking "No environment" clears it
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.envs.Active != "" {
+		t.Errorf("active = %q, want none", m.envs.Active)
+	}
+	if strings.Contains(m.View(), "env: ") {
+		t.Error("footer should not show an environment when none is active")
+	}
+}
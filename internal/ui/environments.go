@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const environmentsFileName = "environments.json"
+
+// environment is a named set of variables used for ${VAR} expansion
+type environment struct {
+	Name      string            `json:"name"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// environmentStore is the persisted list of environments and which one is active
+type environmentStore struct {
+	Active       string        `json:"active,omitempty"`
+	Environments []environment `json:"environments"`
+}
+
+// activeVars returns the variables of the active environment, or nil if none is active
+func (s environmentStore) activeVars() map[string]string {
+	for _, e := range s.Environments {
+		if e.Name == s.Active {
+			return e.Variables
+		}
+	}
+	return nil
+}
+
+// hasActive reports whether the active environment exists
+func (s environmentStore) hasActive() bool {
+	for _, e := range s.Environments {
+		if e.Name == s.Active {
+			return true
+		}
+	}
+	return false
+}
+
+// loadEnvironments reads environments from disk
+func loadEnvironments() (environmentStore, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return environmentStore{}, err
+	}
+
+	path := filepath.Join(dir, environmentsFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return environmentStore{}, nil // No environments yet
+		}
+		return environmentStore{}, err
+	}
+
+	var store environmentStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return environmentStore{}, err
+	}
+	return store, nil
+}
+
+// saveEnvironments writes environments to disk, readable only by the user
+func saveEnvironments(store environmentStore) error {
+	dir, err := getDataDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, environmentsFileName)
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(path, data)
+}
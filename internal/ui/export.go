@@ -0,0 +1,496 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// snippetFormat is a language or tool a request can be exported to
+type snippetFormat int
+
+const (
+	snippetCurl snippetFormat = iota
+	snippetGo
+	snippetPython
+	snippetFetch
+	snippetHTTPie
+	snippetFormatCount
+)
+
+// String returns the format's tab label
+func (f snippetFormat) String() string {
+	return [...]string{"curl", "Go", "Python", "fetch", "HTTPie"}[f]
+}
+
+// writeClipboard copies text to the system clipboard; replaced in tests
+var writeClipboard = clipboard.WriteAll
+
+// exportRequest returns the editor's request as it would be sent, with
+// variables expanded from the active environment or left as placeholders
+func (m model) exportRequest(expand bool) requestSpec {
+	var vars map[string]string
+	if expand {
+		vars = m.requestVars()
+	}
+	return m.exportSpec(m.currentRequest(), expand, vars)
+}
+
+// exportSpec returns a request as it would be sent, expanding variables
+// from vars if expand is set
+func (m model) exportSpec(spec requestSpec, expand bool, vars map[string]string) requestSpec {
+	spec.Settings = spec.Settings.over(m.settings)
+	if expand {
+		// Undefined variables stay as placeholders
+		spec, _ = resolveRequest(spec, vars)
+	}
+	spec.URL = ensureScheme(spec.URL)
+	if spec.Auth.Type == authOAuth2 {
+		// Fetching a token could open a browser, so only a cached one is used
+		token := "${ACCESS_TOKEN}"
+		if t, ok := cachedOAuthToken(spec.Auth); ok && expand && t.valid() {
+			token = t.AccessToken
+		}
+		spec.Auth = requestAuth{Type: authBearer, Token: token}
+	}
+	if spec.Auth.Type == authBasic {
+		// Each tool encodes the credentials itself, so placeholders stay readable
+		spec.Headers = withoutHeader(spec.Headers, "Authorization")
+	} else {
+		spec = applyAuth(spec)
+	}
+	if spec.BodyType == bodyURLEncoded {
+		// A URL-encoded form is plain text to every tool
+		spec.Body, spec.Form = encodeForm(spec.Form), nil
+	}
+
+	headers := maps.Clone(spec.Headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	switch {
+	case spec.BodyType == bodyMultipart:
+		// Each tool sets its own Content-Type with the boundary
+		headers = withoutHeader(headers, "Content-Type")
+	case spec.BodyType == bodyBinary && spec.BodyFile != "":
+		setDefaultHeader(headers, "Content-Type", contentTypeByExtension(spec.BodyFile))
+	case spec.BodyType == bodyURLEncoded && spec.Body != "":
+		setDefaultHeader(headers, "Content-Type", "application/x-www-form-urlencoded")
+	case spec.Body != "":
+		setDefaultHeader(headers, "Content-Type", rawContentType(spec.BodyType))
+	}
+	spec.Headers = headers
+	return spec
+}
+
+// exportsBody reports whether a snippet sends a body
+func exportsBody(spec requestSpec) bool {
+	return spec.Body != "" || len(spec.Form) > 0 || spec.BodyFile != ""
+}
+
+// hasFormFile reports whether a multipart body uploads a file
+func hasFormFile(spec requestSpec) bool {
+	for _, f := range spec.Form {
+		if f.File {
+			return true
+		}
+	}
+	return false
+}
+
+// basicCredentials returns the user:password pair of Basic auth
+func basicCredentials(spec requestSpec) string {
+	return spec.Auth.Username + ":" + spec.Auth.Password
+}
+
+// renderSnippet renders a request in the given format
+func renderSnippet(f snippetFormat, spec requestSpec) string {
+	switch f {
+	case snippetGo:
+		return goSnippet(spec)
+	case snippetPython:
+		return pythonSnippet(spec)
+	case snippetFetch:
+		return fetchSnippet(spec)
+	case snippetHTTPie:
+		return httpieSnippet(spec)
+	default:
+		return curlSnippet(spec)
+	}
+}
+
+// sortedHeaders returns header names in a stable order
+func sortedHeaders(spec requestSpec) []string {
+	return slices.Sorted(maps.Keys(spec.Headers))
+}
+
+// shellQuote quotes s for a POSIX shell, leaving simple words bare
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@%+=,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsString quotes s as a JavaScript (and JSON) string literal
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// curlSnippet renders a curl command, one option per line
+func curlSnippet(spec requestSpec) string {
+	parts := []string{"curl"}
+	if spec.Method != "GET" || exportsBody(spec) {
+		parts = append(parts, "-X "+spec.Method)
+	}
+	parts = append(parts, shellQuote(spec.URL))
+	for _, k := range sortedHeaders(spec) {
+		parts = append(parts, "-H "+shellQuote(k+": "+spec.Headers[k]))
+	}
+	if spec.Auth.Type == authBasic {
+		parts = append(parts, "-u "+shellQuote(basicCredentials(spec)))
+	}
+	for _, f := range spec.Form {
+		if f.File {
+			parts = append(parts, "-F "+shellQuote(f.Key+"=@"+f.Value))
+		} else {
+			parts = append(parts, "--form-string "+shellQuote(f.Key+"="+f.Value))
+		}
+	}
+	if spec.BodyFile != "" {
+		parts = append(parts, "--data-binary "+shellQuote("@"+spec.BodyFile))
+	}
+	if spec.Body != "" {
+		parts = append(parts, "--data-raw "+shellQuote(spec.Body))
+	}
+	if spec.Settings.followRedirects() {
+		parts = append(parts, "-L", fmt.Sprintf("--max-redirs %d", spec.Settings.maxRedirects()))
+	}
+	if spec.Settings.insecure() {
+		parts = append(parts, "-k")
+	}
+	if t := spec.Settings.timeout(); t > 0 {
+		parts = append(parts, fmt.Sprintf("--max-time %d", int(t.Seconds())))
+	}
+	return strings.Join(parts, " \\\n  ")
+}
+
+// goSnippet renders a runnable Go program using net/http
+func goSnippet(spec requestSpec) string {
+	var b strings.Builder
+	imports := []string{"fmt", "io", "log", "net/http"}
+	if spec.Body != "" {
+		imports = append(imports, "strings")
+	}
+	if spec.BodyFile != "" || hasFormFile(spec) {
+		imports = append(imports, "os")
+	}
+	if len(spec.Form) > 0 {
+		imports = append(imports, "bytes", "mime/multipart")
+	}
+	if spec.Settings.timeout() > 0 {
+		imports = append(imports, "time")
+	}
+	if spec.Settings.insecure() {
+		imports = append(imports, "crypto/tls")
+	}
+	slices.Sort(imports)
+
+	b.WriteString("package main\n\nimport (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	switch {
+	case spec.Body != "":
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", strconv.Quote(spec.Body))
+		body = "body"
+	case spec.BodyFile != "":
+		fmt.Fprintf(&b, "\tbody, err := os.Open(%q)\n", spec.BodyFile)
+		b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+		b.WriteString("\tdefer body.Close()\n\n")
+		body = "body"
+	case len(spec.Form) > 0:
+		goMultipart(&b, spec.Form)
+		body = "&body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%q, %q, %s)\n", spec.Method, spec.URL, body)
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	for _, k := range sortedHeaders(spec) {
+		fmt.Fprintf(&b, "\treq.Header.Set(%q, %q)\n", k, spec.Headers[k])
+	}
+	if len(spec.Form) > 0 {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", form.FormDataContentType())\n")
+	}
+	if spec.Auth.Type == authBasic {
+		fmt.Fprintf(&b, "\treq.SetBasicAuth(%q, %q)\n", spec.Auth.Username, spec.Auth.Password)
+	}
+
+	b.WriteString("\n\tclient := &http.Client{")
+	var fields []string
+	if t := spec.Settings.timeout(); t > 0 {
+		fields = append(fields, fmt.Sprintf("\t\tTimeout: %d * time.Second,", int(t.Seconds())))
+	}
+	if spec.Settings.insecure() {
+		fields = append(fields, "\t\tTransport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},")
+	}
+	if !spec.Settings.followRedirects() {
+		fields = append(fields, "\t\tCheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },")
+	}
+	if len(fields) > 0 {
+		b.WriteString("\n" + strings.Join(fields, "\n") + "\n\t")
+	}
+	b.WriteString("}\n")
+
+	b.WriteString("\tresp, err := client.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tout, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n")
+	b.WriteString("\tfmt.Println(string(out))\n")
+	b.WriteString("}")
+	return b.String()
+}
+
+// goMultipart writes the statements building a multipart body named body
+func goMultipart(b *strings.Builder, fields []formField) {
+	b.WriteString("\tvar body bytes.Buffer\n")
+	b.WriteString("\tform := multipart.NewWriter(&body)\n")
+	for _, f := range fields {
+		if !f.File {
+			fmt.Fprintf(b, "\tif err := form.WriteField(%q, %q); err != nil {\n\t\tlog.Fatal(err)\n\t}\n", f.Key, f.Value)
+			continue
+		}
+		fmt.Fprintf(b, "\t{\n\t\tfile, err := os.Open(%q)\n", f.Value)
+		b.WriteString("\t\tif err != nil {\n\t\t\tlog.Fatal(err)\n\t\t}\n")
+		fmt.Fprintf(b, "\t\tpart, err := form.CreateFormFile(%q, %q)\n", f.Key, filepath.Base(f.Value))
+		b.WriteString("\t\tif err != nil {\n\t\t\tlog.Fatal(err)\n\t\t}\n")
+		b.WriteString("\t\tif _, err := io.Copy(part, file); err != nil {\n\t\t\tlog.Fatal(err)\n\t\t}\n")
+		b.WriteString("\t\t_ = file.Close()\n\t}\n")
+	}
+	b.WriteString("\tif err := form.Close(); err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+}
+
+// pythonSnippet renders a script using the requests library
+func pythonSnippet(spec requestSpec) string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", strconv.Quote(spec.URL))
+
+	args := []string{strconv.Quote(spec.Method), "url"}
+	if len(spec.Headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, k := range sortedHeaders(spec) {
+			fmt.Fprintf(&b, "    %s: %s,\n", strconv.Quote(k), strconv.Quote(spec.Headers[k]))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	switch {
+	case spec.Body != "":
+		fmt.Fprintf(&b, "data = %s\n", strconv.Quote(spec.Body))
+		args = append(args, "data=data")
+	case spec.BodyFile != "":
+		fmt.Fprintf(&b, "data = open(%s, \"rb\")\n", strconv.Quote(spec.BodyFile))
+		args = append(args, "data=data")
+	case len(spec.Form) > 0:
+		// (None, value) sends a text field; files makes it multipart either way
+		b.WriteString("files = [\n")
+		for _, f := range spec.Form {
+			value := fmt.Sprintf("(None, %s)", strconv.Quote(f.Value))
+			if f.File {
+				value = fmt.Sprintf("open(%s, \"rb\")", strconv.Quote(f.Value))
+			}
+			fmt.Fprintf(&b, "    (%s, %s),\n", strconv.Quote(f.Key), value)
+		}
+		b.WriteString("]\n")
+		args = append(args, "files=files")
+	}
+	if spec.Auth.Type == authBasic {
+		args = append(args, fmt.Sprintf("auth=(%s, %s)", strconv.Quote(spec.Auth.Username), strconv.Quote(spec.Auth.Password)))
+	}
+	if t := spec.Settings.timeout(); t > 0 {
+		args = append(args, fmt.Sprintf("timeout=%d", int(t.Seconds())))
+	}
+	if !spec.Settings.followRedirects() {
+		args = append(args, "allow_redirects=False")
+	}
+	if spec.Settings.insecure() {
+		args = append(args, "verify=False")
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s)\n", strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\n")
+	b.WriteString("print(response.text)")
+	return b.String()
+}
+
+// fetchSnippet renders JavaScript using fetch
+func fetchSnippet(spec requestSpec) string {
+	var b strings.Builder
+	if spec.BodyFile != "" || hasFormFile(spec) {
+		b.WriteString("import fs from \"node:fs\";\n\n")
+	}
+	if len(spec.Form) > 0 {
+		b.WriteString("const form = new FormData();\n")
+		for _, f := range spec.Form {
+			if f.File {
+				fmt.Fprintf(&b, "form.append(%s, await fs.openAsBlob(%s), %s);\n", jsString(f.Key), jsString(f.Value), jsString(filepath.Base(f.Value)))
+			} else {
+				fmt.Fprintf(&b, "form.append(%s, %s);\n", jsString(f.Key), jsString(f.Value))
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsString(spec.URL))
+	fmt.Fprintf(&b, "  method: %s,\n", jsString(spec.Method))
+	if len(spec.Headers) > 0 || spec.Auth.Type == authBasic {
+		b.WriteString("  headers: {\n")
+		for _, k := range sortedHeaders(spec) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsString(k), jsString(spec.Headers[k]))
+		}
+		if spec.Auth.Type == authBasic {
+			fmt.Fprintf(&b, "    \"Authorization\": \"Basic \" + btoa(%s),\n", jsString(basicCredentials(spec)))
+		}
+		b.WriteString("  },\n")
+	}
+	switch {
+	case spec.Body != "":
+		fmt.Fprintf(&b, "  body: %s,\n", jsString(spec.Body))
+	case spec.BodyFile != "":
+		fmt.Fprintf(&b, "  body: await fs.openAsBlob(%s),\n", jsString(spec.BodyFile))
+	case len(spec.Form) > 0:
+		b.WriteString("  body: form,\n")
+	}
+	if !spec.Settings.followRedirects() {
+		b.WriteString("  redirect: \"manual\",\n")
+	}
+	if t := spec.Settings.timeout(); t > 0 {
+		fmt.Fprintf(&b, "  signal: AbortSignal.timeout(%d),\n", t.Milliseconds())
+	}
+	b.WriteString("});\n")
+	b.WriteString("console.log(response.status);\n")
+	b.WriteString("console.log(await response.text());")
+	return b.String()
+}
+
+// httpieSnippet renders an HTTPie command
+func httpieSnippet(spec requestSpec) string {
+	parts := []string{"http"}
+	if spec.Settings.followRedirects() {
+		parts = append(parts, "--follow", fmt.Sprintf("--max-redirects=%d", spec.Settings.maxRedirects()))
+	}
+	if spec.Settings.insecure() {
+		parts = append(parts, "--verify=no")
+	}
+	if t := spec.Settings.timeout(); t > 0 {
+		parts = append(parts, fmt.Sprintf("--timeout=%d", int(t.Seconds())))
+	}
+	if spec.Auth.Type == authBasic {
+		parts = append(parts, "-a "+shellQuote(basicCredentials(spec)))
+	}
+	if spec.Body != "" {
+		parts = append(parts, "--raw "+shellQuote(spec.Body))
+	}
+	if len(spec.Form) > 0 {
+		parts = append(parts, "--multipart")
+	}
+	parts = append(parts, spec.Method, shellQuote(spec.URL))
+	for _, k := range sortedHeaders(spec) {
+		parts = append(parts, shellQuote(k+":"+spec.Headers[k]))
+	}
+	for _, f := range spec.Form {
+		if f.File {
+			parts = append(parts, shellQuote(f.Key+"@"+f.Value))
+		} else {
+			parts = append(parts, shellQuote(f.Key+"="+f.Value))
+		}
+	}
+	if spec.BodyFile != "" {
+		parts = append(parts, "< "+shellQuote(spec.BodyFile))
+	}
+	return strings.Join(parts, " \\\n  ")
+}
+
+// openExport shows the export dialog for the editor's request
+func (m *model) openExport() {
+	m.openModal(modalExport)
+	m.exportFormat = snippetCurl
+}
+
+// updateExport handles keys in the export dialog
+func (m *model) updateExport(key string) {
+	switch key {
+	case "esc", "q":
+		m.modal = modalNone
+	case "tab", "right", "l":
+		m.exportFormat = (m.exportFormat + 1) % snippetFormatCount
+	case "shift+tab", "left", "h":
+		m.exportFormat = (m.exportFormat + snippetFormatCount - 1) % snippetFormatCount
+	case "v":
+		m.exportExpand = !m.exportExpand
+	case "y", "c":
+		snippet := renderSnippet(m.exportFormat, m.exportRequest(m.exportExpand))
+		if err := writeClipboard(snippet); err != nil {
+			m.err = fmt.Errorf("copy to clipboard: %w", err)
+			return
+		}
+		m.err = nil
+		m.status = fmt.Sprintf("Copied %s snippet", m.exportFormat)
+		m.modal = modalNone
+	}
+}
+
+// viewExport renders the format tabs and the snippet
+func (m model) viewExport(width int) string {
+	faintStyle := lipgloss.NewStyle().Faint(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+
+	var tabs []string
+	for f := range snippetFormatCount {
+		label := " " + f.String() + " "
+		if f == m.exportFormat {
+			label = selectedStyle.Render(label)
+		}
+		tabs = append(tabs, label)
+	}
+
+	vars := "variables: placeholders"
+	if m.exportExpand {
+		vars = "variables: expanded"
+	}
+
+	// Keep long snippets inside the dialog; copying still takes the whole text
+	lines := strings.Split(renderSnippet(m.exportFormat, m.exportRequest(m.exportExpand)), "\n")
+	maxLines := max(m.contentHeight()-8, 3)
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], faintStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-maxLines+1)))
+	}
+	for i, l := range lines {
+		if lipgloss.Width(l) > width-4 {
+			lines[i] = truncateURL(l, max(width-4, 4))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		strings.Join(tabs, " "),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		faintStyle.Render("tab: format  v: "+vars+"  y: copy  esc: close"),
+	)
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportTestSpec is a request exercising quoting in every format
+func exportTestSpec() requestSpec {
+	return requestSpec{
+		Method: "POST",
+		URL:    "https://api.example.com/items?q=it's",
+		Body:   `{"name":"O'Brien","note":"line1\nline2"}`,
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": "Bearer ${TOKEN}",
+		},
+	}
+}
+
+// TestCurlSnippetRoundTrip tests that the exported curl command imports back unchanged
+func TestCurlSnippetRoundTrip(t *testing.T) {
+	spec := exportTestSpec()
+	spec.Settings = requestSettings{Insecure: boolPtr(true), TimeoutSec: intPtr(30)}
+
+	got, err := parseCurl(curlSnippet(spec))
+	if err != nil {
+		t.Fatalf("exported curl does not parse: %v\n%s", err, curlSnippet(spec))
+	}
+	if got.Method != spec.Method || got.URL != spec.URL || got.Body != spec.Body {
+		t.Errorf("round trip = %s %s %q, want %s %s %q", got.Method, got.URL, got.Body, spec.Method, spec.URL, spec.Body)
+	}
+	for k, v := range spec.Headers {
+		if got.Headers[k] != v {
+			t.Errorf("header %s = %q, want %q", k, got.Headers[k], v)
+		}
+	}
+	if !got.Settings.insecure() || got.Settings.timeout().Seconds() != 30 || !got.Settings.followRedirects() {
+		t.Errorf("settings did not round trip: %+v", got.Settings)
+	}
+}
+
+// TestGoSnippetParses tests that the Go snippet is a valid program
+func TestGoSnippetParses(t *testing.T) {
+	for _, s := range []requestSettings{{}, {FollowRedirects: boolPtr(false), Insecure: boolPtr(true), TimeoutSec: intPtr(0)}} {
+		spec := exportTestSpec()
+		spec.Settings = s
+		src := goSnippet(spec)
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+			t.Errorf("Go snippet does not parse: %v\n%s", err, src)
+		}
+	}
+}
+
+// TestSnippetContents tests the key parts of each format
+func TestSnippetContents(t *testing.T) {
+	spec := exportTestSpec()
+	spec.Settings = requestSettings{FollowRedirects: boolPtr(false)}
+
+	tests := []struct {
+		format snippetFormat
+		want   []string
+	}{
+		{snippetPython, []string{
+			"import requests",
+			`"Authorization": "Bearer ${TOKEN}",`,
+			`data = "{\"name\":\"O'Brien\",\"note\":\"line1\\nline2\"}"`,
+			`requests.request("POST", url, headers=headers, data=data, timeout=12, allow_redirects=False)`,
+		}},
+		{snippetFetch, []string{
+			`await fetch("https://api.example.com/items?q=it's", {`,
+			`method: "POST",`,
+			`body: "{\"name\":\"O'Brien\",\"note\":\"line1\\nline2\"}",`,
+			`redirect: "manual",`,
+			`signal: AbortSignal.timeout(12000),`,
+		}},
+		{snippetHTTPie, []string{
+			"--timeout=12",
+			`--raw '{"name":"O'\''Brien","note":"line1\nline2"}'`,
+			`'https://api.example.com/items?q=it'\''s'`,
+			`'Authorization:Bearer ${TOKEN}'`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			got := renderSnippet(tt.format, spec)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("snippet missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestExportRequestVariables tests exporting with expanded or placeholder variables
+func TestExportRequestVariables(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.envs = environmentStore{
+		Active:       "dev",
+		Environments: []environment{{Name: "dev", Variables: map[string]string{"HOST": "dev.example.com"}}},
+	}
+	m.url.SetValue("${HOST}/users/${ID}")
+	m.body.SetValue(`{"a":1}`)
+
+	placeholder := m.exportRequest(false)
+	if placeholder.URL != "${HOST}/users/${ID}" {
+		t.Errorf("placeholder URL = %q", placeholder.URL)
+	}
+	if placeholder.Headers["Content-Type"] != "application/json" {
+		t.Error("the default Content-Type getboy sends should be exported")
+	}
+
+	expanded := m.exportRequest(true)
+	if expanded.URL != "https://dev.example.com/users/${ID}" {
+		t.Errorf("expanded URL = %q, want HOST expanded and ID kept", expanded.URL)
+	}
+}
+
+// TestExportDialog tests switching formats and copying to the clipboard
+func TestExportDialog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var copied string
+	orig := writeClipboard
+	writeClipboard = func(s string) error { copied = s; return nil }
+	defer func() { writeClipboard = orig }()
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneEditor
+	m.url.SetValue("https://a.com/x")
+
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "tab" {
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+
+	press("x")
+	if m.modal != modalExport {
+		t.Fatal("x should open the export dialog")
+	}
+	if view := m.View(); !strings.Contains(view, "curl") || !strings.Contains(view, "https://a.com/x") {
+		t.Errorf("dialog should show the curl command, got:\n%s", view)
+	}
+
+	press("tab")
+	press("tab")
+	if m.exportFormat != snippetPython {
+		t.Errorf("format = %v, want Python after two tabs", m.exportFormat)
+	}
+
+	press("y")
+	if !strings.HasPrefix(copied, "import requests") {
+		t.Errorf("clipboard = %q, want the Python snippet", copied)
+	}
+	if m.modal != modalNone || m.status != "Copied Python snippet" {
+		t.Errorf("after copy modal = %v, status = %q", m.modal, m.status)
+	}
+
+	// A clipboard failure is reported and keeps the dialog open
+	writeClipboard = func(string) error { return errors.New("no clipboard") }
+	press("x")
+	press("y")
+	if m.err == nil || m.modal != modalExport {
+		t.Error("clipboard errors should be shown with the dialog still open")
+	}
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startResponseFilter opens the filter input with the current filter
+func (m *model) startResponseFilter() {
+	m.respFiltering = true
+	m.respFilter.SetValue(m.respFilterExpr)
+	m.respFilter.CursorEnd()
+	m.respFilter.Focus()
+}
+
+// updateResponseFilter handles keys while editing the filter. Enter applies
+// it, an empty filter showing the whole response again.
+func (m *model) updateResponseFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.respFiltering = false
+		m.respFilter.Blur()
+		m.respFilterExpr = strings.TrimSpace(m.respFilter.Value())
+		if m.respTab != respBody {
+			m.respTab = respBody
+		}
+		m.refreshResponseView()
+		return nil
+	case "esc":
+		m.respFiltering = false
+		m.respFilter.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.respFilter, cmd = m.respFilter.Update(msg)
+	return cmd
+}
+
+// renderFilteredBody renders the result of the response filter
+func renderFilteredBody(body, filter string) string {
+	out, err := runJQ(filter, body)
+	if err != nil {
+		return fmt.Sprintf("Filter error: %v", err)
+	}
+	if out == "" {
+		return "Filter matched nothing"
+	}
+	return highlight(out, "json")
+}
+
+// newFilterInput creates the response pane's filter input
+func newFilterInput() textinput.Model {
+	in := textinput.New()
+	in.Prompt = "Filter: "
+	in.Placeholder = ".items[].id"
+	in.CharLimit = 1024
+	return in
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestResponseFilter tests filtering the response body and clearing the filter
+func TestResponseFilter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	press := func(msg tea.Msg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	typeText := func(s string) {
+		for _, r := range s {
+			press(key(string(r)))
+		}
+	}
+	plain := func() string { return ansiPattern.ReplaceAllString(m.view.View(), "") }
+
+	body := `{"items":[{"id":"first-id"},{"id":"second-id"}],"secret":"hidden"}`
+	press(key("3"))
+	press(httpDoneMsg{Status: "200 OK", StatusCode: 200, Body: body, Headers: http.Header{"Content-Type": {"application/json"}}})
+
+	press(key("f"))
+	typeText(".items[].id")
+	if !m.respFiltering {
+		t.Fatal("f should open the filter input")
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := plain(); !strings.Contains(got, `"first-id"`) || !strings.Contains(got, `"second-id"`) || strings.Contains(got, "hidden") {
+		t.Errorf("filtered body =\n%s", got)
+	}
+	if m.resp.Body != body {
+		t.Error("filtering must keep the original response")
+	}
+	if !strings.Contains(m.View(), "filter: .items[].id") {
+		t.Errorf("status should show the active filter:\n%s", m.View())
+	}
+
+	// Errors are shown in place of the body
+	press(key("f"))
+	typeText(" |")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := plain(); !strings.Contains(got, "Filter error") {
+		t.Errorf("bad filter should show an error:\n%s", got)
+	}
+
+	// An empty filter shows the whole body again
+	press(key("f"))
+	for range len(m.respFilter.Value()) {
+		press(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := plain(); !strings.Contains(got, "hidden") {
+		t.Errorf("clearing the filter should show the whole body:\n%s", got)
+	}
+}
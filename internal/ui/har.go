@@ -0,0 +1,508 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// harVersion is the version of the HAR format written on export
+const harVersion = "1.2"
+
+// harSkippedHeaders are request headers in captured traffic that the HTTP
+// client sets itself; sending them as recorded breaks the request
+var harSkippedHeaders = []string{"Host", "Content-Length", "Connection", "Accept-Encoding"}
+
+// harFile is an HTTP Archive, as exported by browser devtools
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text,omitempty"`
+	Params   []harParam `json:"params,omitempty"`
+}
+
+type harParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// harTimings are the phases of a request in milliseconds, -1 when they
+// don't apply
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"` // includes SSL
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// isHAR reports whether a decoded document is an HTTP Archive
+func isHAR(root map[string]any) bool {
+	log := jsonObject(root["log"])
+	_, ok := log["entries"].([]any)
+	return ok
+}
+
+// readHAR reads an HTTP Archive from a file
+func readHAR(path string) (harFile, error) {
+	var har harFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return har, err
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		return har, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if len(har.Log.Entries) == 0 {
+		return har, fmt.Errorf("%s has no requests", filepath.Base(path))
+	}
+	return har, nil
+}
+
+// importHAR converts the requests in an HTTP Archive into a collection
+func importHAR(path, name string) (*collection, error) {
+	har, err := readHAR(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &collection{folder: folder{Name: importName(name)}}
+	for _, e := range har.Log.Entries {
+		spec := e.Request.spec()
+		c.Requests = append(c.Requests, savedRequest{
+			Name: uniqueName(requestName(spec.Method, spec.URL), func(n string) bool {
+				return c.requestIndex(n) >= 0
+			}),
+			requestSpec: spec,
+		})
+	}
+	return c, nil
+}
+
+// historyEntries converts the exchanges in an HTTP Archive into history
+// entries, oldest first
+func (h harFile) historyEntries() []historyEntry {
+	var entries []historyEntry
+	for _, e := range h.Log.Entries {
+		entry := newHistoryEntry(e.Request.spec())
+		entry.DurationMs = int64(e.Time)
+		if e.Response.Status > 0 {
+			entry.Response = e.historyResponse()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// spec converts a recorded request into a request
+func (r harRequest) spec() requestSpec {
+	spec := requestSpec{Method: strings.ToUpper(r.Method), URL: r.URL}
+	for _, h := range r.Headers {
+		// HTTP/2 pseudo-headers such as :authority aren't real headers
+		if strings.HasPrefix(h.Name, ":") || slices.ContainsFunc(harSkippedHeaders, func(s string) bool { return strings.EqualFold(s, h.Name) }) {
+			continue
+		}
+		if v := lookupHeader(spec.Headers, h.Name); v != "" {
+			sep := ", "
+			if strings.EqualFold(h.Name, "Cookie") {
+				sep = "; "
+			}
+			spec.Headers = withoutHeader(spec.Headers, h.Name)
+			h.Value = v + sep + h.Value
+		}
+		setHeader(&spec, h.Name, h.Value)
+	}
+
+	p := r.PostData
+	if p == nil || (p.Text == "" && len(p.Params) == 0) {
+		return spec
+	}
+	mediaType, _, _ := mime.ParseMediaType(p.MimeType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		spec.BodyType = bodyURLEncoded
+		for _, q := range parseQuery(p.Text) {
+			spec.Form = append(spec.Form, formField{Key: q.Key, Value: q.Value})
+		}
+	case mediaType == "multipart/form-data" && len(p.Params) > 0:
+		// Uploaded files aren't recorded, so file fields keep only the name
+		spec.BodyType = bodyMultipart
+		for _, param := range p.Params {
+			if param.FileName != "" {
+				spec.Form = append(spec.Form, formField{Key: param.Name, Value: param.FileName, File: true})
+			} else {
+				spec.Form = append(spec.Form, formField{Key: param.Name, Value: param.Value})
+			}
+		}
+		spec.Headers = withoutHeader(spec.Headers, "Content-Type")
+	default:
+		spec.Body = p.Text
+		spec.BodyType = postmanRawType("", spec)
+	}
+	if len(spec.Headers) == 0 {
+		spec.Headers = nil
+	}
+	return spec
+}
+
+// historyResponse converts a recorded response into a history response
+func (e harEntry) historyResponse() *historyResponse {
+	r := e.Response
+	headers := http.Header{}
+	for _, h := range r.Headers {
+		headers.Add(h.Name, h.Value)
+	}
+	status := cmp.Or(r.StatusText, http.StatusText(r.Status))
+	resp := &historyResponse{
+		SentAt:     e.StartedDateTime,
+		Status:     strings.TrimSpace(fmt.Sprintf("%d %s", r.Status, status)),
+		StatusCode: r.Status,
+		Proto:      strings.ToUpper(r.HTTPVersion),
+		Headers:    headers,
+		Size:       r.Content.Size,
+		Timing:     e.Timings.timing(e.Time),
+	}
+	body := r.Content.Text
+	if r.Content.Encoding == "base64" {
+		b, _ := base64.StdEncoding.DecodeString(body)
+		body = string(b)
+	}
+	resp.setBody(body)
+	return resp
+}
+
+// timing lays the phases of a recorded request out one after another
+func (t harTimings) timing(total float64) requestTiming {
+	ms := func(v float64) time.Duration {
+		return time.Duration(max(v, 0) * float64(time.Millisecond))
+	}
+	var timing requestTiming
+	at := ms(t.Blocked)
+	next := func(d time.Duration) span {
+		s := span{Start: at, Duration: d}
+		at += d
+		return s
+	}
+	timing.DNS = next(ms(t.DNS))
+	timing.Connect = next(ms(t.Connect) - ms(t.SSL))
+	timing.TLS = next(ms(t.SSL))
+	at += ms(t.Send)
+	timing.Wait = next(ms(t.Wait))
+	timing.Transfer = next(ms(t.Receive))
+	timing.Total = max(ms(total), at)
+	timing.Reused = t.Connect < 0
+	return timing
+}
+
+// harTimingsOf converts the timing of a request into HAR timings
+func harTimingsOf(t requestTiming) harTimings {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	optional := func(d time.Duration) float64 {
+		if d == 0 {
+			return -1
+		}
+		return ms(d)
+	}
+	timings := harTimings{
+		Blocked: -1,
+		DNS:     optional(t.DNS.Duration),
+		Connect: optional(t.Connect.Duration + t.TLS.Duration),
+		SSL:     optional(t.TLS.Duration),
+		Wait:    ms(t.Wait.Duration),
+		Receive: ms(t.Transfer.Duration),
+	}
+	if t.Reused {
+		timings.DNS, timings.Connect, timings.SSL = -1, -1, -1
+	}
+	return timings
+}
+
+// harEntryOf converts a history entry into a HAR entry. The request is
+// given as it was sent; the response is empty if none was recorded.
+func harEntryOf(spec requestSpec, e historyEntry) harEntry {
+	entry := harEntry{
+		Time: float64(e.DurationMs),
+		Request: harRequest{
+			Method:      spec.Method,
+			URL:         spec.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(spec.Headers),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+	}
+	_, rawQuery, _ := splitURL(spec.URL)
+	for _, q := range parseQuery(rawQuery) {
+		entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{q.Key, q.Value})
+	}
+
+	contentType := lookupHeader(spec.Headers, "Content-Type")
+	switch {
+	case spec.BodyType == bodyMultipart:
+		post := &harPostData{MimeType: "multipart/form-data"}
+		for _, f := range spec.Form {
+			if f.File {
+				post.Params = append(post.Params, harParam{Name: f.Key, FileName: filepath.Base(f.Value), ContentType: contentTypeByExtension(f.Value)})
+			} else {
+				post.Params = append(post.Params, harParam{Name: f.Key, Value: f.Value})
+			}
+		}
+		entry.Request.PostData = post
+	case spec.BodyType == bodyBinary && spec.BodyFile != "":
+		// HAR has no way to encode binary request bodies, so only the type is kept
+		entry.Request.PostData = &harPostData{MimeType: contentType}
+	case spec.Body != "":
+		entry.Request.PostData = &harPostData{MimeType: contentType, Text: spec.Body}
+		entry.Request.BodySize = len(spec.Body)
+	}
+
+	r := e.Response
+	if r == nil {
+		return entry
+	}
+	entry.StartedDateTime = r.SentAt
+	entry.Request.HTTPVersion = cmp.Or(r.Proto, entry.Request.HTTPVersion)
+	entry.Timings = harTimingsOf(r.Timing)
+	entry.Response.Status = r.StatusCode
+	entry.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(r.Status, fmt.Sprint(r.StatusCode)))
+	entry.Response.HTTPVersion = r.Proto
+	entry.Response.RedirectURL = r.Headers.Get("Location")
+	for _, k := range slices.Sorted(maps.Keys(r.Headers)) {
+		for _, v := range r.Headers[k] {
+			entry.Response.Headers = append(entry.Response.Headers, harNameValue{k, v})
+		}
+	}
+	entry.Response.Content = harContent{Size: r.Size, MimeType: r.Headers.Get("Content-Type")}
+	body, err := r.body()
+	switch {
+	case err != nil:
+		entry.Response.Content.Comment = "body no longer stored"
+	case !utf8.ValidString(body):
+		entry.Response.Content.Text = base64.StdEncoding.EncodeToString([]byte(body))
+		entry.Response.Content.Encoding = "base64"
+	default:
+		entry.Response.Content.Text = body
+	}
+	if r.Truncated && err == nil {
+		entry.Response.Content.Comment = "body truncated"
+	}
+	entry.Response.BodySize = r.Size
+	return entry
+}
+
+// harHeaders converts headers into HAR name/value pairs, sorted by name
+func harHeaders(headers map[string]string) []harNameValue {
+	pairs := []harNameValue{}
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		pairs = append(pairs, harNameValue{k, headers[k]})
+	}
+	return pairs
+}
+
+// toggleHistoryMark marks or unmarks the selected history entry for export
+func (m *model) toggleHistoryMark() {
+	it, ok := m.sidebar.SelectedItem().(reqItem)
+	if !ok {
+		return
+	}
+	if m.historyMarked == nil {
+		m.historyMarked = map[string]bool{}
+	}
+	if m.historyMarked[it.hash] {
+		delete(m.historyMarked, it.hash)
+	} else {
+		m.historyMarked[it.hash] = true
+	}
+	m.updateSidebarItems()
+}
+
+// markedHistory returns the history entries marked for export, or the
+// selected one if none are marked
+func (m model) markedHistory() []historyEntry {
+	var entries []historyEntry
+	for _, e := range m.history {
+		if m.historyMarked[e.hash()] {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > 0 {
+		return entries
+	}
+	if it, ok := m.sidebar.SelectedItem().(reqItem); ok {
+		if i := findHistory(m.history, it.hash); i >= 0 {
+			entries = append(entries, m.history[i])
+		}
+	}
+	return entries
+}
+
+// openExportHistory asks where to write the marked history entries
+func (m *model) openExportHistory() {
+	n := len(m.markedHistory())
+	if n == 0 {
+		return
+	}
+	label := "Export request to HAR file (variables as placeholders):"
+	if n > 1 {
+		label = fmt.Sprintf("Export %d requests to HAR file (variables as placeholders):", n)
+	}
+	m.openPrompt(promptExportHistory, label, "getboy.har")
+}
+
+// exportHistory writes the marked history entries and their responses to
+// a HAR file. Variables and OAuth tokens are left as placeholders, as HAR
+// files get shared. A directory target gets the default file name.
+func (m *model) exportHistory(target string) {
+	entries := m.markedHistory()
+	if len(entries) == 0 || target == "" {
+		return
+	}
+	target = expandHome(target)
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		target = filepath.Join(target, "getboy.har")
+	}
+
+	har := harFile{Log: harLog{Version: harVersion, Creator: harCreator{Name: "getboy"}, Entries: []harEntry{}}}
+	for _, e := range slices.Backward(entries) {
+		// HAR lists requests oldest first, with headers as sent
+		spec := m.exportSpec(e.spec(), false, nil)
+		if spec.Auth.Type == authBasic {
+			spec = applyAuth(spec)
+		}
+		har.Log.Entries = append(har.Log.Entries, harEntryOf(spec, e))
+	}
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err == nil {
+		err = os.WriteFile(target, data, 0644)
+	}
+	if err != nil {
+		m.err = fmt.Errorf("export: %w", err)
+		return
+	}
+	m.historyMarked = nil
+	m.updateSidebarItems()
+	m.status = "Exported request to " + target
+	if len(entries) > 1 {
+		m.status = fmt.Sprintf("Exported %d requests to %s", len(entries), target)
+	}
+}
+
+// importHistory adds the requests and responses in a HAR file to history,
+// reporting when history can't hold them all
+func (m *model) importHistory(path string) {
+	path = expandHome(path)
+	har, err := readHAR(path)
+	if err != nil {
+		m.err = fmt.Errorf("import: %w", err)
+		return
+	}
+	entries := har.historyEntries()
+	imported := map[string]bool{}
+	for _, e := range entries {
+		m.history = addToHistory(m.history, e)
+		imported[e.hash()] = true
+	}
+	if err := saveHistory(m.history); err != nil {
+		m.err = err
+		return
+	}
+	m.updateSidebarItems()
+
+	kept := 0
+	for _, e := range m.history {
+		if imported[e.hash()] {
+			kept++
+		}
+	}
+	m.status = "Imported request from " + filepath.Base(path)
+	if kept != 1 {
+		m.status = fmt.Sprintf("Imported %d requests from %s", kept, filepath.Base(path))
+	}
+	if kept < len(imported) {
+		m.err = fmt.Errorf("import: history holds %d requests, so only the last %d of %d were kept; import the file on the Saved tab to keep them all", maxHistoryItems, kept, len(imported))
+	}
+}
@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const harTestFile = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "startedDateTime": "2024-05-01T10:00:00.000Z",
+        "time": 120.5,
+        "request": {
+          "method": "GET",
+          "url": "https://shop.example.com/api/orders?page=2",
+          "httpVersion": "http/2.0",
+          "headers": [
+            {"name": ":authority", "value": "shop.example.com"},
+            {"name": "accept", "value": "application/json"},
+            {"name": "accept-encoding", "value": "gzip, br"},
+            {"name": "cookie", "value": "a=1"},
+            {"name": "cookie", "value": "b=2"}
+          ],
+          "queryString": [{"name": "page", "value": "2"}],
+          "cookies": [],
+          "headersSize": -1,
+          "bodySize": 0
+        },
+        "response": {
+          "status": 200,
+          "statusText": "",
+          "httpVersion": "http/2.0",
+          "headers": [{"name": "content-type", "value": "application/json"}],
+          "cookies": [],
+          "content": {"size": 11, "mimeType": "application/json", "text": "{\"ok\":true}"},
+          "redirectURL": "",
+          "headersSize": -1,
+          "bodySize": 11
+        },
+        "cache": {},
+        "timings": {"blocked": 2, "dns": 10, "connect": 30, "ssl": 20, "send": 0.5, "wait": 70, "receive": 8}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:01.000Z",
+        "time": 40,
+        "request": {
+          "method": "POST",
+          "url": "https://shop.example.com/api/login",
+          "httpVersion": "HTTP/1.1",
+          "headers": [{"name": "Content-Type", "value": "application/x-www-form-urlencoded"}],
+          "postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=ada&pass=s3cret"}
+        },
+        "response": {
+          "status": 204,
+          "statusText": "No Content",
+          "headers": [],
+          "content": {"size": 0, "mimeType": ""}
+        },
+        "timings": {"send": 1, "wait": 30, "receive": 9}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:02.000Z",
+        "time": 15,
+        "request": {
+          "method": "PUT",
+          "url": "https://shop.example.com/api/images",
+          "headers": [{"name": "Content-Type", "value": "multipart/form-data; boundary=x"}],
+          "postData": {"mimeType": "multipart/form-data; boundary=x", "params": [
+            {"name": "title", "value": "Cat"},
+            {"name": "image", "fileName": "cat.png", "contentType": "image/png"}
+          ]}
+        },
+        "response": {
+          "status": 201,
+          "statusText": "Created",
+          "headers": [{"name": "Content-Type", "value": "image/png"}],
+          "content": {"size": 4, "mimeType": "image/png", "text": "iVBORw==", "encoding": "base64"}
+        },
+        "timings": {"send": 1, "wait": 10, "receive": 4}
+      }
+    ]
+  }
+}`
+
+// TestImportHAR tests converting captured traffic into a collection and
+// into history
+func TestImportHAR(t *testing.T) {
+	path := writeSpec(t, "shop.har", harTestFile)
+	c, _, err := importFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "shop" || len(c.Requests) != 3 {
+		t.Fatalf("collection %q has %d requests", c.Name, len(c.Requests))
+	}
+
+	orders := importedRequest(t, c, "GET api orders")
+	if want := map[string]string{"accept": "application/json", "cookie": "a=1; b=2"}; !reflect.DeepEqual(orders.Headers, want) {
+		t.Errorf("headers = %v, want %v", orders.Headers, want)
+	}
+	login := importedRequest(t, c, "POST api login")
+	if want := []formField{{Key: "user", Value: "ada"}, {Key: "pass", Value: "s3cret"}}; login.BodyType != bodyURLEncoded || !reflect.DeepEqual(login.Form, want) {
+		t.Errorf("login body %q %+v", login.BodyType, login.Form)
+	}
+	upload := importedRequest(t, c, "PUT api images")
+	if want := []formField{{Key: "title", Value: "Cat"}, {Key: "image", Value: "cat.png", File: true}}; upload.BodyType != bodyMultipart || !reflect.DeepEqual(upload.Form, want) || upload.Headers != nil {
+		t.Errorf("upload body %q %+v headers %v", upload.BodyType, upload.Form, upload.Headers)
+	}
+
+	har, err := readHAR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := har.historyEntries()
+	first := entries[0].Response
+	if first.Status != "200 OK" || first.Body != `{"ok":true}` || first.Headers.Get("Content-Type") != "application/json" || entries[0].DurationMs != 120 {
+		t.Errorf("first response = %+v", first)
+	}
+	want := requestTiming{
+		DNS:      span{2 * time.Millisecond, 10 * time.Millisecond},
+		Connect:  span{12 * time.Millisecond, 10 * time.Millisecond},
+		TLS:      span{22 * time.Millisecond, 20 * time.Millisecond},
+		Wait:     span{42500 * time.Microsecond, 70 * time.Millisecond},
+		Transfer: span{112500 * time.Microsecond, 8 * time.Millisecond},
+		Total:    120500 * time.Microsecond,
+	}
+	if first.Timing != want {
+		t.Errorf("timing = %+v, want %+v", first.Timing, want)
+	}
+	if image := entries[2].Response; image.Encoding != "base64" || image.Body != "iVBORw==" || image.Status != "201 Created" {
+		t.Errorf("binary response = %+v", image)
+	}
+}
+
+// TestExportHAR tests recording responses in history, exporting marked
+// entries to a HAR file and importing it into history again
+func TestExportHAR(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Seen", r.Header.Get("X-Token"))
+		_, _ = w.Write([]byte("hello " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.envs = environmentStore{Environments: []environment{{Name: "dev", Variables: map[string]string{"TOKEN": "t0k"}}}, Active: "dev"}
+	press := func(msg tea.Msg) {
+		updated, cmd := m.Update(msg)
+		m = updated.(model)
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyEnter && cmd != nil && m.loading {
+			updated, _ = m.Update(cmd())
+			m = updated.(model)
+		}
+	}
+	send := func(path string) {
+		m.pane = paneEditor
+		m.url.SetValue(server.URL + path)
+		m.headers[0].key.SetValue("X-Token")
+		m.headers[0].value.SetValue("${TOKEN}")
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	send("/a")
+	send("/b")
+	send("/c")
+	if r := m.history[0].Response; r == nil || r.Body != "hello /c" || r.StatusCode != 200 || r.SentAt.IsZero() {
+		t.Fatalf("the response should be recorded in history, got %+v", r)
+	}
+
+	// Mark /a and /c
+	m.pane = paneSidebar
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")})
+	if it := m.sidebar.Items()[0].(reqItem); !strings.HasSuffix(it.title, "(marked)") {
+		t.Errorf("marked entries should be labelled, got %q", it.title)
+	}
+	dir := t.TempDir()
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m.prompt.SetValue(dir)
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.err != nil {
+		t.Fatal(m.err)
+	}
+
+	path := filepath.Join(dir, "getboy.har")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatal(err)
+	}
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 2 {
+		t.Fatalf("export =\n%s", data)
+	}
+	first := har.Log.Entries[0]
+	if !strings.HasSuffix(first.Request.URL, "/a") || first.Response.Content.Text != "hello /a" || first.Response.StatusText != "OK" {
+		t.Errorf("entries should be oldest first with their responses, got %+v", first)
+	}
+	if !reflect.DeepEqual(first.Request.Headers, []harNameValue{{"X-Token", "${TOKEN}"}}) {
+		t.Errorf("variables should be exported as placeholders, got %v", first.Request.Headers)
+	}
+	if !strings.Contains(string(data), `"name": "X-Seen"`) || first.Timings.Wait < 0 {
+		t.Errorf("export =\n%s", data)
+	}
+
+	// Without marks the selected entry is exported on its own
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m.prompt.SetValue(filepath.Join(dir, "one.har"))
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if want := "Exported request to " + filepath.Join(dir, "one.har"); m.err != nil || m.status != want {
+		t.Errorf("status = %q (%v), want %q", m.status, m.err, want)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	m = New().(model)
+	m.pane = paneSidebar
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+	m.prompt.SetValue(path)
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.err != nil {
+		t.Fatal(m.err)
+	}
+	if len(m.history) != 2 || m.history[0].Response == nil || m.history[0].Response.Body != "hello /c" {
+		t.Errorf("history = %+v", m.history)
+	}
+	if saved, _ := loadHistory(); len(saved) != 2 {
+		t.Errorf("imported history should be saved, got %d entries", len(saved))
+	}
+
+	// A file with more requests than history holds says how many were kept
+	har.Log.Entries = nil
+	for i := range maxHistoryItems + 5 {
+		e := first
+		e.Request.URL = fmt.Sprintf("%s?page=%d", first.Request.URL, i)
+		har.Log.Entries = append(har.Log.Entries, e)
+	}
+	data, _ = json.Marshal(har)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+	m.prompt.SetValue(path)
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.err == nil || !strings.Contains(m.err.Error(), "only the last 100 of 105 were kept") || len(m.history) != maxHistoryItems {
+		t.Errorf("error = %v with %d entries, want a note that history is full", m.err, len(m.history))
+	}
+}
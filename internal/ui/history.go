@@ -2,16 +2,28 @@ package ui
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
+	"unicode/utf8"
 )
 
 const (
-	historyFileName = "history.json"
-	maxHistoryItems = 100
+	historyFileName  = "history.json"
+	responsesDirName = "responses"
+	maxHistoryItems  = 100
+
+	// maxHistoryBody is how much of a response body history keeps
+	maxHistoryBody = 8 << 20
+
+	// maxInlineHistoryBody is the largest body kept in the history file
+	// itself; larger ones are stored in their own file in responsesDirName
+	maxInlineHistoryBody = 64 << 10
 )
 
 // historyEntry represents a single history item for persistence
@@ -20,6 +32,213 @@ type historyEntry struct {
 	URL     string            `json:"url"`
 	Body    string            `json:"body,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+
+	BodyType string      `json:"body_type,omitempty"`
+	Form     []formField `json:"form,omitempty"`
+	BodyFile string      `json:"body_file,omitempty"`
+
+	Auth     requestAuth     `json:"auth,omitzero"`
+	Settings requestSettings `json:"settings,omitzero"`
+
+	DurationMs int64            `json:"duration_ms,omitempty"` // total time of the last send
+	Response   *historyResponse `json:"response,omitempty"`    // response to the last send
+}
+
+// historyResponse is the response a history entry got, kept so it can be
+// shown again and the exchange exported
+type historyResponse struct {
+	SentAt        time.Time     `json:"sent_at"`
+	Status        string        `json:"status"`
+	StatusCode    int           `json:"status_code"`
+	Proto         string        `json:"proto,omitempty"`
+	Headers       http.Header   `json:"headers,omitempty"`
+	Body          string        `json:"body,omitempty"`
+	Encoding      string        `json:"encoding,omitempty"`  // "base64" for binary bodies
+	BodyFile      string        `json:"body_file,omitempty"` // file in responsesDirName holding a large body instead of Body
+	Truncated     bool          `json:"truncated,omitempty"` // only the start of the body was kept
+	Size          int           `json:"size"`
+	ContentLength int64         `json:"content_length"`
+	SavedTo       string        `json:"saved_to,omitempty"`
+	FinalURL      string        `json:"final_url,omitempty"`
+	RemoteAddr    string        `json:"remote_addr,omitempty"`
+	TLSVersion    string        `json:"tls_version,omitempty"`
+	Timing        requestTiming `json:"timing"`
+}
+
+// newHistoryResponse records a response, keeping at most maxHistoryBody
+// bytes of its body
+func newHistoryResponse(msg httpDoneMsg) *historyResponse {
+	r := &historyResponse{
+		SentAt:        msg.SentAt,
+		Status:        msg.Status,
+		StatusCode:    msg.StatusCode,
+		Proto:         msg.Proto,
+		Headers:       msg.Headers,
+		Truncated:     msg.Truncated,
+		Size:          msg.Size,
+		ContentLength: msg.ContentLength,
+		SavedTo:       msg.SavedTo,
+		FinalURL:      msg.FinalURL,
+		RemoteAddr:    msg.RemoteAddr,
+		TLSVersion:    msg.TLSVersion,
+		Timing:        msg.Timing,
+	}
+	r.setBody(msg.Body)
+	return r
+}
+
+// setBody stores at most maxHistoryBody bytes of a body. Large bodies go
+// to their own file, or are cut to maxInlineHistoryBody if that fails;
+// others are kept inline, base64 encoded unless they are text.
+func (r *historyResponse) setBody(body string) {
+	body = r.truncateBody(body, maxHistoryBody)
+	r.Body, r.Encoding, r.BodyFile = "", "", ""
+	if len(body) > maxInlineHistoryBody {
+		if file, err := writeResponseBlob(body); err == nil {
+			r.BodyFile = file
+			return
+		}
+		body = r.truncateBody(body, maxInlineHistoryBody)
+	}
+	r.Body = body
+	if !utf8.ValidString(body) {
+		r.Body, r.Encoding = base64.StdEncoding.EncodeToString([]byte(body)), "base64"
+	}
+}
+
+// truncateBody cuts a body to at most limit bytes, marking the response
+// truncated if it was longer
+func (r *historyResponse) truncateBody(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n-- // don't split a character
+	}
+	r.Truncated = true
+	return body[:n]
+}
+
+// body returns the stored body, reading it from its own file if needed
+func (r *historyResponse) body() (string, error) {
+	if r.BodyFile != "" {
+		dir, err := getResponsesDir()
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, r.BodyFile))
+		return string(data), err
+	}
+	if r.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(r.Body)
+		return string(b), err
+	}
+	return r.Body, nil
+}
+
+// message rebuilds the response as it was received, for the response pane
+func (r *historyResponse) message() (httpDoneMsg, error) {
+	body, err := r.body()
+	return httpDoneMsg{
+		Status:        r.Status,
+		Body:          body,
+		StatusCode:    r.StatusCode,
+		Proto:         r.Proto,
+		Headers:       r.Headers,
+		ContentLength: r.ContentLength,
+		Size:          r.Size,
+		SavedTo:       r.SavedTo,
+		Truncated:     r.Truncated,
+		FinalURL:      r.FinalURL,
+		RemoteAddr:    r.RemoteAddr,
+		TLSVersion:    r.TLSVersion,
+		SentAt:        r.SentAt,
+		Timing:        r.Timing,
+		Restored:      true,
+	}, err
+}
+
+// getResponsesDir returns the directory holding large history response
+// bodies, creating it readable only by the user if needed
+func getResponsesDir() (string, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, responsesDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeResponseBlob stores a body in a file named after its content and
+// returns the file name
+func writeResponseBlob(body string) (string, error) {
+	dir, err := getResponsesDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(body))
+	file := hex.EncodeToString(sum[:]) + ".body"
+	return file, writePrivateFile(filepath.Join(dir, file), []byte(body))
+}
+
+// pruneResponseBlobs removes stored bodies no history entry refers to
+func pruneResponseBlobs(entries []historyEntry) {
+	dir, err := getResponsesDir()
+	if err != nil {
+		return
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	used := map[string]bool{}
+	for _, e := range entries {
+		if e.Response != nil && e.Response.BodyFile != "" {
+			used[e.Response.BodyFile] = true
+		}
+	}
+	for _, f := range files {
+		if !used[f.Name()] {
+			_ = os.Remove(filepath.Join(dir, f.Name())) // Best-effort cleanup
+		}
+	}
+}
+
+// newHistoryEntry records a request in history
+func newHistoryEntry(spec requestSpec) historyEntry {
+	return historyEntry{
+		Method:   spec.Method,
+		URL:      spec.URL,
+		Body:     spec.Body,
+		Headers:  spec.Headers,
+		BodyType: spec.BodyType,
+		Form:     spec.Form,
+		BodyFile: spec.BodyFile,
+		Auth:     spec.Auth,
+		Settings: spec.Settings,
+	}
+}
+
+// spec returns the request an entry records
+func (e historyEntry) spec() requestSpec {
+	return requestSpec{
+		Method:   e.Method,
+		URL:      e.URL,
+		Body:     e.Body,
+		Headers:  e.Headers,
+		BodyType: e.BodyType,
+		Form:     e.Form,
+		BodyFile: e.BodyFile,
+		Auth:     e.Auth,
+		Settings: e.Settings,
+	}
 }
 
 // hash returns a unique hash for the entire request
@@ -41,9 +260,36 @@ func (e historyEntry) hash() string {
 		h.Write([]byte(k))
 		h.Write([]byte(e.Headers[k]))
 	}
+	// Newer fields only count when set, so older entries keep their hash
+	if e.BodyType != "" || len(e.Form) > 0 || e.BodyFile != "" {
+		body, _ := json.Marshal(struct {
+			Type string
+			Form []formField
+			File string
+		}{e.BodyType, e.Form, e.BodyFile})
+		h.Write(body)
+	}
+	if e.Auth != (requestAuth{}) {
+		auth, _ := json.Marshal(e.Auth)
+		h.Write(auth)
+	}
+	if !e.Settings.isZero() {
+		settings, _ := json.Marshal(e.Settings)
+		h.Write(settings)
+	}
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// findHistory returns the index of the entry with the given hash, or -1
+func findHistory(entries []historyEntry, hash string) int {
+	for i, e := range entries {
+		if e.hash() == hash {
+			return i
+		}
+	}
+	return -1
+}
+
 // getDataDir returns the path to ~/.getboy, creating it if needed
 func getDataDir() (string, error) {
 	home, err := os.UserHomeDir()
@@ -57,6 +303,15 @@ func getDataDir() (string, error) {
 	return dir, nil
 }
 
+// writePrivateFile writes data to path readable only by the user, also
+// tightening files that older versions wrote readable by everyone
+func writePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
 // loadHistory reads history from disk
 func loadHistory() ([]historyEntry, error) {
 	dir, err := getDataDir()
@@ -80,7 +335,8 @@ func loadHistory() ([]historyEntry, error) {
 	return entries, nil
 }
 
-// saveHistory writes history to disk
+// saveHistory writes history to disk, readable only by the user as it holds
+// credentials, and removes response bodies that dropped out of it
 func saveHistory(entries []historyEntry) error {
 	dir, err := getDataDir()
 	if err != nil {
@@ -92,7 +348,11 @@ func saveHistory(entries []historyEntry) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	if err := writePrivateFile(path, data); err != nil {
+		return err
+	}
+	pruneResponseBlobs(entries)
+	return nil
 }
 
 // addToHistory adds a new entry to history, avoiding duplicates
@@ -128,12 +388,10 @@ func historyToItems(entries []historyEntry) []reqItem {
 		// Create a short title from the URL
 		title := e.Method + " " + truncateURL(e.URL, 30)
 		items[i] = reqItem{
-			title:   title,
-			desc:    e.URL,
-			method:  e.Method,
-			url:     e.URL,
-			body:    e.Body,
-			headers: e.Headers,
+			title: title,
+			desc:  e.URL,
+			spec:  e.spec(),
+			hash:  e.hash(),
 		}
 	}
 	return items
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -131,22 +134,22 @@ func TestHistoryToItems(t *testing.T) {
 	}
 
 	// Check first item
-	if items[0].method != "GET" {
-		t.Errorf("first method = %q, want %q", items[0].method, "GET")
+	if items[0].spec.Method != "GET" {
+		t.Errorf("first method = %q, want %q", items[0].spec.Method, "GET")
 	}
-	if items[0].url != "https://example.com/api" {
-		t.Errorf("first url = %q, want %q", items[0].url, "https://example.com/api")
+	if items[0].spec.URL != "https://example.com/api" {
+		t.Errorf("first url = %q, want %q", items[0].spec.URL, "https://example.com/api")
 	}
 
 	// Check second item
-	if items[1].method != "POST" {
-		t.Errorf("second method = %q, want %q", items[1].method, "POST")
+	if items[1].spec.Method != "POST" {
+		t.Errorf("second method = %q, want %q", items[1].spec.Method, "POST")
 	}
-	if items[1].body != `{"key":"value"}` {
-		t.Errorf("second body = %q, want %q", items[1].body, `{"key":"value"}`)
+	if items[1].spec.Body != `{"key":"value"}` {
+		t.Errorf("second body = %q, want %q", items[1].spec.Body, `{"key":"value"}`)
 	}
-	if items[1].headers["Content-Type"] != "application/json" {
-		t.Errorf("second Content-Type = %q, want %q", items[1].headers["Content-Type"], "application/json")
+	if items[1].spec.Headers["Content-Type"] != "application/json" {
+		t.Errorf("second Content-Type = %q, want %q", items[1].spec.Headers["Content-Type"], "application/json")
 	}
 }
 
@@ -266,10 +269,10 @@ func TestLoadFromHistoryWithHeaders(t *testing.T) {
 	m.pane = paneSidebar
 
 	// Add a history item with headers (uses temp dir)
-	m.addToHistoryAndSave("POST", "https://api.example.com", `{"data":"test"}`, map[string]string{
+	m.addToHistoryAndSave(requestSpec{Method: "POST", URL: "https://api.example.com", Body: `{"data":"test"}`, Headers: map[string]string{
 		"Authorization": "Bearer token",
 		"Content-Type":  "application/json",
-	})
+	}})
 
 	// Press enter to load
 	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
@@ -319,9 +322,13 @@ func TestSaveAndLoadHistory(t *testing.T) {
 
 	// Verify file exists
 	historyPath := filepath.Join(tmpDir, ".getboy", "history.json")
-	if _, err := os.Stat(historyPath); os.IsNotExist(err) {
+	info, err := os.Stat(historyPath)
+	if os.IsNotExist(err) {
 		t.Fatal("history file was not created")
 	}
+	if err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("history mode = %v, want 0600", info.Mode().Perm())
+	}
 
 	// Load history
 	loaded, err := loadHistory()
@@ -356,18 +363,19 @@ func TestEmptySidebarShowsMessage(t *testing.T) {
 	}
 }
 
-// TestSavedTabShowsComingSoon tests that Saved tab shows coming soon message
-func TestSavedTabShowsComingSoon(t *testing.T) {
+// TestEmptySavedTabShowsMessage tests that an empty Saved tab explains how to save
+func TestEmptySavedTabShowsMessage(t *testing.T) {
 	m := New().(model)
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
 	m = updated.(model)
 
-	// Switch to Saved tab
+	// Switch to Saved tab with nothing saved
 	m.sidebarTab = sidebarSaved
+	m.collections = nil
 
 	view := m.viewSidebar()
-	if !containsAny(view, "coming soon") {
-		t.Error("Saved tab should show 'coming soon' message")
+	if !containsAny(view, "No saved requests") {
+		t.Error("empty Saved tab should show 'No saved requests' message")
 	}
 }
 
@@ -394,3 +402,102 @@ func containsStringHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// TestHistoryRestoresResponse tests that loading a history item shows the
+// response it got, with large bodies stored outside the history file
+func TestHistoryRestoresResponse(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	large := strings.Repeat("x", maxInlineHistoryBody+1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/large" {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	}))
+	defer server.Close()
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	send := func(path string) {
+		m.pane = paneEditor
+		m.url.SetValue(server.URL + path)
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updated.(model)
+		updated, _ = m.Update(cmd())
+		m = updated.(model)
+	}
+	send("/teapot")
+	send("/large")
+
+	data, err := os.ReadFile(filepath.Join(home, ".getboy", historyFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), large) || !strings.Contains(string(data), "short and stout") {
+		t.Error("only small bodies should be kept in the history file")
+	}
+	blobs, _ := os.ReadDir(filepath.Join(home, ".getboy", responsesDirName))
+	if len(blobs) != 1 {
+		t.Fatalf("the large body should be stored on its own, got %d files", len(blobs))
+	}
+
+	// A restarted session restores the response of the selected item
+	m = New().(model)
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneSidebar
+	for _, want := range []struct {
+		status, body string
+	}{{"200 OK", large}, {"418 I'm a teapot", "short and stout"}} {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updated.(model)
+		if m.resp == nil || m.resp.Status != want.status || m.resp.Body != want.body || !m.resp.Restored || m.resp.SentAt.IsZero() {
+			t.Fatalf("restored response = %+v", m.resp)
+		}
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = updated.(model)
+	}
+	if !strings.Contains(m.respContent, "short and stout") {
+		t.Errorf("the response pane should show the body, got %q", m.respContent)
+	}
+
+	// Bodies are removed with their entries
+	m.history = m.history[1:]
+	if err := saveHistory(m.history); err != nil {
+		t.Fatal(err)
+	}
+	if blobs, _ := os.ReadDir(filepath.Join(home, ".getboy", responsesDirName)); len(blobs) != 0 {
+		t.Errorf("stored bodies should be pruned, got %d files", len(blobs))
+	}
+}
+
+// TestHistoryResponseBody tests capping and encoding stored bodies
+func TestHistoryResponseBody(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	r := newHistoryResponse(httpDoneMsg{Status: "200 OK", Body: "\x00\xff"})
+	if r.Encoding != "base64" || r.Truncated {
+		t.Errorf("binary bodies should be base64 encoded, got %+v", r)
+	}
+	if body, err := r.body(); err != nil || body != "\x00\xff" {
+		t.Errorf("body = %q, %v", body, err)
+	}
+
+	huge := newHistoryResponse(httpDoneMsg{Body: strings.Repeat("é", maxHistoryBody)})
+	body, err := huge.body()
+	if err != nil || !huge.Truncated || len(body) > maxHistoryBody || !strings.HasSuffix(body, "é") {
+		t.Errorf("large bodies should be cut at a character to %d bytes, got %d (%v)", maxHistoryBody, len(body), err)
+	}
+
+	dir, _ := getResponsesDir()
+	for path, want := range map[string]os.FileMode{dir: 0700, filepath.Join(dir, huge.BodyFile): 0600} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != want {
+			t.Errorf("%s should only be readable by the user, got %v", path, err)
+		}
+	}
+}
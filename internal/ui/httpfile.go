@@ -0,0 +1,606 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requestFileExts are the extensions of request files opened in place
+var requestFileExts = []string{".http", ".rest"}
+
+var (
+	// httpRequestLinePattern matches a request line such as "GET /users HTTP/1.1"
+	httpRequestLinePattern = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|TRACE|CONNECT)\s+(\S+)(?:\s+(HTTP/\S+))?$`)
+
+	// httpVarPattern matches a file variable definition such as "@host = example.com"
+	httpVarPattern = regexp.MustCompile(`^@([A-Za-z_][\w.-]*)\s*=\s*(.*)$`)
+
+	// httpDirectivePattern matches the comment directives getboy reads and writes
+	httpDirectivePattern = regexp.MustCompile(`^(?:#|//)\s*@(name|no-redirect|no-cookie-jar)\b\s*(.*)$`)
+)
+
+// multipartBoundary separates the parts of multipart bodies written to request files
+const multipartBoundary = "getboy-boundary"
+
+// IsRequestFile reports whether a path names a .http or .rest request file
+func IsRequestFile(path string) bool {
+	return slices.Contains(requestFileExts, strings.ToLower(filepath.Ext(path)))
+}
+
+// Open starts the TUI with request files opened as collections, which are
+// read from and saved back to the files themselves
+func Open(paths []string) (tea.Model, error) {
+	m := New().(model)
+	var names []string
+	for _, path := range paths {
+		if !IsRequestFile(path) {
+			return nil, fmt.Errorf("%s: not a .http or .rest file", path)
+		}
+		c, err := openHTTPFile(path)
+		if err != nil {
+			return nil, err
+		}
+		c.Name = uniqueName(c.Name, func(n string) bool { return findCollection(m.collections, n) != nil })
+		m.collections = append(m.collections, c)
+		m.expandPath([]string{c.Name})
+		names = append(names, c.Name)
+	}
+	sortCollections(m.collections)
+	m.refreshTree()
+	if len(names) > 0 {
+		m.sidebarTab = sidebarSaved
+		m.selectTreeNode([]string{names[0]})
+	}
+	return m, nil
+}
+
+// httpFile is a .http or .rest request file opened as a collection. Saving
+// rewrites only the requests that changed and keeps the rest of the file,
+// comments and variables included, as written.
+type httpFile struct {
+	path    string
+	modTime time.Time // when the file was read, to catch edits by other programs
+	crlf    bool
+	chunks  []httpChunk
+}
+
+// httpChunk is a run of lines of a request file: a request with the
+// separator and comments before it, or text between requests
+type httpChunk struct {
+	head []string // separator, comments and directives before the request line
+	body []string // request line through the body; empty for text
+	tail []string // blank lines and response handlers after the body
+
+	name        string // name of the request in the collection
+	origName    string // name as read from the file
+	named       bool   // named by a @name directive
+	titled      bool   // named by the ### separator starting head
+	version     string // HTTP version on the request line, if any
+	headerOrder []string
+	spec        requestSpec // request as read, to tell whether it was edited
+}
+
+// openHTTPFile reads a request file into a collection. A file that doesn't
+// exist yet opens empty and is created on the first save.
+func openHTTPFile(path string) (*collection, error) {
+	path, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	h := &httpFile{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil {
+		h.modTime = info.ModTime()
+	}
+	reqs, vars := h.parse(string(data))
+	return &collection{folder: folder{Name: filepath.Base(path), Variables: vars, Requests: reqs}, http: h}, nil
+}
+
+// isHTTPSeparator reports whether a line starts a new request
+func isHTTPSeparator(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "###")
+}
+
+// isHTTPComment reports whether a line is a comment
+func isHTTPComment(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "#") || strings.HasPrefix(t, "//")
+}
+
+// parse splits the file's text into chunks and returns its requests and
+// variables
+func (h *httpFile) parse(content string) ([]savedRequest, map[string]string) {
+	h.crlf = strings.Contains(content, "\r\n")
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	h.chunks = nil
+
+	var segments [][]string
+	if content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			if len(segments) == 0 || isHTTPSeparator(line) {
+				segments = append(segments, nil)
+			}
+			segments[len(segments)-1] = append(segments[len(segments)-1], line)
+		}
+	}
+
+	var reqs []savedRequest
+	var vars map[string]string
+	dir := filepath.Dir(h.path)
+	for _, seg := range segments {
+		start := slices.IndexFunc(seg, func(line string) bool {
+			t := strings.TrimSpace(line)
+			return t != "" && !isHTTPComment(t) && !isHTTPSeparator(t) && !httpVarPattern.MatchString(t)
+		})
+		if start < 0 {
+			start = len(seg)
+		}
+		for _, line := range seg[:start] {
+			if match := httpVarPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+				if vars == nil {
+					vars = map[string]string{}
+				}
+				// Values may use variables defined above them
+				vars[match[1]] = envVarPattern.ReplaceAllStringFunc(fromBraceVars(match[2]), func(ref string) string {
+					if v, ok := vars[ref[2:len(ref)-1]]; ok {
+						return v
+					}
+					return ref
+				})
+			}
+		}
+
+		if start == len(seg) {
+			h.chunks = append(h.chunks, httpChunk{head: seg})
+			continue
+		}
+
+		// Text up to the last variable stays in place even if the request
+		// after it is deleted
+		head := seg[:start]
+		split := 0
+		for i, line := range head {
+			if httpVarPattern.MatchString(strings.TrimSpace(line)) {
+				split = i + 1
+			}
+		}
+		for split > 0 && split < len(head) && strings.TrimSpace(head[split]) == "" {
+			split++
+		}
+		if split > 0 {
+			h.chunks = append(h.chunks, httpChunk{head: head[:split]})
+		}
+
+		rest := seg[start:]
+		end := len(rest)
+		for i := 1; i < len(rest); i++ {
+			// Response handlers and redirections follow the body
+			if t := strings.TrimSpace(rest[i]); strings.HasPrefix(t, "> ") || strings.HasPrefix(t, ">>") {
+				end = i
+				break
+			}
+		}
+		for end > 1 && strings.TrimSpace(rest[end-1]) == "" {
+			end--
+		}
+		c := httpChunk{head: head[split:], body: rest[:end], tail: rest[end:]}
+		c.parseRequest(httpTitle(seg[0]), dir)
+		c.name = uniqueName(c.name, func(n string) bool {
+			return slices.ContainsFunc(reqs, func(r savedRequest) bool { return r.Name == n })
+		})
+		c.origName = c.name
+		reqs = append(reqs, savedRequest{Name: c.name, requestSpec: c.spec})
+		h.chunks = append(h.chunks, c)
+	}
+	return reqs, vars
+}
+
+// httpTitle returns the title following ### on a separator line
+func httpTitle(line string) string {
+	if !isHTTPSeparator(line) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+}
+
+// parseRequest reads the chunk's request, name and directives. The
+// request is named by a @name directive, else by the title of its
+// separator, else after its method and path.
+func (c *httpChunk) parseRequest(title, dir string) {
+	first := strings.TrimSpace(c.body[0])
+	method, url := "GET", first
+	if match := httpRequestLinePattern.FindStringSubmatch(first); match != nil {
+		method, url, c.version = match[1], match[2], match[3]
+	} else if u, version, ok := strings.Cut(first, " HTTP/"); ok {
+		url, c.version = u, "HTTP/"+version
+	}
+
+	// Query parameters may continue on the following lines
+	i := 1
+	for ; i < len(c.body); i++ {
+		t := strings.TrimSpace(c.body[i])
+		if !strings.HasPrefix(t, "?") && !strings.HasPrefix(t, "&") {
+			break
+		}
+		url += t
+	}
+	c.spec = requestSpec{Method: method, URL: fromBraceVars(url)}
+
+	for ; i < len(c.body) && strings.TrimSpace(c.body[i]) != ""; i++ {
+		if isHTTPComment(c.body[i]) {
+			continue
+		}
+		k, v, ok := strings.Cut(c.body[i], ":")
+		if !ok {
+			continue
+		}
+		k = strings.TrimSpace(k)
+		c.headerOrder = append(c.headerOrder, fromBraceVars(k))
+		setHeader(&c.spec, fromBraceVars(k), fromBraceVars(strings.TrimSpace(v)))
+	}
+	if i < len(c.body) {
+		setHTTPBody(&c.spec, strings.Trim(strings.Join(c.body[i+1:], "\n"), "\n"), dir)
+	}
+	parseHTTPBasicAuth(&c.spec)
+
+	for _, line := range c.head {
+		match := httpDirectivePattern.FindStringSubmatch(strings.TrimSpace(line))
+		switch {
+		case match == nil:
+		case match[1] == "name" && match[2] != "":
+			c.name, c.named = importName(match[2]), true
+		case match[1] == "no-redirect":
+			c.spec.Settings.FollowRedirects = new(bool)
+		case match[1] == "no-cookie-jar":
+			c.spec.Settings.Cookies = new(bool)
+		}
+	}
+	if c.name == "" && title != "" {
+		// The separator may have been split off with the variables after it
+		c.name, c.titled = importName(title), len(c.head) > 0 && isHTTPSeparator(c.head[0])
+	}
+	if c.name == "" {
+		c.name = requestName(method, url)
+	}
+}
+
+// setHTTPBody sets the body of a request read from a file, picking the
+// body type from the Content-Type header or the content
+func setHTTPBody(spec *requestSpec, body, dir string) {
+	mediaType, params, _ := mime.ParseMediaType(lookupHeader(spec.Headers, "Content-Type"))
+	switch {
+	case body == "":
+	case isHTTPFileRef(body):
+		spec.BodyType = bodyBinary
+		spec.BodyFile = httpFilePath(body, dir)
+	case mediaType == "application/x-www-form-urlencoded":
+		spec.BodyType = bodyURLEncoded
+		for _, p := range parseQuery(strings.ReplaceAll(fromBraceVars(body), "\n", "")) {
+			spec.Form = append(spec.Form, formField{Key: p.Key, Value: p.Value})
+		}
+	case mediaType == "multipart/form-data" && parseHTTPMultipart(spec, body, params["boundary"], dir):
+	case isJSONMediaType(mediaType) || (mediaType == "" && detectContentType(body) == contentJSON):
+		spec.BodyType, spec.Body = bodyJSON, fromBraceVars(body)
+	case strings.HasSuffix(mediaType, "xml"):
+		spec.BodyType, spec.Body = bodyXML, fromBraceVars(body)
+	default:
+		spec.BodyType, spec.Body = bodyText, fromBraceVars(body)
+	}
+}
+
+// isHTTPFileRef reports whether a body is a "< path" reference to a file
+func isHTTPFileRef(body string) bool {
+	return !strings.Contains(body, "\n") && (strings.HasPrefix(body, "< ") || strings.HasPrefix(body, "<@ "))
+}
+
+// httpFilePath returns the path of a "< path" reference, relative paths
+// being relative to the request file
+func httpFilePath(ref, dir string) string {
+	path := strings.TrimSpace(strings.TrimLeft(ref, "<@"))
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") && !strings.Contains(path, "{{") {
+		path = filepath.Join(dir, path)
+	}
+	return fromBraceVars(path)
+}
+
+// relativeHTTPPath writes a path inside the request file's directory as
+// relative to it, so the file still works elsewhere
+func relativeHTTPPath(path, dir string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsAbs(path) && !strings.HasPrefix(rel, "..") {
+		return "./" + filepath.ToSlash(rel)
+	}
+	return toBraceVars(path)
+}
+
+// parseHTTPMultipart reads a multipart body written out part by part into
+// form fields, reporting false if it uses anything fields can't represent
+func parseHTTPMultipart(spec *requestSpec, body, boundary, dir string) bool {
+	if boundary == "" {
+		return false
+	}
+	var fields []formField
+	var part []string
+	inPart := false
+	for _, line := range strings.Split(body, "\n") {
+		t := strings.TrimSpace(line)
+		if t != "--"+boundary && t != "--"+boundary+"--" {
+			if !inPart && t != "" {
+				return false
+			}
+			part = append(part, line)
+			continue
+		}
+		if inPart {
+			field, ok := parseHTTPPart(part, dir)
+			if !ok {
+				return false
+			}
+			fields = append(fields, field)
+		}
+		part, inPart = nil, t == "--"+boundary
+	}
+	if inPart || len(fields) == 0 {
+		return false
+	}
+	spec.BodyType, spec.Form = bodyMultipart, fields
+	spec.Headers = withoutHeader(spec.Headers, "Content-Type")
+	return true
+}
+
+// parseHTTPPart reads one part of a multipart body
+func parseHTTPPart(lines []string, dir string) (formField, bool) {
+	i := slices.IndexFunc(lines, func(line string) bool { return strings.TrimSpace(line) == "" })
+	if i < 0 {
+		return formField{}, false
+	}
+	var name, filename string
+	for _, line := range lines[:i] {
+		k, v, _ := strings.Cut(line, ":")
+		if strings.EqualFold(strings.TrimSpace(k), "Content-Disposition") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+			if err != nil {
+				return formField{}, false
+			}
+			name, filename = params["name"], params["filename"]
+		}
+	}
+	content := strings.TrimRight(strings.Join(lines[i+1:], "\n"), "\n")
+	switch {
+	case name == "":
+		return formField{}, false
+	case isHTTPFileRef(content):
+		return formField{Key: fromBraceVars(name), Value: httpFilePath(content, dir), File: true}, true
+	case filename != "":
+		return formField{}, false // file contents written inline
+	}
+	return formField{Key: fromBraceVars(name), Value: fromBraceVars(content)}, true
+}
+
+// parseHTTPBasicAuth turns an "Authorization: Basic user:password" header,
+// which REST clients encode themselves, into basic auth
+func parseHTTPBasicAuth(spec *requestSpec) {
+	for k, v := range spec.Headers {
+		creds, ok := strings.CutPrefix(v, "Basic ")
+		if !strings.EqualFold(k, "Authorization") || !ok {
+			continue
+		}
+		user, password, ok := strings.Cut(strings.TrimSpace(creds), ":")
+		if !ok {
+			user, password, ok = strings.Cut(strings.TrimSpace(creds), " ")
+		}
+		if ok {
+			spec.Auth = requestAuth{Type: authBasic, Username: user, Password: strings.TrimSpace(password)}
+			spec.Headers = withoutHeader(spec.Headers, k)
+			if len(spec.Headers) == 0 {
+				spec.Headers = nil
+			}
+		}
+	}
+}
+
+// lookupHeader returns the value of a header, matching its name in any case
+func lookupHeader(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// renameRequest keeps a renamed request in its place in the file
+func (h *httpFile) renameRequest(from, to string) {
+	for i := range h.chunks {
+		if h.chunks[i].body != nil && h.chunks[i].name == from {
+			h.chunks[i].name = to
+		}
+	}
+}
+
+// write saves the collection's requests to the file. Requests are matched
+// to the file's by name; unchanged ones keep their text, edited ones are
+// rewritten in place, and new ones are added at the end.
+func (h *httpFile) write(c *collection) error {
+	base := filepath.Base(h.path)
+	if len(c.Folders) > 0 {
+		return fmt.Errorf("%s can't hold folders", base)
+	}
+	if info, err := os.Stat(h.path); err == nil && !info.ModTime().Equal(h.modTime) {
+		return fmt.Errorf("%s was changed by another program; reopen it to save", base)
+	}
+
+	dir := filepath.Dir(h.path)
+	pending := map[string]requestSpec{}
+	for _, r := range c.Requests {
+		pending[r.Name] = r.requestSpec
+	}
+
+	var out []string
+	separated := true // whether a ### separates the next request from the previous one
+	for _, chunk := range h.chunks {
+		if chunk.body == nil {
+			out = append(out, chunk.head...)
+			if slices.ContainsFunc(chunk.head, isHTTPSeparator) {
+				separated = true
+			}
+			continue
+		}
+		spec, ok := pending[chunk.name]
+		if !ok {
+			continue // deleted
+		}
+		delete(pending, chunk.name)
+		lines, err := chunk.render(spec, dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", chunk.name, err)
+		}
+		if !separated && (len(lines) == 0 || !isHTTPSeparator(lines[0])) {
+			out = append(out, "###")
+		}
+		out = append(out, lines...)
+		separated = false
+	}
+
+	for _, r := range c.Requests {
+		if _, ok := pending[r.Name]; !ok {
+			continue
+		}
+		lines, err := renderHTTPRequest(r.requestSpec, nil, "", dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		out = append(out, "### "+r.Name)
+		out = append(out, httpDirectives(r.requestSpec)...)
+		out = append(out, lines...)
+	}
+
+	content := strings.Join(out, "\n") + "\n"
+	if h.crlf {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	if err := os.WriteFile(h.path, []byte(content), 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(h.path); err == nil {
+		h.modTime = info.ModTime()
+	}
+	h.parse(content)
+	return nil
+}
+
+// render returns the chunk's lines for spec: as written if the request is
+// unchanged, otherwise with the request rewritten and its comments kept
+func (c httpChunk) render(spec requestSpec, dir string) ([]string, error) {
+	lines, err := renderHTTPRequest(spec, c.headerOrder, c.version, dir)
+	if err != nil {
+		return nil, err
+	}
+	orig, _ := renderHTTPRequest(c.spec, c.headerOrder, c.version, dir)
+	if c.name == c.origName && slices.Equal(lines, orig) && slices.Equal(httpDirectives(spec), httpDirectives(c.spec)) {
+		return slices.Concat(c.head, c.body, c.tail), nil
+	}
+
+	head := slices.DeleteFunc(slices.Clone(c.head), func(line string) bool {
+		return httpDirectivePattern.MatchString(strings.TrimSpace(line))
+	})
+	switch {
+	case c.titled:
+		head[0] = "### " + c.name
+	case c.named || c.name != c.origName:
+		head = append(head, "# @name "+c.name)
+	}
+	head = append(head, httpDirectives(spec)...)
+	return slices.Concat(head, lines, c.tail), nil
+}
+
+// httpDirectives returns the comment directives for a request's settings
+func httpDirectives(spec requestSpec) []string {
+	var lines []string
+	if s := spec.Settings.FollowRedirects; s != nil && !*s {
+		lines = append(lines, "# @no-redirect")
+	}
+	if s := spec.Settings.Cookies; s != nil && !*s {
+		lines = append(lines, "# @no-cookie-jar")
+	}
+	return lines
+}
+
+// renderHTTPRequest writes a request in .http syntax: the request line,
+// headers in order then sorted, and the body. File paths inside dir are
+// written relative to it.
+func renderHTTPRequest(spec requestSpec, order []string, version, dir string) ([]string, error) {
+	switch spec.Auth.Type {
+	case authOAuth2:
+		return nil, fmt.Errorf("OAuth 2.0 can't be saved to a request file; use a bearer token instead")
+	case authBasic:
+		spec.Headers = withoutHeader(spec.Headers, "Authorization")
+		spec.Headers["Authorization"] = "Basic " + spec.Auth.Username + ":" + spec.Auth.Password
+	default:
+		spec = applyAuth(spec)
+	}
+
+	headers := maps.Clone(spec.Headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	var body []string
+	switch {
+	case !spec.hasBody():
+	case isRawBody(spec.BodyType):
+		setDefaultHeader(headers, "Content-Type", rawContentType(spec.BodyType))
+		body = strings.Split(toBraceVars(spec.Body), "\n")
+	case spec.BodyType == bodyURLEncoded:
+		setDefaultHeader(headers, "Content-Type", "application/x-www-form-urlencoded")
+		body = []string{toBraceVars(encodeForm(spec.Form))}
+	case spec.BodyType == bodyMultipart:
+		headers = withoutHeader(headers, "Content-Type")
+		headers["Content-Type"] = "multipart/form-data; boundary=" + multipartBoundary
+		for _, f := range spec.Form {
+			disposition := fmt.Sprintf(`Content-Disposition: form-data; name="%s"`, toBraceVars(f.Key))
+			value := toBraceVars(f.Value)
+			if f.File {
+				disposition += fmt.Sprintf(`; filename="%s"`, filepath.Base(f.Value))
+				value = "< " + relativeHTTPPath(f.Value, dir)
+			}
+			body = append(body, "--"+multipartBoundary, disposition, "", value)
+		}
+		body = append(body, "--"+multipartBoundary+"--")
+	case spec.BodyType == bodyBinary:
+		body = []string{"< " + relativeHTTPPath(spec.BodyFile, dir)}
+	}
+
+	line := spec.Method + " " + toBraceVars(spec.URL)
+	if version != "" {
+		line += " " + version
+	}
+	lines := []string{line}
+	for _, k := range order {
+		if v, ok := headers[k]; ok {
+			lines = append(lines, toBraceVars(k)+": "+toBraceVars(v))
+			delete(headers, k)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		lines = append(lines, toBraceVars(k)+": "+toBraceVars(headers[k]))
+	}
+	if len(body) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, body...)
+	}
+	return lines, nil
+}
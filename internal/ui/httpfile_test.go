@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const httpTestFile = `# Shop API
+@host = https://shop.example.com
+@api = {{host}}/v1
+
+### List orders
+GET {{api}}/orders
+    ?limit=10
+    &sort=desc
+Accept: application/json
+
+### Create order
+# @name create
+# @no-redirect
+POST {{api}}/orders HTTP/1.1
+Content-Type: application/json
+Authorization: Basic ada:{{pw}}
+
+{
+  "sku": "{{sku}}"
+}
+
+> {% client.global.set("id", response.body.id); %}
+
+###
+// Health check
+https://shop.example.com/health
+
+###
+POST {{api}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user=ada&pass={{pw}}
+
+###
+PUT {{api}}/images
+Content-Type: multipart/form-data; boundary=b
+
+--b
+Content-Disposition: form-data; name="title"
+
+Cat
+--b
+Content-Disposition: form-data; name="image"; filename="cat.png"
+
+< ./cat.png
+--b--
+
+###
+POST {{api}}/dump
+Content-Type: application/octet-stream
+
+< ./dump.bin
+`
+
+// openTestHTTPFile writes a request file and opens it as a collection
+func openTestHTTPFile(t *testing.T, content string) (*collection, string) {
+	t.Helper()
+	path := writeSpec(t, "shop.http", content)
+	c, err := openHTTPFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, path
+}
+
+// TestParseHTTPFile tests reading requests and variables from a .http file
+func TestParseHTTPFile(t *testing.T) {
+	c, path := openTestHTTPFile(t, httpTestFile)
+	dir := filepath.Dir(path)
+	if c.Name != "shop.http" {
+		t.Errorf("name = %q, want the file name", c.Name)
+	}
+	if want := map[string]string{"host": "https://shop.example.com", "api": "https://shop.example.com/v1"}; !reflect.DeepEqual(c.Variables, want) {
+		t.Errorf("variables = %v, want %v", c.Variables, want)
+	}
+	var names []string
+	for _, r := range c.Requests {
+		names = append(names, r.Name)
+	}
+	if want := []string{"List orders", "create", "GET health", "POST login", "PUT images", "POST dump"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+
+	list := importedRequest(t, c, "List orders")
+	if list.URL != "${api}/orders?limit=10&sort=desc" || list.Headers["Accept"] != "application/json" {
+		t.Errorf("list = %s %v", list.URL, list.Headers)
+	}
+
+	create := importedRequest(t, c, "create")
+	if create.BodyType != bodyJSON || create.Body != "{\n  \"sku\": \"${sku}\"\n}" {
+		t.Errorf("create body %q:\n%s", create.BodyType, create.Body)
+	}
+	if create.Auth != (requestAuth{Type: authBasic, Username: "ada", Password: "${pw}"}) || create.Headers["Authorization"] != "" {
+		t.Errorf("create auth %+v headers %v", create.Auth, create.Headers)
+	}
+	if create.Settings.FollowRedirects == nil || *create.Settings.FollowRedirects {
+		t.Error("@no-redirect should turn off following redirects")
+	}
+
+	if health := importedRequest(t, c, "GET health"); health.Method != "GET" || health.URL != "https://shop.example.com/health" {
+		t.Errorf("health = %s %s", health.Method, health.URL)
+	}
+	login := importedRequest(t, c, "POST login")
+	if want := []formField{{Key: "user", Value: "ada"}, {Key: "pass", Value: "${pw}"}}; login.BodyType != bodyURLEncoded || !reflect.DeepEqual(login.Form, want) {
+		t.Errorf("login body %q %+v", login.BodyType, login.Form)
+	}
+	upload := importedRequest(t, c, "PUT images")
+	if want := []formField{{Key: "title", Value: "Cat"}, {Key: "image", Value: filepath.Join(dir, "cat.png"), File: true}}; upload.BodyType != bodyMultipart || !reflect.DeepEqual(upload.Form, want) {
+		t.Errorf("upload body %q %+v", upload.BodyType, upload.Form)
+	}
+	if dump := importedRequest(t, c, "POST dump"); dump.BodyType != bodyBinary || dump.BodyFile != filepath.Join(dir, "dump.bin") {
+		t.Errorf("dump body %q %s", dump.BodyType, dump.BodyFile)
+	}
+}
+
+// TestWriteHTTPFile tests that saving rewrites only what changed and keeps
+// the rest of the file as written
+func TestWriteHTTPFile(t *testing.T) {
+	c, path := openTestHTTPFile(t, httpTestFile)
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if err := writeCollection(c); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != httpTestFile {
+		t.Errorf("saving unchanged requests should keep the file, got\n%s", got)
+	}
+
+	c.Requests[0].Headers["Accept"] = "text/csv"
+	c.Requests[1].Name = "Create"
+	c.http.renameRequest("create", "Create")
+	c.Requests = append(c.Requests[:2], c.Requests[3:]...) // delete the health check
+	c.Requests = append(c.Requests, savedRequest{Name: "Delete order", requestSpec: requestSpec{Method: "DELETE", URL: "${api}/orders/${id}"}})
+	if err := writeCollection(c); err != nil {
+		t.Fatal(err)
+	}
+	got := read()
+	for _, want := range []string{
+		"# Shop API\n@host = https://shop.example.com\n@api = {{host}}/v1\n\n### List orders\nGET {{api}}/orders?limit=10&sort=desc\nAccept: text/csv\n\n###",
+		"### Create order\n# @name Create\n# @no-redirect\nPOST {{api}}/orders HTTP/1.1\n",
+		"\n\n> {% client.global.set",
+		"user=ada&pass={{pw}}",
+		"--b--\n\n###\nPOST {{api}}/dump",
+		"< ./dump.bin\n\n### Delete order\nDELETE {{api}}/orders/{{id}}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("file is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "health") {
+		t.Errorf("the deleted request should be removed:\n%s", got)
+	}
+	if len(c.http.chunks) == 0 || c.http.chunks[len(c.http.chunks)-1].name != "Delete order" {
+		t.Error("the file should be read back after saving")
+	}
+
+	// Edits made by another program aren't overwritten
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCollection(c); err == nil || !strings.Contains(err.Error(), "changed by another program") {
+		t.Errorf("error = %v, want a conflict", err)
+	}
+}
+
+// TestWriteNewHTTPFile tests creating a request file and keeping its line
+// endings
+func TestWriteNewHTTPFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.rest")
+	c, err := openHTTPFile(path)
+	if err != nil || len(c.Requests) != 0 {
+		t.Fatal(err, c.Requests)
+	}
+	c.Requests = []savedRequest{{Name: "Ping", requestSpec: requestSpec{Method: "GET", URL: "https://example.com/ping"}}}
+	if err := writeCollection(c); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "### Ping\nGET https://example.com/ping\n" {
+		t.Errorf("file =\n%s", data)
+	}
+
+	crlf, _ := openTestHTTPFile(t, "GET https://example.com/a\r\n\r\n###\r\nGET https://example.com/b\r\n")
+	crlf.Requests[1].URL = "https://example.com/c"
+	if err := writeCollection(crlf); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(crlf.http.path); string(data) != "GET https://example.com/a\r\n\r\n###\r\nGET https://example.com/c\r\n" {
+		t.Errorf("file = %q", data)
+	}
+
+	c.Folders = []*folder{{Name: "Sub"}}
+	if err := writeCollection(c); err == nil {
+		t.Error("request files can't hold folders")
+	}
+	c.Folders = nil
+	c.Requests[0].Auth = requestAuth{Type: authOAuth2}
+	if err := writeCollection(c); err == nil {
+		t.Error("OAuth 2.0 can't be written to a request file")
+	}
+}
+
+// TestOpenHTTPFiles tests opening request files as collections in the TUI
+func TestOpenHTTPFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := writeSpec(t, "api.http", "GET https://example.com/users\n")
+
+	tm, err := Open([]string{path, filepath.Join(filepath.Dir(path), "other.rest")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tm.(model)
+	if n, ok := m.selectedNode(); !ok || m.sidebarTab != sidebarSaved || n.key() != "api.http" {
+		t.Errorf("the first file should be selected on the Saved tab")
+	}
+
+	m.renameNode("api.http/GET users", "Users")
+	if m.err != nil {
+		t.Fatal(m.err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "# @name Users\nGET https://example.com/users\n" {
+		t.Errorf("file =\n%s", data)
+	}
+	m.collections[0].Requests = nil
+	m.createFolder("Sub")
+	if m.err == nil {
+		t.Error("request files can't hold folders")
+	}
+
+	m.err = nil
+	m.deleteNode("api.http")
+	if _, err := os.Stat(path); err != nil || len(m.collections) != 1 {
+		t.Errorf("closing a request file should keep it, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".getboy", collectionsDirName, collectionFileName("api.http"))); err == nil {
+		t.Error("request files shouldn't be copied into the collections directory")
+	}
+
+	if _, err := Open([]string{"notes.txt"}); err == nil {
+		t.Error("only .http and .rest files can be opened")
+	}
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// readDocument reads a JSON or YAML file into decoded JSON values
+func readDocument(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		return v, nil
+	}
+	v, err := parseYAML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return v, nil
+}
+
+// importFile converts an API description, Postman collection or HAR file
+// on disk into a collection, or a Postman environment into an environment
+func importFile(path string) (*collection, *environment, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := jsonObject(doc)
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var c *collection
+	switch {
+	case isOpenAPI(root):
+		c, err = importOpenAPI(root, name)
+	case isPostmanCollection(root):
+		c, err = importPostman(root, strings.TrimSuffix(name, ".postman_collection"))
+	case isHAR(root):
+		c, err = importHAR(path, name)
+	case isPostmanEnvironment(root):
+		return nil, importPostmanEnvironment(root, strings.TrimSuffix(name, ".postman_environment")), nil
+	default:
+		err = fmt.Errorf("%s is not an OpenAPI, Swagger, Postman or HAR file", filepath.Base(path))
+	}
+	return c, nil, err
+}
+
+// importName makes an imported title usable as a collection, folder or
+// request name, which cannot contain slashes
+func importName(s string) string {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, "/", " ")), " ")
+	if s == "" {
+		return "Imported"
+	}
+	return s
+}
+
+// requestName names an imported request after its method and path,
+// without scheme and host
+func requestName(method, url string) string {
+	base, _, _ := splitURL(url)
+	if _, rest, ok := strings.Cut(base, "://"); ok {
+		base = rest
+	}
+	if _, path, ok := strings.Cut(base, "/"); ok && path != "" {
+		base = path
+	}
+	return importName(method + " " + base)
+}
+
+// uniqueName returns name, or name with the lowest number appended that
+// taken reports as free
+func uniqueName(name string, taken func(string) bool) string {
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		candidate = name + " " + strconv.Itoa(i)
+	}
+	return candidate
+}
+
+// importFromFile imports a file from disk as a new collection or
+// environment, renaming it if its name is already taken
+func (m *model) importFromFile(path string) {
+	path = expandHome(path)
+	c, env, err := importFile(path)
+	if err != nil {
+		m.err = fmt.Errorf("import: %w", err)
+		return
+	}
+	if env != nil {
+		m.importEnvironment(*env, filepath.Base(path))
+		return
+	}
+
+	c.Name = uniqueName(c.Name, func(n string) bool {
+		for _, existing := range m.collections {
+			if existing.Name == n || collectionFileName(existing.Name) == collectionFileName(n) {
+				return true
+			}
+		}
+		return false
+	})
+	m.collections = append(m.collections, c)
+	sortCollections(m.collections)
+	m.expandPath([]string{c.Name})
+	m.persistCollection(c, fmt.Sprintf("Imported '%s' from %s", c.Name, filepath.Base(path)))
+	m.selectTreeNode([]string{c.Name})
+}
+
+// importEnvironment adds an imported environment without activating it
+func (m *model) importEnvironment(env environment, file string) {
+	env.Name = uniqueName(env.Name, func(n string) bool {
+		return slices.ContainsFunc(m.envs.Environments, func(e environment) bool { return e.Name == n })
+	})
+	m.envs.Environments = append(m.envs.Environments, env)
+	if err := saveEnvironments(m.envs); err != nil {
+		m.err = err
+		return
+	}
+	m.status = fmt.Sprintf("Imported environment '%s' from %s", env.Name, file)
+}
+
+// openExportCollection asks where to write the selected node's collection
+// as a Postman collection
+func (m *model) openExportCollection() {
+	n, ok := m.selectedNode()
+	if !ok {
+		return
+	}
+	name := strings.TrimSuffix(collectionFileName(n.path[0]), ".json") + ".postman_collection.json"
+	m.openPrompt(promptExportCollection, fmt.Sprintf("Export '%s' to:", n.path[0]), name)
+	m.promptTarget = n.path[0]
+}
+
+// exportCollection writes a collection to a file as a Postman v2.1
+// collection. A directory target gets the suggested file name.
+func (m *model) exportCollection(name, target string) {
+	c := findCollection(m.collections, name)
+	if c == nil || target == "" {
+		return
+	}
+	target = expandHome(target)
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		target = filepath.Join(target, strings.TrimSuffix(collectionFileName(c.Name), ".json")+".postman_collection.json")
+	}
+	data, err := json.MarshalIndent(exportPostman(c), "", "  ")
+	if err == nil {
+		err = os.WriteFile(target, data, 0644)
+	}
+	if err != nil {
+		m.err = fmt.Errorf("export: %w", err)
+		return
+	}
+	m.status = fmt.Sprintf("Exported '%s' to %s", c.Name, target)
+}
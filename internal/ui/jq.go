@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// jqTimeout bounds how long the response filter may run, as filters such as
+// repeat or range can go on forever
+const jqTimeout = 2 * time.Second
+
+// compileJQ parses and compiles a filter expression
+func compileJQ(src string) (*gojq.Code, error) {
+	q, err := gojq.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+	return gojq.Compile(q)
+}
+
+// runJQ applies a filter to a JSON document and renders each result as
+// indented JSON
+func runJQ(filter, doc string) (string, error) {
+	code, err := compileJQ(filter)
+	if err != nil {
+		return "", err
+	}
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber() // Keep big integers exact
+	var in any
+	if err := dec.Decode(&in); err != nil {
+		return "", fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jqTimeout)
+	defer cancel()
+	var results []string
+	iter := code.RunWithContext(ctx, in)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				break
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				return "", fmt.Errorf("filter ran for over %s", jqTimeout)
+			}
+			return "", err
+		}
+		out, err := gojq.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, out, "", "  "); err != nil {
+			return "", err
+		}
+		results = append(results, buf.String())
+	}
+	return strings.Join(results, "\n"), nil
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/gojq"
+)
+
+const jqTestDoc = `{
+	"data": {"total": 3, "next": null},
+	"items": [
+		{"id": 1, "name": "ant", "tags": ["a"], "price": 2.5},
+		{"id": 2, "name": "bee", "tags": [], "price": 10},
+		{"id": 12345678901234567890, "name": "cat", "tags": ["a", "b"], "price": 7}
+	]
+}`
+
+// jqResults runs a filter over jqTestDoc and returns its outputs as compact
+// JSON separated by spaces
+func jqResults(t *testing.T, filter string) string {
+	t.Helper()
+	code, err := compileJQ(filter)
+	if err != nil {
+		t.Fatalf("compileJQ(%q) error: %v", filter, err)
+	}
+	dec := json.NewDecoder(strings.NewReader(jqTestDoc))
+	dec.UseNumber()
+	var in any
+	if err := dec.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	var results []string
+	iter := code.Run(in)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			t.Fatalf("%q error: %v", filter, err)
+		}
+		b, _ := gojq.Marshal(v)
+		results = append(results, string(b))
+	}
+	return strings.Join(results, " ")
+}
+
+// TestJQFilters tests filters over a document with big integers
+func TestJQFilters(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{".data.total", "3"},
+		{".data.missing.deeper", "null"},
+		{`."data"["total"]`, "3"},
+		{".items[].id", "1 2 12345678901234567890"},
+		{".items[0].name, .items[-1].name", `"ant" "cat"`},
+		{".items[1:].[0].name", `"bee"`},
+		{".items[5]", "null"},
+		{".items | length", "3"},
+		{".data | keys", `["next","total"]`},
+		{"[.items[] | select(.price > 5) | .name]", `["bee","cat"]`},
+		{`.items[] | select(.name == "ant" or (.tags | length) > 1) | .id`, "1 12345678901234567890"},
+		{"[.items[].price] | add", "19.5"},
+		{".items[].name.first?", ""},
+		{".items | map({name, n: (.tags | length)}) | first", `{"n":1,"name":"ant"}`},
+		{".items | sort_by(.price) | reverse | .[0].name", `"bee"`},
+		{".items[2].tags | has(1)", "true"},
+		{"[.. | .name? | values]", `["ant","bee","cat"]`},
+		{`.data | to_entries | map(.key + "=" + (.value | tostring))`, `["next=null","total=3"]`},
+		{".items[0].price - 1, -.data.total", "1.5 -3"},
+		{"[.items[].tags | length] | sort", "[0,1,2]"},
+		{".data.next | not", "true"},
+		{"[.items[] | .name | type]", `["string","string","string"]`},
+		{`"ab"[0:1], empty, true and false`, `"a" false`},
+	}
+	for _, tt := range tests {
+		if got := jqResults(t, tt.filter); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.filter, got, tt.want)
+		}
+	}
+}
+
+// TestRunJQ tests that results are pretty-printed and errors reported
+func TestRunJQ(t *testing.T) {
+	got, err := runJQ(".items[0] | {id, tags}", jqTestDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"id\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}"
+	if got != want {
+		t.Errorf("runJQ = %q, want %q", got, want)
+	}
+	if got, err := runJQ(".items[-1].id, (.items[1:10000000000000000000] | length)", jqTestDoc); err != nil || got != "12345678901234567890\n2" {
+		t.Errorf("big integers should be kept exact, got %q, %v", got, err)
+	}
+
+	for _, tt := range []struct{ filter, doc, want string }{
+		{".items[", jqTestDoc, "filter:"},
+		{".items | frobnicate", jqTestDoc, "function not defined: frobnicate/0"},
+		{".items.name", jqTestDoc, "expected an object but got: array"},
+		{".a", "not json", "not JSON"},
+		{`"unterminated`, "{}", "unterminated string"},
+		{"repeat(.)", "{}", "filter ran for over"},
+	} {
+		if _, err := runJQ(tt.filter, tt.doc); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("runJQ(%q) error = %v, want %q", tt.filter, err, tt.want)
+		}
+	}
+}
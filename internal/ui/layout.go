@@ -13,7 +13,7 @@ func (m *model) recomputeLayout() {
 	m.sidebar.SetSize(sidebarWidth-2, contentHeight-2)
 	m.url.Width = rightWidth - 14 // Account for "  URL:    " prefix
 	m.body.SetWidth(rightWidth - 4)
-	m.body.SetHeight(editorHeight - 4)
+	m.body.SetHeight(editorHeight - 5) // Leave a line for the body type
 	m.view.Width = rightWidth - 4
 	m.view.Height = respHeight - 3
 }
@@ -1,7 +1,27 @@
 package ui
 
+import (
+	"net/http"
+	"time"
+)
+
 type httpDoneMsg struct {
+	ID     int // request the response belongs to, see model.reqID
 	Status string
 	Body   string
 	Err    error
+
+	StatusCode    int
+	Proto         string
+	Headers       http.Header
+	ContentLength int64  // as declared by the server, -1 if unknown
+	Size          int    // bytes actually received
+	SavedTo       string // file the body was streamed to instead of Body
+	Truncated     bool   // Body holds only the first maxBufferedBody bytes
+	FinalURL      string
+	RemoteAddr    string
+	TLSVersion    string // empty for plain HTTP
+	SentAt        time.Time
+	Timing        requestTiming
+	Restored      bool // loaded from history rather than received just now
 }
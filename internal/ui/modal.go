@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfnilsson/getboy/internal/ui/theme"
+)
+
+// modalKind identifies which dialog is shown over the panes
+type modalKind int
+
+const (
+	modalNone modalKind = iota
+	modalEnvironments
+	modalImportCurl
+	modalExport
+	modalCookies
+)
+
+// openModal shows a dialog over the panes
+func (m *model) openModal(kind modalKind) {
+	m.modal = kind
+	m.modalIdx = 0
+	m.insertMode = false
+	m.applyFocus()
+
+	if kind == modalImportCurl {
+		m.curlInput.Reset()
+		m.curlInput.SetWidth(max(m.width/2, 40) - 4)
+		m.curlInput.SetHeight(max(m.contentHeight()/2, 6))
+		m.curlInput.Focus()
+	}
+
+	if kind == modalEnvironments {
+		// Start on the active environment; index 0 is "No environment"
+		for i, e := range m.envs.Environments {
+			if e.Name == m.envs.Active {
+				m.modalIdx = i + 1
+			}
+		}
+	}
+}
+
+// updateModal routes key presses to the open dialog
+func (m model) updateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.modal {
+	case modalEnvironments:
+		switch msg.String() {
+		case "esc", "q", "e":
+			m.modal = modalNone
+		case "up", "k":
+			if m.modalIdx > 0 {
+				m.modalIdx--
+			}
+		case "down", "j":
+			if m.modalIdx < len(m.envs.Environments) {
+				m.modalIdx++
+			}
+		case "enter":
+			m.selectEnvironment(m.modalIdx)
+			m.modal = modalNone
+		}
+	case modalExport:
+		m.updateExport(msg.String())
+	case modalCookies:
+		m.updateCookieManager(msg.String())
+	case modalImportCurl:
+		switch msg.String() {
+		case "esc":
+			m.curlInput.Blur()
+			m.modal = modalNone
+			return m, nil
+		case "enter":
+			// A trailing backslash continues the command on the next line
+			if !strings.HasSuffix(strings.TrimRight(m.curlInput.Value(), " \t"), "\\") {
+				m.importCurl(m.curlInput.Value())
+				m.curlInput.Blur()
+				m.modal = modalNone
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.curlInput, cmd = m.curlInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// selectEnvironment activates the environment at picker index idx and persists the choice
+func (m *model) selectEnvironment(idx int) {
+	if idx == 0 {
+		m.envs.Active = ""
+	} else if idx-1 < len(m.envs.Environments) {
+		m.envs.Active = m.envs.Environments[idx-1].Name
+	}
+	if err := saveEnvironments(m.envs); err != nil {
+		m.err = err
+		return
+	}
+	if m.envs.Active == "" {
+		m.status = "No environment"
+	} else {
+		m.status = fmt.Sprintf("Environment '%s'", m.envs.Active)
+	}
+}
+
+// viewModal renders the open dialog centered over the content area
+func (m model) viewModal() string {
+	var title, content string
+	width := min(max(m.width/2, 40), max(m.width-4, 20))
+
+	switch m.modal {
+	case modalEnvironments:
+		title = "Environments"
+		content = m.viewEnvironmentPicker()
+	case modalExport:
+		title = "Export"
+		width = max(m.width*3/4, min(60, m.width-4))
+		content = m.viewExport(width)
+	case modalCookies:
+		title = "Cookies"
+		width = max(m.width*3/4, min(60, m.width-4))
+		content = m.viewCookieManager(width)
+	case modalImportCurl:
+		title = "Import curl"
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			m.curlInput.View(),
+			"",
+			lipgloss.NewStyle().Faint(true).Render("Paste a curl command  enter: import  esc: cancel"),
+		)
+	}
+
+	box := titledPane(content, width, 0, true, "", title)
+	return lipgloss.Place(m.width, m.contentHeight(), lipgloss.Center, lipgloss.Center, box)
+}
+
+// viewEnvironmentPicker renders the environment list
+func (m model) viewEnvironmentPicker() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	names := []string{"No environment"}
+	for _, e := range m.envs.Environments {
+		names = append(names, fmt.Sprintf("%s (%d vars)", e.Name, len(e.Variables)))
+	}
+
+	var lines []string
+	for i, name := range names {
+		active := (i == 0 && !m.envs.hasActive()) ||
+			(i > 0 && m.envs.Environments[i-1].Name == m.envs.Active)
+		marker := "  "
+		if active {
+			marker = "● "
+		}
+		line := marker + name
+		if i == m.modalIdx {
+			line = selectedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", faintStyle.Render(strings.Join([]string{
+		"j/k: select  enter: activate  esc: close",
+		"Define variables in ~/.getboy/" + environmentsFileName,
+	}, "\n")))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -1,7 +1,8 @@
 package ui
 
 import (
-	"net/url"
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -62,9 +63,19 @@ type model struct {
 	width  int
 	height int
 
-	sidebar    list.Model
-	sidebarTab sidebarTab     // History or Saved
-	history    []historyEntry // persisted history
+	sidebar       list.Model
+	sidebarTab    sidebarTab      // History or Saved
+	history       []historyEntry  // persisted history
+	historyMarked map[string]bool // hashes of history entries marked for export
+
+	collections []*collection   // persisted saved collections
+	expanded    map[string]bool // expanded collection/folder paths in the saved tree
+	tree        []treeNode      // visible rows of the saved tree
+	treeIdx     int             // which tree row is selected
+	moving      string          // path of the saved request being moved, if any
+	loadedPath  string          // path of the saved request loaded into the editor, if any
+
+	envs environmentStore // named variable sets and the active one
 
 	methodIdx      int // index into httpMethods
 	url            textinput.Model
@@ -73,6 +84,23 @@ type model struct {
 	headersRawText textarea.Model // textarea for raw headers mode
 	body           textarea.Model
 	view           viewport.Model
+	respTab        responseTab  // Body, Headers or Info
+	resp           *httpDoneMsg // last response, nil before the first request
+	respPages      int          // how many steps of a large body are rendered
+	sentHash       string       // history hash of the request in flight
+	reqID          int          // ID of the latest request; older responses are ignored
+	cancel         context.CancelFunc
+
+	respContent    string          // rendered response text, before search highlighting
+	respSearch     textinput.Model // search query typed after / in the response pane
+	respSearching  bool            // typing a search in the response pane
+	respSearchFrom int             // scroll offset the search started at
+	respMatches    []searchMatch   // search hits in respContent
+	respMatchIdx   int             // current search hit
+
+	respFilter     textinput.Model // jq filter being typed after f
+	respFiltering  bool            // typing a filter in the response pane
+	respFilterExpr string          // applied filter, empty to show the whole body
 
 	pane        focusPane
 	editorPart  editorFocus
@@ -84,6 +112,51 @@ type model struct {
 	headerField headerField // key or value within the row
 	headersRaw  bool        // toggle for raw view mode
 
+	bodyType  string      // selected body type in the Body tab
+	formRows  []formRow   // fields of form bodies
+	formIdx   int         // which form row is selected
+	formField headerField // key or value within the form row
+	bodyFile  textinput.Model
+
+	authType     string // selected auth type in the Auth tab
+	authIn       string // where an API key is sent
+	authIdx      int    // which Auth row is selected
+	authUsername textinput.Model
+	authPassword textinput.Model
+	authToken    textinput.Model
+	authKey      textinput.Model
+	authValue    textinput.Model
+
+	oauthGrant        string // selected OAuth 2.0 grant
+	oauthTokenURL     textinput.Model
+	oauthAuthURL      textinput.Model
+	oauthRedirectURL  textinput.Model
+	oauthClientID     textinput.Model
+	oauthClientSecret textinput.Model
+	oauthScope        textinput.Model
+
+	settings          requestSettings // global client settings
+	reqSettings       requestSettings // per-request overrides of the global settings
+	settingsIdx       settingsField   // which Settings row is selected
+	settingsGlobal    bool            // Settings tab edits the global settings instead of the request's
+	timeoutInput      textinput.Model
+	maxRedirectsInput textinput.Model
+	downloadToInput   textinput.Model
+
+	prompt       textinput.Model // footer input for names and confirmations
+	promptKind   promptKind
+	promptLabel  string
+	promptTarget string // saved tree path the prompt acts on
+
+	modal     modalKind      // dialog shown over the panes
+	modalIdx  int            // selected row in the dialog
+	curlInput textarea.Model // pasted curl command in the import dialog
+
+	exportFormat snippetFormat // format shown in the export dialog
+	exportExpand bool          // export with variables expanded instead of as placeholders
+
+	cookies []storedCookie // cookies shown in the cookie manager
+
 	status  string
 	loading bool
 	err     error
@@ -121,7 +194,10 @@ func (m *model) prevMethod() {
 
 func New() tea.Model {
 	// Load history from disk
-	history, _ := loadHistory() // Ignore error, start with empty history
+	history, _ := loadHistory()   // Ignore error, start with empty history
+	cols, _ := loadCollections()  // Ignore error, start with no collections
+	envs, _ := loadEnvironments() // Ignore error, start with no environments
+	settings, _ := loadSettings() // Ignore error, use the built-in defaults
 
 	// Convert history to list items
 	historyItems := historyToItems(history)
@@ -169,10 +245,20 @@ func New() tea.Model {
 	rawHeaders.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	rawHeaders.BlurredStyle.CursorLine = lipgloss.NewStyle()
 
+	// Curl import textarea, unlimited so long pasted commands fit
+	curl := textarea.New()
+	curl.CharLimit = 0
+	curl.ShowLineNumbers = false
+	curl.Prompt = ""
+	curl.Placeholder = "curl https://example.com -H 'Accept: application/json'"
+	curl.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	curl.BlurredStyle.CursorLine = lipgloss.NewStyle()
+
 	// Ensure all inputs start blurred (not in insert mode)
 	u.Blur()
 	t.Blur()
 	rawHeaders.Blur()
+	curl.Blur()
 
 	vp := viewport.New(0, 0)
 	vp.SetContent("Response will appear here…")
@@ -183,10 +269,14 @@ func New() tea.Model {
 	// Start with one empty param row
 	params := []paramRow{newParamRow()}
 
-	return model{
+	m := model{
 		sidebar:        sb,
 		sidebarTab:     sidebarHistory,
 		history:        history,
+		collections:    cols,
+		expanded:       map[string]bool{},
+		tree:           flattenTree(cols, nil),
+		envs:           envs,
 		methodIdx:      0, // Default to GET
 		url:            u,
 		params:         params,
@@ -194,10 +284,47 @@ func New() tea.Model {
 		headersRawText: rawHeaders,
 		body:           t,
 		view:           vp,
+		respSearch:     newSearchInput(),
+		respFilter:     newFilterInput(),
+		formRows:       []formRow{newFormRow()},
+		bodyFile:       newBodyFileInput(),
+		prompt:         newPromptInput(),
+		curlInput:      curl,
 		pane:           paneSidebar,
 		activeTab:      tabOverview,
 		status:         "1/2/3: panes  j/k: select  enter: load",
+
+		authUsername: newAuthInput("username"),
+		authPassword: newAuthInput("password"),
+		authToken:    newAuthInput("token"),
+		authKey:      newAuthInput("X-API-Key"),
+		authValue:    newAuthInput("key"),
+
+		oauthTokenURL:     newAuthInput("https://auth.example.com/oauth/token"),
+		oauthAuthURL:      newAuthInput("https://auth.example.com/oauth/authorize"),
+		oauthRedirectURL:  newAuthInput(oauthDefaultRedirect),
+		oauthClientID:     newAuthInput("client ID"),
+		oauthClientSecret: newAuthInput("none for public clients"),
+		oauthScope:        newAuthInput("space-separated scopes"),
+
+		settings:          settings,
+		timeoutInput:      newNumberInput(),
+		maxRedirectsInput: newNumberInput(),
+		downloadToInput:   newDownloadToInput(),
 	}
+	m.authPassword.EchoMode = textinput.EchoPassword
+	m.oauthClientSecret.EchoMode = textinput.EchoPassword
+	m.syncSettingsInputs()
+	return m
+}
+
+// newNumberInput creates a short input for numeric settings
+func newNumberInput() textinput.Model {
+	n := textinput.New()
+	n.CharLimit = 6
+	n.Width = 8
+	n.Prompt = ""
+	return n
 }
 
 // addHeaderRow adds a new header row after the current one
@@ -320,8 +447,23 @@ func (m *model) nextEditorPart() {
 			m.headerIdx++
 		}
 	case tabBody:
-		// Body tab has only one field, no navigation needed
 		m.editorPart = edBody
+		// Move to next form row; other body types have a single field
+		if isFormBody(m.bodyType) && m.formIdx < len(m.formRows)-1 {
+			m.formIdx++
+		}
+	case tabAuth:
+		m.editorPart = edAuth
+		// Move to next auth row
+		if m.authIdx < len(m.authRows())-1 {
+			m.authIdx++
+		}
+	case tabSettings:
+		m.editorPart = edSettings
+		// Move to next settings row
+		if m.settingsIdx < settingsFieldCount-1 {
+			m.settingsIdx++
+		}
 	}
 	m.applyFocus()
 }
@@ -348,19 +490,34 @@ func (m *model) prevEditorPart() {
 			m.headerIdx--
 		}
 	case tabBody:
-		// Body tab has only one field, no navigation needed
 		m.editorPart = edBody
+		// Move to previous form row; other body types have a single field
+		if isFormBody(m.bodyType) && m.formIdx > 0 {
+			m.formIdx--
+		}
+	case tabAuth:
+		m.editorPart = edAuth
+		// Move to previous auth row
+		if m.authIdx > 0 {
+			m.authIdx--
+		}
+	case tabSettings:
+		m.editorPart = edSettings
+		// Move to previous settings row
+		if m.settingsIdx > 0 {
+			m.settingsIdx--
+		}
 	}
 	m.applyFocus()
 }
 
 func (m *model) nextTab() {
-	m.activeTab = (m.activeTab + 1) % 4
+	m.activeTab = (m.activeTab + 1) % requestTabCount
 	m.resetEditorPartForTab()
 }
 
 func (m *model) prevTab() {
-	m.activeTab = (m.activeTab + 3) % 4
+	m.activeTab = (m.activeTab + requestTabCount - 1) % requestTabCount
 	m.resetEditorPartForTab()
 }
 
@@ -380,13 +537,35 @@ func (m *model) resetEditorPartForTab() {
 		m.headerField = headerKey
 	case tabBody:
 		m.editorPart = edBody
+		m.formIdx = 0
+		m.formField = headerKey
+	case tabAuth:
+		m.editorPart = edAuth
+		m.authIdx = 0
+	case tabSettings:
+		m.editorPart = edSettings
+		m.settingsIdx = 0
 	}
 }
 
 func (m *model) applyFocus() {
 	m.url.Blur()
-	m.body.Blur()
+	m.blurBody()
 	m.headersRawText.Blur()
+	m.timeoutInput.Blur()
+	m.maxRedirectsInput.Blur()
+	m.downloadToInput.Blur()
+	m.authUsername.Blur()
+	m.authPassword.Blur()
+	m.authToken.Blur()
+	m.authKey.Blur()
+	m.authValue.Blur()
+	m.oauthTokenURL.Blur()
+	m.oauthAuthURL.Blur()
+	m.oauthRedirectURL.Blur()
+	m.oauthClientID.Blur()
+	m.oauthClientSecret.Blur()
+	m.oauthScope.Blur()
 	// Blur all param inputs
 	for i := range m.params {
 		m.params[i].key.Blur()
@@ -426,14 +605,33 @@ func (m *model) applyFocus() {
 				}
 			}
 		case edBody:
-			m.body.Focus()
+			m.focusBody()
+		case edAuth:
+			if in := m.authInput(m.selectedAuthField()); in != nil {
+				in.Focus()
+			}
+		case edSettings:
+			switch m.settingsIdx {
+			case setTimeout:
+				m.timeoutInput.Focus()
+			case setMaxRedirects:
+				m.maxRedirectsInput.Focus()
+			case setDownloadTo:
+				m.downloadToInput.Focus()
+			}
 		}
 	}
 }
 
 func (m model) ensureURL(u string) string {
+	return ensureScheme(u)
+}
+
+// ensureScheme defaults a URL to https. URLs starting with a ${VAR}
+// placeholder are left alone since the variable usually carries the scheme.
+func ensureScheme(u string) string {
 	u = strings.TrimSpace(u)
-	if u == "" {
+	if u == "" || strings.HasPrefix(u, "${") {
 		return u
 	}
 	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
@@ -449,58 +647,45 @@ func (m *model) syncParamsFromURL() {
 		return
 	}
 
-	// Parse URL to extract query params
-	parsed, err := url.Parse(urlStr)
-	if err != nil {
-		return
-	}
-
-	query := parsed.Query()
-	if len(query) == 0 {
+	// Split rather than url.Parse so ${VAR} placeholders don't break parsing
+	_, rawQuery, _ := splitURL(urlStr)
+	pairs := parseQuery(rawQuery)
+	if len(pairs) == 0 {
 		// No query params, reset to single empty row
 		m.params = []paramRow{newParamRow()}
 		m.paramIdx = 0
 		return
 	}
 
-	// Build params from query
+	// Build params from query, keeping URL order
 	m.params = nil
-	for key, values := range query {
-		for _, val := range values {
-			row := newParamRow()
-			row.key.SetValue(key)
-			row.value.SetValue(val)
-			m.params = append(m.params, row)
-		}
-	}
-	if len(m.params) == 0 {
-		m.params = []paramRow{newParamRow()}
+	for _, p := range pairs {
+		row := newParamRow()
+		row.key.SetValue(p.Key)
+		row.value.SetValue(p.Value)
+		m.params = append(m.params, row)
 	}
 	m.paramIdx = 0
 }
 
 // syncURLFromParams updates the URL query string from params
 func (m *model) syncURLFromParams() {
-	urlStr := m.url.Value()
-
-	// Parse existing URL or create minimal one
-	parsed, err := url.Parse(urlStr)
-	if err != nil {
-		return
-	}
+	base, _, fragment := splitURL(m.url.Value())
 
 	// Build query string from params
-	query := url.Values{}
+	var pairs []queryPair
 	for _, p := range m.params {
 		key := strings.TrimSpace(p.key.Value())
 		if key != "" {
-			query.Add(key, p.value.Value())
+			pairs = append(pairs, queryPair{Key: key, Value: p.value.Value()})
 		}
 	}
 
 	// Update URL with new query string
-	parsed.RawQuery = query.Encode()
-	m.url.SetValue(parsed.String())
+	if len(pairs) > 0 {
+		base += "?" + encodeQuery(pairs)
+	}
+	m.url.SetValue(base + fragment)
 }
 
 // getHeaders returns headers as a map, skipping empty keys
@@ -547,22 +732,25 @@ func (m *model) updateSidebarItems() {
 		historyItems := historyToItems(m.history)
 		items = make([]list.Item, len(historyItems))
 		for i, item := range historyItems {
+			if m.historyMarked[item.hash] {
+				item.title += " (marked)"
+			}
 			items[i] = item
 		}
 	case sidebarSaved:
-		// Empty for now - "coming soon"
-		items = []list.Item{}
+		// Saved requests are rendered as a tree, not through the list
+		m.refreshTree()
+		return
 	}
 	m.sidebar.SetItems(items)
 }
 
 // addToHistoryAndSave adds an entry to history and persists to disk
-func (m *model) addToHistoryAndSave(method, url, body string, headers map[string]string) {
-	entry := historyEntry{
-		Method:  method,
-		URL:     url,
-		Body:    body,
-		Headers: headers,
+// Returns the entry's hash so the response can be recorded against it
+func (m *model) addToHistoryAndSave(spec requestSpec) string {
+	entry := newHistoryEntry(spec)
+	if i := findHistory(m.history, entry.hash()); i >= 0 {
+		entry.DurationMs, entry.Response = m.history[i].DurationMs, m.history[i].Response
 	}
 	m.history = addToHistory(m.history, entry)
 	_ = saveHistory(m.history) // Ignore error, history is best-effort
@@ -571,6 +759,19 @@ func (m *model) addToHistoryAndSave(method, url, body string, headers map[string
 	if m.sidebarTab == sidebarHistory {
 		m.updateSidebarItems()
 	}
+	return entry.hash()
+}
+
+// recordHistoryResponse stores a response and its total time on the
+// history entry that was sent
+func (m *model) recordHistoryResponse(hash string, msg httpDoneMsg) {
+	i := findHistory(m.history, hash)
+	if i < 0 {
+		return
+	}
+	m.history[i].DurationMs = msg.Timing.Total.Milliseconds()
+	m.history[i].Response = newHistoryResponse(msg)
+	_ = saveHistory(m.history) // Ignore error, history is best-effort
 }
 
 // setHeadersFromMap sets headers from a map (used when loading from history)
@@ -590,3 +791,88 @@ func (m *model) setHeadersFromMap(hdrs map[string]string) {
 	}
 	m.headerIdx = 0
 }
+
+// currentRequest captures the editor state as a request spec
+func (m model) currentRequest() requestSpec {
+	spec := requestSpec{
+		Method:   m.methodValue(),
+		URL:      m.url.Value(),
+		Headers:  m.getHeaders(),
+		Auth:     m.currentAuth(),
+		Settings: m.reqSettings,
+	}
+	m.currentBody(&spec)
+	return spec
+}
+
+// setRequest populates the editor from a request spec
+func (m *model) setRequest(spec requestSpec) {
+	m.setMethod(spec.Method)
+	m.url.SetValue(spec.URL)
+	m.setBody(spec)
+	m.setHeadersFromMap(spec.Headers)
+	m.setAuth(spec.Auth)
+	m.setRequestSettings(spec.Settings)
+}
+
+// loadItem populates the editor from a sidebar item, and the response
+// pane from the response history recorded for it
+func (m *model) loadItem(it reqItem) {
+	m.setRequest(it.spec)
+	m.loadedPath = ""
+	m.status = fmt.Sprintf("Loaded '%s'", it.title)
+	if m.loading {
+		return // the response in flight replaces the pane soon
+	}
+
+	m.resp = nil
+	m.respPages = 1
+	m.setResponseContent("")
+	i := findHistory(m.history, it.hash)
+	if i < 0 || m.history[i].Response == nil {
+		return
+	}
+	msg, err := m.history[i].Response.message()
+	if err != nil {
+		m.err = fmt.Errorf("restore response: %w", err)
+	}
+	m.resp = &msg
+	m.refreshResponseView()
+}
+
+// requestVars returns the variables the editor's request is expanded with:
+// the active environment's over those of the loaded request's collection
+func (m model) requestVars() map[string]string {
+	return mergeVars(collectionVars(m.collections, splitPath(m.loadedPath)), m.envs.activeVars())
+}
+
+// sendRequest starts a cancellable request, superseding any request in flight
+func (m *model) sendRequest(spec requestSpec) tea.Cmd {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.reqID++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.err = nil
+	m.loading = true
+	spec.Settings = spec.Settings.over(m.settings)
+	return doHTTPContext(ctx, m.reqID, spec, m.requestVars(), newCookieJar(m.envs.Active))
+}
+
+// cancelRequest aborts the request in flight and discards its response
+func (m *model) cancelRequest() {
+	if !m.loading {
+		return
+	}
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.reqID++ // The cancelled request's response is now stale
+	m.loading = false
+	m.resp = nil
+	m.setResponseContent("Request cancelled")
+	m.view.GotoTop()
+	m.status = "Request cancelled"
+}
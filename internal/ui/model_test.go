@@ -435,7 +435,7 @@ func TestEnterKeyLoadsItemFromSidebar(t *testing.T) {
 	m.pane = paneSidebar
 
 	// Add a history item to load (uses temp dir)
-	m.addToHistoryAndSave("GET", "https://httpbin.org/get", "", nil)
+	m.addToHistoryAndSave(requestSpec{Method: "GET", URL: "https://httpbin.org/get"})
 
 	// Press enter to load the first item
 	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
@@ -727,16 +727,28 @@ func TestTabCycling(t *testing.T) {
 		t.Errorf("activeTab = %v, want %v", m.activeTab, tabBody)
 	}
 
+	// Next tab: Body -> Auth
+	m.nextTab()
+	if m.activeTab != tabAuth {
+		t.Errorf("activeTab = %v, want %v", m.activeTab, tabAuth)
+	}
+
+	// Next tab: Auth -> Settings
+	m.nextTab()
+	if m.activeTab != tabSettings {
+		t.Errorf("activeTab = %v, want %v", m.activeTab, tabSettings)
+	}
+
 	// Should wrap to overview
 	m.nextTab()
 	if m.activeTab != tabOverview {
 		t.Errorf("activeTab = %v, want %v", m.activeTab, tabOverview)
 	}
 
-	// Prev tab should wrap to body
+	// Prev tab should wrap to settings
 	m.prevTab()
-	if m.activeTab != tabBody {
-		t.Errorf("activeTab = %v, want %v", m.activeTab, tabBody)
+	if m.activeTab != tabSettings {
+		t.Errorf("activeTab = %v, want %v", m.activeTab, tabSettings)
 	}
 }
 
@@ -759,6 +771,8 @@ func TestTabSwitchResetsEditorPart(t *testing.T) {
 	}
 
 	// Switch back to overview tab - should set editorPart to edMethod
+	m.nextTab() // auth
+	m.nextTab() // settings
 	m.nextTab() // overview
 	if m.activeTab != tabOverview {
 		t.Fatalf("activeTab = %v, want %v", m.activeTab, tabOverview)
@@ -0,0 +1,406 @@
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	oauthTokensFileName = "oauth_tokens.json"
+
+	// oauthDefaultRedirect is the callback used for the authorization code
+	// grant when none is configured
+	oauthDefaultRedirect = "http://127.0.0.1:8910/callback"
+
+	// oauthExpiryLeeway renews tokens this long before they expire, so a
+	// token doesn't lapse while the request is in flight
+	oauthExpiryLeeway = 30 * time.Second
+)
+
+// OAuth 2.0 grant types, as persisted
+const (
+	grantClientCredentials = "client_credentials"
+	grantPassword          = "password"
+	grantAuthorizationCode = "authorization_code"
+)
+
+// oauthGrants lists the grants in the order the Auth tab cycles through them
+var oauthGrants = []string{grantClientCredentials, grantPassword, grantAuthorizationCode}
+
+// oauthAuthorizeTimeout bounds how long to wait for the browser callback
+var oauthAuthorizeTimeout = 5 * time.Minute
+
+// openBrowser opens a URL in the user's browser; replaced in tests
+var openBrowser = func(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	return cmd.Start()
+}
+
+// oauthConfig describes how to obtain an OAuth 2.0 access token. The
+// password grant takes its credentials from the auth's username and password.
+type oauthConfig struct {
+	Grant        string `json:"grant,omitempty"`
+	TokenURL     string `json:"token_url,omitempty"`
+	AuthURL      string `json:"auth_url,omitempty"` // authorization code grant only
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	RedirectURL  string `json:"redirect_url,omitempty"` // authorization code grant only
+}
+
+// grantLabel returns the name shown in the Auth tab
+func grantLabel(g string) string {
+	switch g {
+	case grantPassword:
+		return "Password"
+	case grantAuthorizationCode:
+		return "Authorization code (PKCE)"
+	default:
+		return "Client credentials"
+	}
+}
+
+// oauthToken is a cached access token
+type oauthToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"` // zero when the server gave no lifetime
+}
+
+// valid reports whether the token can still be used
+func (t oauthToken) valid() bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(oauthExpiryLeeway).Before(t.Expiry))
+}
+
+// oauthCacheMu serializes access to the token cache file
+var oauthCacheMu sync.Mutex
+
+// oauthCacheKey identifies the token for a configuration, so editing the
+// client or scope fetches a new one
+func oauthCacheKey(a requestAuth) string {
+	o := a.OAuth
+	h := sha256.New()
+	for _, s := range []string{o.Grant, o.TokenURL, o.ClientID, o.Scope, a.Username} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// loadOAuthTokens reads the token cache from disk
+func loadOAuthTokens() (map[string]oauthToken, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, oauthTokensFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]oauthToken{}, nil
+		}
+		return nil, err
+	}
+	tokens := map[string]oauthToken{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// saveOAuthTokens writes the token cache to disk, readable only by the user
+func saveOAuthTokens(tokens map[string]oauthToken) error {
+	dir, err := getDataDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, oauthTokensFileName), data, 0600)
+}
+
+// cachedOAuthToken returns the cached token for an auth configuration
+func cachedOAuthToken(a requestAuth) (oauthToken, bool) {
+	oauthCacheMu.Lock()
+	defer oauthCacheMu.Unlock()
+	tokens, err := loadOAuthTokens()
+	if err != nil {
+		return oauthToken{}, false
+	}
+	t, ok := tokens[oauthCacheKey(a)]
+	return t, ok
+}
+
+// storeOAuthToken caches a token, or forgets it when the token is empty
+func storeOAuthToken(a requestAuth, t oauthToken) error {
+	oauthCacheMu.Lock()
+	defer oauthCacheMu.Unlock()
+	tokens, err := loadOAuthTokens()
+	if err != nil {
+		tokens = map[string]oauthToken{} // Replace an unreadable cache
+	}
+	if t.AccessToken == "" {
+		delete(tokens, oauthCacheKey(a))
+	} else {
+		tokens[oauthCacheKey(a)] = t
+	}
+	return saveOAuthTokens(tokens)
+}
+
+// resolveOAuth returns the auth as a Bearer token, using the cached token
+// while it is valid, refreshing it when it has expired and otherwise running
+// the configured grant
+func resolveOAuth(ctx context.Context, a requestAuth, client *http.Client) (requestAuth, error) {
+	if a.Type != authOAuth2 {
+		return a, nil
+	}
+	cached, ok := cachedOAuthToken(a)
+	token := cached
+	if !ok || !cached.valid() {
+		var err error
+		token, err = fetchOAuthToken(ctx, a, cached, client)
+		if err != nil {
+			return a, fmt.Errorf("oauth: %w", err)
+		}
+		_ = storeOAuthToken(a, token) // Ignore error, caching is best-effort
+	}
+	return requestAuth{Type: authBearer, Token: token.AccessToken}, nil
+}
+
+// fetchOAuthToken obtains a new token, trying the refresh token first
+func fetchOAuthToken(ctx context.Context, a requestAuth, cached oauthToken, client *http.Client) (oauthToken, error) {
+	o := a.OAuth
+	if o.TokenURL == "" {
+		return oauthToken{}, errors.New("token URL is required")
+	}
+
+	if cached.RefreshToken != "" {
+		form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {cached.RefreshToken}}
+		if o.Scope != "" {
+			form.Set("scope", o.Scope)
+		}
+		if t, err := requestOAuthToken(ctx, o, form, client); err == nil {
+			if t.RefreshToken == "" {
+				t.RefreshToken = cached.RefreshToken // The server kept the old one
+			}
+			return t, nil
+		}
+		// The refresh token was rejected; fall back to the full grant
+	}
+
+	form := url.Values{"grant_type": {o.Grant}}
+	if o.Grant == "" {
+		form.Set("grant_type", grantClientCredentials)
+	}
+	if o.Scope != "" {
+		form.Set("scope", o.Scope)
+	}
+	switch o.Grant {
+	case grantPassword:
+		form.Set("username", a.Username)
+		form.Set("password", a.Password)
+	case grantAuthorizationCode:
+		code, verifier, redirect, err := authorizeInBrowser(ctx, o)
+		if err != nil {
+			return oauthToken{}, err
+		}
+		form.Set("code", code)
+		form.Set("code_verifier", verifier)
+		form.Set("redirect_uri", redirect)
+	}
+	return requestOAuthToken(ctx, o, form, client)
+}
+
+// requestOAuthToken posts a grant to the token endpoint. Confidential clients
+// authenticate with HTTP Basic; public clients send only their ID.
+func requestOAuthToken(ctx context.Context, o oauthConfig, form url.Values, client *http.Client) (oauthToken, error) {
+	if o.ClientSecret == "" {
+		form.Set("client_id", o.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", ensureScheme(o.TokenURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return oauthToken{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return oauthToken{}, err
+	}
+
+	var tr struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	jsonErr := json.Unmarshal(body, &tr)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || tr.Error != "" {
+		switch {
+		case tr.ErrorDescription != "":
+			return oauthToken{}, fmt.Errorf("token endpoint: %s: %s", tr.Error, tr.ErrorDescription)
+		case tr.Error != "":
+			return oauthToken{}, fmt.Errorf("token endpoint: %s", tr.Error)
+		}
+		return oauthToken{}, fmt.Errorf("token endpoint: %s", resp.Status)
+	}
+	if jsonErr != nil {
+		return oauthToken{}, fmt.Errorf("token endpoint: %w", jsonErr)
+	}
+	if tr.AccessToken == "" {
+		return oauthToken{}, errors.New("token endpoint: response has no access_token")
+	}
+
+	t := oauthToken{AccessToken: tr.AccessToken, RefreshToken: tr.RefreshToken}
+	if tr.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback address,
+// so the callback listener can't be reached from other machines
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authorizeInBrowser runs the authorization code flow with PKCE: it opens
+// the authorization page and waits for the redirect on a local listener.
+// It returns the code, the PKCE verifier and the redirect URI used.
+func authorizeInBrowser(ctx context.Context, o oauthConfig) (code, verifier, redirect string, err error) {
+	if o.AuthURL == "" {
+		return "", "", "", errors.New("authorization URL is required")
+	}
+	redirect = o.RedirectURL
+	if redirect == "" {
+		redirect = oauthDefaultRedirect
+	}
+	ru, err := url.Parse(redirect)
+	if err != nil || ru.Scheme != "http" || !isLoopbackHost(ru.Hostname()) {
+		return "", "", "", fmt.Errorf("redirect URL must be a local http URL, got %q", redirect)
+	}
+
+	verifier = randomURLSafe(32)
+	state := randomURLSafe(16)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	ln, err := net.Listen("tcp", ru.Host)
+	if err != nil {
+		return "", "", "", fmt.Errorf("callback listener: %w", err)
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	path := ru.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("authorization callback has the wrong state")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("authorization callback has no code")
+		default:
+			res.code = q.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			_, _ = io.WriteString(w, "Authorization complete. You can close this tab and return to getboy.")
+		}
+		select {
+		case done <- res:
+		default: // Only the first callback counts
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.ClientID},
+		"redirect_uri":          {redirect},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	if o.Scope != "" {
+		params.Set("scope", o.Scope)
+	}
+	authURL := ensureScheme(o.AuthURL)
+	sep := "?"
+	if strings.Contains(authURL, "?") {
+		sep = "&"
+	}
+	authURL += sep + params.Encode()
+	if err := openBrowser(authURL); err != nil {
+		return "", "", "", fmt.Errorf("open browser: %w (visit %s)", err, authURL)
+	}
+
+	select {
+	case res := <-done:
+		return res.code, verifier, redirect, res.err
+	case <-ctx.Done():
+		return "", "", "", ctx.Err()
+	case <-time.After(oauthAuthorizeTimeout):
+		return "", "", "", errors.New("timed out waiting for authorization in the browser")
+	}
+}
+
+// randomURLSafe returns n random bytes encoded for use in URLs
+func randomURLSafe(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b) // crypto/rand never fails on supported platforms
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,285 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tokenServer is a stand-in OAuth 2.0 token endpoint that records the grants it sees
+type tokenServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	forms  []map[string]string
+	reply  func(form map[string]string) (int, string)
+	client string // user:password of the last HTTP Basic client authentication
+}
+
+// grants returns the grant types requested so far
+func (s *tokenServer) grants() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, f := range s.forms {
+		out = append(out, f["grant_type"])
+	}
+	return out
+}
+
+func newTokenServer(t *testing.T, reply func(form map[string]string) (int, string)) *tokenServer {
+	s := &tokenServer{reply: reply}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		form := map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		s.mu.Lock()
+		s.forms = append(s.forms, form)
+		if u, p, ok := r.BasicAuth(); ok {
+			s.client = u + ":" + p
+		}
+		s.mu.Unlock()
+		status, body := s.reply(form)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newBearerServer returns an API server that echoes the bearer token it receives
+func newBearerServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestOAuthClientCredentials tests fetching, injecting and caching a token
+func TestOAuthClientCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tokens := newTokenServer(t, func(form map[string]string) (int, string) {
+		return 200, `{"access_token":"tok-1","token_type":"Bearer","expires_in":3600}`
+	})
+	api := newBearerServer(t)
+
+	spec := requestSpec{Method: "GET", URL: api.URL, Auth: requestAuth{Type: authOAuth2, OAuth: oauthConfig{
+		Grant:        grantClientCredentials,
+		TokenURL:     "${AUTH}/token",
+		ClientID:     "cli",
+		ClientSecret: "s3cret",
+		Scope:        "read write",
+	}}}
+	vars := map[string]string{"AUTH": tokens.URL}
+
+	for range 2 {
+		msg := doHTTP(spec, vars)().(httpDoneMsg)
+		if msg.Err != nil {
+			t.Fatalf("request failed: %v", msg.Err)
+		}
+		if msg.Body != "tok-1" {
+			t.Errorf("API saw token %q, want tok-1", msg.Body)
+		}
+	}
+
+	if got := tokens.grants(); len(got) != 1 || got[0] != grantClientCredentials {
+		t.Errorf("token requests = %v, want one client_credentials grant", got)
+	}
+	if tokens.forms[0]["scope"] != "read write" || tokens.client != "cli:s3cret" {
+		t.Errorf("token request form = %v, client = %q", tokens.forms[0], tokens.client)
+	}
+
+	info, err := os.Stat(filepath.Join(home, ".getboy", oauthTokensFileName))
+	if err != nil {
+		t.Fatalf("token cache not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token cache mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+// TestOAuthRefresh tests renewing an expiring token with its refresh token
+func TestOAuthRefresh(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tokens := newTokenServer(t, func(form map[string]string) (int, string) {
+		if form["grant_type"] == "refresh_token" {
+			if form["refresh_token"] != "r1" {
+				return 400, `{"error":"invalid_grant"}`
+			}
+			return 200, `{"access_token":"tok-2","expires_in":3600}`
+		}
+		// Expires within the leeway, so the next send refreshes it
+		return 200, `{"access_token":"tok-1","refresh_token":"r1","expires_in":5}`
+	})
+	api := newBearerServer(t)
+
+	spec := requestSpec{Method: "GET", URL: api.URL, Auth: requestAuth{
+		Type:     authOAuth2,
+		Username: "alice",
+		Password: "pw",
+		OAuth:    oauthConfig{Grant: grantPassword, TokenURL: tokens.URL, ClientID: "public"},
+	}}
+
+	var got []string
+	for range 2 {
+		msg := doHTTP(spec, nil)().(httpDoneMsg)
+		if msg.Err != nil {
+			t.Fatalf("request failed: %v", msg.Err)
+		}
+		got = append(got, msg.Body)
+	}
+	if strings.Join(got, ",") != "tok-1,tok-2" {
+		t.Errorf("tokens used = %v, want tok-1 then the refreshed tok-2", got)
+	}
+	if g := tokens.grants(); strings.Join(g, ",") != "password,refresh_token" {
+		t.Errorf("grants = %v", g)
+	}
+	first := tokens.forms[0]
+	if first["username"] != "alice" || first["password"] != "pw" || first["client_id"] != "public" {
+		t.Errorf("password grant form = %v", first)
+	}
+
+	cached, _ := cachedOAuthToken(spec.Auth)
+	if cached.RefreshToken != "r1" {
+		t.Error("the refresh token should be kept when the server doesn't rotate it")
+	}
+}
+
+// TestOAuthTokenError tests that token endpoint errors are reported
+func TestOAuthTokenError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tokens := newTokenServer(t, func(map[string]string) (int, string) {
+		return 401, `{"error":"invalid_client","error_description":"unknown client"}`
+	})
+	spec := requestSpec{Method: "GET", URL: "http://127.0.0.1:1", Auth: requestAuth{Type: authOAuth2, OAuth: oauthConfig{TokenURL: tokens.URL}}}
+
+	msg := doHTTP(spec, nil)().(httpDoneMsg)
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "invalid_client: unknown client") {
+		t.Errorf("error = %v, want the token endpoint's error", msg.Err)
+	}
+}
+
+// TestOAuthAuthorizationCode tests the PKCE flow through a local callback
+func TestOAuthAuthorizationCode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Pick a free port for the callback listener
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirect := "http://" + ln.Addr().String() + "/cb"
+	_ = ln.Close()
+
+	var challenge string
+	authorize := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		challenge = q.Get("code_challenge")
+		if q.Get("code_challenge_method") != "S256" || q.Get("redirect_uri") != redirect || q.Get("client_id") != "app" {
+			http.Error(w, "bad authorization request", http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, redirect+"?code=c0de&state="+q.Get("state"), http.StatusFound)
+	}))
+	defer authorize.Close()
+
+	tokens := newTokenServer(t, func(form map[string]string) (int, string) {
+		sum := sha256.Sum256([]byte(form["code_verifier"]))
+		if form["code"] != "c0de" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			return 400, `{"error":"invalid_grant"}`
+		}
+		return 200, `{"access_token":"tok-code","expires_in":3600}`
+	})
+	api := newBearerServer(t)
+
+	// The "browser" follows the authorization redirect to the callback
+	orig := openBrowser
+	openBrowser = func(u string) error {
+		go func() {
+			if resp, err := http.Get(u); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	defer func() { openBrowser = orig }()
+
+	spec := requestSpec{Method: "GET", URL: api.URL, Auth: requestAuth{Type: authOAuth2, OAuth: oauthConfig{
+		Grant:       grantAuthorizationCode,
+		AuthURL:     authorize.URL + "/authorize",
+		TokenURL:    tokens.URL,
+		ClientID:    "app",
+		RedirectURL: redirect,
+	}}}
+	msg := doHTTP(spec, nil)().(httpDoneMsg)
+	if msg.Err != nil {
+		t.Fatalf("request failed: %v", msg.Err)
+	}
+	if msg.Body != "tok-code" {
+		t.Errorf("API saw token %q, want tok-code", msg.Body)
+	}
+
+	// The callback must not listen where other machines can reach it
+	for _, redirect := range []string{"http://0.0.0.0:8910/cb", "http://192.168.1.5:8910/cb", "http://example.com/cb"} {
+		o := spec.Auth.OAuth
+		o.RedirectURL = redirect
+		if _, _, _, err := authorizeInBrowser(context.Background(), o); err == nil || !strings.Contains(err.Error(), "must be a local http URL") {
+			t.Errorf("redirect %s: error = %v, want it rejected", redirect, err)
+		}
+	}
+}
+
+// TestOAuthAuthTab tests editing OAuth settings and forgetting the cached token
+func TestOAuthAuthTab(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.activeTab = tabAuth
+	m.resetEditorPartForTab()
+	m.setAuth(requestAuth{Type: authOAuth2, OAuth: oauthConfig{TokenURL: "https://a.com/token", ClientID: "cli"}})
+
+	if rows := m.authRows(); len(rows) != 6 || rows[1] != authFieldGrant {
+		t.Fatalf("client credentials rows = %v", rows)
+	}
+	m.authIdx = 1
+	m.cycleAuthField(authFieldGrant)
+	if m.oauthGrant != grantPassword || len(m.authRows()) != 8 {
+		t.Errorf("grant = %q with %d rows, want password with username and password", m.oauthGrant, len(m.authRows()))
+	}
+
+	a := m.currentRequest().Auth
+	if err := storeOAuthToken(a, oauthToken{AccessToken: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(model)
+	if _, ok := cachedOAuthToken(a); ok {
+		t.Error("r should forget the cached token")
+	}
+
+	data, _ := json.Marshal(a)
+	var back requestAuth
+	_ = json.Unmarshal(data, &back)
+	if back != a {
+		t.Errorf("auth did not survive JSON: %+v", back)
+	}
+}
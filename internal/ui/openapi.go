@@ -0,0 +1,572 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// baseURLVar is the variable a relative server URL is prefixed with
+const baseURLVar = "${baseUrl}"
+
+// openAPIMethods are the operations of a path item, in the order they are imported
+var openAPIMethods = []string{"get", "put", "post", "patch", "delete", "head", "options", "trace"}
+
+// pathTemplatePattern matches {name} parameters in a path template
+var pathTemplatePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// openAPIDoc is a decoded OpenAPI 3 or Swagger 2 document
+type openAPIDoc struct {
+	root    map[string]any
+	swagger bool // Swagger 2.0 rather than OpenAPI 3
+}
+
+// isOpenAPI reports whether a decoded document is an OpenAPI or Swagger description
+func isOpenAPI(root map[string]any) bool {
+	return root["openapi"] != nil || root["swagger"] != nil
+}
+
+// importOpenAPI converts an OpenAPI 3 or Swagger 2 document into a
+// collection with a request per operation, in a folder per tag. name is
+// used when the document has no title.
+func importOpenAPI(root map[string]any, name string) (*collection, error) {
+	d := openAPIDoc{root: root, swagger: root["swagger"] != nil}
+	if title := jsonString(jsonObject(root["info"])["title"]); title != "" {
+		name = title
+	}
+	c := &collection{folder: folder{Name: importName(name)}}
+
+	paths := jsonObject(root["paths"])
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		item := d.resolve(paths[path])
+		for _, method := range openAPIMethods {
+			op := jsonObject(item[method])
+			if op == nil {
+				continue
+			}
+			f := &c.folder
+			if tags := jsonArray(op["tags"]); len(tags) > 0 && jsonString(tags[0]) != "" {
+				f = f.ensureFolder(importName(jsonString(tags[0])))
+			}
+			req := savedRequest{Name: d.operationName(method, path, op), requestSpec: d.request(method, path, item, op)}
+			req.Name = uniqueName(req.Name, func(n string) bool { return f.requestIndex(n) >= 0 })
+			f.Requests = append(f.Requests, req)
+		}
+	}
+	if len(c.Requests) == 0 && len(c.Folders) == 0 {
+		return nil, fmt.Errorf("the document has no operations")
+	}
+	return c, nil
+}
+
+// operationName names the request for an operation after its summary or
+// ID, falling back to its method and path
+func (d openAPIDoc) operationName(method, path string, op map[string]any) string {
+	name := cmp.Or(jsonString(op["summary"]), jsonString(op["operationId"]))
+	if name == "" {
+		name = strings.ToUpper(method) + " " + path
+	}
+	return importName(name)
+}
+
+// request builds the request for an operation
+func (d openAPIDoc) request(method, path string, item, op map[string]any) requestSpec {
+	spec := requestSpec{
+		Method: strings.ToUpper(method),
+		URL:    d.baseURL(item, op) + pathTemplatePattern.ReplaceAllString(path, "$${$1}"),
+	}
+
+	var query []queryPair
+	var form []map[string]any
+	for _, p := range d.parameters(item, op) {
+		name := jsonString(p["name"])
+		switch jsonString(p["in"]) {
+		case "query":
+			values := paramValues(d.paramExample(p))
+			if len(values) == 0 {
+				values = []string{""}
+			}
+			for _, v := range values {
+				query = append(query, queryPair{Key: name, Value: v})
+			}
+		case "header":
+			// Content-Type, Accept and Authorization are described elsewhere
+			switch strings.ToLower(name) {
+			case "content-type", "accept", "authorization":
+				continue
+			}
+			if p["required"] == true {
+				if spec.Headers == nil {
+					spec.Headers = map[string]string{}
+				}
+				spec.Headers[name] = strings.Join(paramValues(d.paramExample(p)), ",")
+			}
+		case "body":
+			d.setBody(&spec, d.consumes(op, "application/json"), map[string]any{"schema": p["schema"]})
+		case "formData":
+			form = append(form, p)
+		}
+	}
+	if len(query) > 0 {
+		spec.URL += "?" + encodeQuery(query)
+	}
+	if len(form) > 0 {
+		d.setSwaggerForm(&spec, op, form)
+	}
+
+	if body := d.resolve(op["requestBody"]); body != nil {
+		content := jsonObject(body["content"])
+		if mediaType := preferredMediaType(slices.Collect(maps.Keys(content))); mediaType != "" {
+			d.setBody(&spec, mediaType, d.resolve(content[mediaType]))
+		}
+	}
+	return spec
+}
+
+// baseURL returns the URL operations are relative to, from the operation's,
+// path's or document's first server, or the Swagger host and base path.
+// A relative URL is prefixed with the ${baseUrl} variable.
+func (d openAPIDoc) baseURL(item, op map[string]any) string {
+	var base string
+	if d.swagger {
+		base = jsonString(d.root["basePath"])
+		if host := jsonString(d.root["host"]); host != "" {
+			scheme := "https"
+			if schemes := jsonArray(d.root["schemes"]); len(schemes) > 0 && !slices.Contains(schemes, any("https")) {
+				scheme = jsonString(schemes[0])
+			}
+			base = scheme + "://" + host + base
+		}
+	} else {
+		for _, servers := range [][]any{jsonArray(op["servers"]), jsonArray(item["servers"]), jsonArray(d.root["servers"])} {
+			if len(servers) == 0 {
+				continue
+			}
+			server := jsonObject(servers[0])
+			vars := jsonObject(server["variables"])
+			base = pathTemplatePattern.ReplaceAllStringFunc(jsonString(server["url"]), func(match string) string {
+				if def := jsonString(jsonObject(vars[match[1:len(match)-1]])["default"]); def != "" {
+					return def
+				}
+				return "$" + match
+			})
+			break
+		}
+	}
+	base = strings.TrimRight(base, "/")
+	if !strings.Contains(base, "://") && !strings.HasPrefix(base, "${") {
+		base = baseURLVar + base
+	}
+	return base
+}
+
+// parameters returns the path's and operation's parameters, those of the
+// operation overriding path ones with the same name and location
+func (d openAPIDoc) parameters(item, op map[string]any) []map[string]any {
+	var params []map[string]any
+	index := map[string]int{}
+	for _, list := range [][]any{jsonArray(item["parameters"]), jsonArray(op["parameters"])} {
+		for _, raw := range list {
+			p := d.resolve(raw)
+			if p == nil {
+				continue
+			}
+			key := jsonString(p["in"]) + ":" + jsonString(p["name"])
+			if i, ok := index[key]; ok {
+				params[i] = p
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// paramExample returns a parameter's example, default or first allowed
+// value, or nil if it has none
+func (d openAPIDoc) paramExample(p map[string]any) any {
+	if v, ok := p["example"]; ok {
+		return v
+	}
+	if v, ok := d.firstExample(p["examples"]); ok {
+		return v
+	}
+	schema := d.resolve(p["schema"])
+	if d.swagger && schema == nil {
+		schema = p // Swagger describes non-body parameters inline
+	}
+	v, _ := schemaHint(schema)
+	return v
+}
+
+// firstExample returns the value of the first entry of an OpenAPI 3
+// examples map
+func (d openAPIDoc) firstExample(examples any) (any, bool) {
+	m := jsonObject(examples)
+	if len(m) == 0 {
+		return nil, false
+	}
+	first := d.resolve(m[slices.Sorted(maps.Keys(m))[0]])
+	v, ok := first["value"]
+	return v, ok
+}
+
+// consumes returns the media type a Swagger operation sends, preferring JSON
+func (d openAPIDoc) consumes(op map[string]any, fallback string) string {
+	types := jsonArray(op["consumes"])
+	if len(types) == 0 {
+		types = jsonArray(d.root["consumes"])
+	}
+	var names []string
+	for _, t := range types {
+		names = append(names, jsonString(t))
+	}
+	return cmp.Or(preferredMediaType(names), fallback)
+}
+
+// setSwaggerForm sets a form body from Swagger formData parameters
+func (d openAPIDoc) setSwaggerForm(spec *requestSpec, op map[string]any, params []map[string]any) {
+	mediaType := d.consumes(op, "application/x-www-form-urlencoded")
+	properties := map[string]any{}
+	for _, p := range params {
+		schema := maps.Clone(p)
+		if jsonString(p["type"]) == "file" {
+			schema = map[string]any{"type": "string", "format": "binary"}
+			mediaType = "multipart/form-data"
+		}
+		if v := d.paramExample(p); v != nil {
+			schema["example"] = v
+		}
+		properties[jsonString(p["name"])] = schema
+	}
+	if !isFormMediaType(baseMediaType(mediaType)) {
+		mediaType = "application/x-www-form-urlencoded"
+	}
+	d.setBody(spec, mediaType, map[string]any{"schema": map[string]any{"type": "object", "properties": properties}})
+}
+
+// preferredMediaType picks the media type to send a body as: JSON, then
+// forms, then anything else, in a stable order
+func preferredMediaType(types []string) string {
+	slices.Sort(types)
+	rank := func(t string) int {
+		switch t := baseMediaType(t); {
+		case isJSONMediaType(t):
+			return 0
+		case isFormMediaType(t):
+			return 1
+		case isTextMediaType(t):
+			return 2
+		}
+		return 3
+	}
+	best := ""
+	for _, t := range types {
+		if best == "" || rank(t) < rank(best) {
+			best = t
+		}
+	}
+	return best
+}
+
+// baseMediaType returns a media type without its parameters, so that
+// "application/json; charset=utf-8" matches as JSON
+func baseMediaType(t string) string {
+	mediaType, _, err := mime.ParseMediaType(t)
+	if mediaType == "" && err != nil {
+		return strings.ToLower(strings.TrimSpace(t))
+	}
+	return mediaType
+}
+
+// isJSONMediaType reports whether a media type is JSON
+func isJSONMediaType(t string) bool {
+	return t == "application/json" || strings.HasSuffix(t, "+json")
+}
+
+// isFormMediaType reports whether a media type is a URL-encoded or multipart form
+func isFormMediaType(t string) bool {
+	return t == "application/x-www-form-urlencoded" || t == "multipart/form-data"
+}
+
+// setBody sets the body of a request from a media type object, using its
+// example or one generated from its schema. mediaType may have parameters,
+// which are kept in the Content-Type header.
+func (d openAPIDoc) setBody(spec *requestSpec, mediaType string, media map[string]any) {
+	example, ok := media["example"]
+	if !ok {
+		example, ok = d.firstExample(media["examples"])
+	}
+	if !ok {
+		example = d.exampleValue(media["schema"], nil)
+	}
+
+	base := baseMediaType(mediaType)
+	switch {
+	case isJSONMediaType(base):
+		spec.BodyType = bodyJSON
+		if s, isString := example.(string); isString {
+			spec.Body = s
+		} else if example != nil {
+			b, _ := json.MarshalIndent(example, "", "  ")
+			spec.Body = string(b)
+		}
+	case isFormMediaType(base):
+		spec.BodyType = bodyURLEncoded
+		if base == "multipart/form-data" {
+			spec.BodyType = bodyMultipart
+		}
+		properties := d.properties(media["schema"])
+		fields := jsonObject(example)
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			field := formField{Key: key, Value: strings.Join(paramValues(fields[key]), ",")}
+			if spec.BodyType == bodyMultipart && isBinarySchema(d.resolve(properties[key])) {
+				field = formField{Key: key, File: true}
+			}
+			spec.Form = append(spec.Form, field)
+		}
+	case base == "application/xml" || strings.HasSuffix(base, "+xml"):
+		spec.BodyType = bodyXML
+		spec.Body, _ = example.(string)
+	case isTextMediaType(base):
+		spec.BodyType = bodyText
+		spec.Body = strings.Join(paramValues(example), ",")
+	default:
+		spec.BodyType = bodyBinary
+	}
+
+	// The body type implies the usual Content-Type; keep any other one
+	if mediaType != "" && mediaType != rawContentType(spec.BodyType) && !isFormMediaType(base) && base != "application/octet-stream" {
+		if spec.Headers == nil {
+			spec.Headers = map[string]string{}
+		}
+		spec.Headers["Content-Type"] = mediaType
+	}
+}
+
+// properties returns the properties of an object schema, including those
+// it inherits through allOf
+func (d openAPIDoc) properties(schema any) map[string]any {
+	s := d.resolve(schema)
+	props := maps.Clone(jsonObject(s["properties"]))
+	if props == nil {
+		props = map[string]any{}
+	}
+	for _, sub := range jsonArray(s["allOf"]) {
+		for k, v := range d.properties(sub) {
+			if _, ok := props[k]; !ok {
+				props[k] = v
+			}
+		}
+	}
+	return props
+}
+
+// isBinarySchema reports whether a schema describes file contents
+func isBinarySchema(s map[string]any) bool {
+	return schemaType(s) == "string" && (s["format"] == "binary" || s["format"] == "byte" || s["contentMediaType"] != nil)
+}
+
+// exampleValue generates an example value for a schema. refs holds the
+// references being expanded, so recursive schemas stop instead of looping.
+func (d openAPIDoc) exampleValue(schema any, refs []string) any {
+	s := jsonObject(schema)
+	if ref := jsonString(s["$ref"]); ref != "" {
+		if slices.Contains(refs, ref) {
+			return nil
+		}
+		return d.exampleValue(d.lookup(ref), append(refs, ref))
+	}
+	if s == nil {
+		return nil
+	}
+	if v, ok := schemaHint(s); ok {
+		return v
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alts := jsonArray(s[key]); len(alts) > 0 {
+			return d.exampleValue(alts[0], refs)
+		}
+	}
+	if all := jsonArray(s["allOf"]); len(all) > 0 {
+		merged := map[string]any{}
+		for _, sub := range all {
+			if m, ok := d.exampleValue(sub, refs).(map[string]any); ok {
+				maps.Copy(merged, m)
+			}
+		}
+		if s["properties"] != nil {
+			maps.Copy(merged, d.objectExample(s, refs))
+		}
+		return merged
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return d.objectExample(s, refs)
+	case "array":
+		if item := d.exampleValue(s["items"], refs); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case "integer", "number":
+		return json.Number("0")
+	case "boolean":
+		return false
+	case "string":
+		return stringExample(jsonString(s["format"]))
+	}
+	return nil
+}
+
+// objectExample generates an example object from a schema's properties,
+// leaving out those only sent by the server
+func (d openAPIDoc) objectExample(s map[string]any, refs []string) map[string]any {
+	out := map[string]any{}
+	for name, prop := range jsonObject(s["properties"]) {
+		if d.resolve(prop)["readOnly"] == true {
+			continue
+		}
+		out[name] = d.exampleValue(prop, refs)
+	}
+	return out
+}
+
+// schemaHint returns a schema's example, default or first allowed value
+func schemaHint(s map[string]any) (any, bool) {
+	for _, key := range []string{"example", "default", "const"} {
+		if v, ok := s[key]; ok {
+			return v, true
+		}
+	}
+	if examples := jsonArray(s["examples"]); len(examples) > 0 {
+		return examples[0], true
+	}
+	if enum := jsonArray(s["enum"]); len(enum) > 0 {
+		return enum[0], true
+	}
+	return nil, false
+}
+
+// schemaType returns the type of a schema, inferring it from the keywords
+// used when it isn't given
+func schemaType(s map[string]any) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []any:
+		// OpenAPI 3.1 allows a list of types such as [string, "null"]
+		for _, v := range t {
+			if v != "null" {
+				return jsonString(v)
+			}
+		}
+	}
+	switch {
+	case s["properties"] != nil || s["additionalProperties"] != nil:
+		return "object"
+	case s["items"] != nil:
+		return "array"
+	}
+	return ""
+}
+
+// stringExample returns a placeholder for a string of the given format
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "binary", "byte":
+		return ""
+	}
+	return "string"
+}
+
+// paramValues formats an example as parameter values, one per element of a list
+func paramValues(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			values = append(values, paramValues(item)...)
+		}
+		return values
+	case map[string]any:
+		b, _ := json.Marshal(v)
+		return []string{string(b)}
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+// resolve follows local $ref pointers and returns the object they lead to
+func (d openAPIDoc) resolve(v any) map[string]any {
+	m := jsonObject(v)
+	for range 16 {
+		ref := jsonString(m["$ref"])
+		if ref == "" {
+			return m
+		}
+		m = jsonObject(d.lookup(ref))
+	}
+	return nil
+}
+
+// lookup returns the value a local JSON pointer reference such as
+// #/components/schemas/Pet points to. External references are not followed.
+func (d openAPIDoc) lookup(ref string) any {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+	var v any = d.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[token]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// jsonObject returns v as a decoded JSON object, or nil
+func jsonObject(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+// jsonArray returns v as a decoded JSON array, or nil
+func jsonArray(v any) []any {
+	a, _ := v.([]any)
+	return a
+}
+
+// jsonString returns v as a string, or "" if it isn't one
+func jsonString(v any) string {
+	s, _ := v.(string)
+	return s
+}
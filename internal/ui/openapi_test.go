@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const openAPITestSpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: "1.0"
+servers:
+  - url: https://{env}.example.com/v1/
+    variables:
+      env:
+        default: api
+paths:
+  /pets:
+    get:
+      summary: List pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, default: 20}
+        - name: status
+          in: query
+          example: [available, sold]
+        - $ref: '#/components/parameters/Tenant'
+        - name: X-Trace
+          in: header
+          schema: {type: string}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/xml:
+            schema: {$ref: '#/components/schemas/Pet'}
+          application/json; charset=utf-8:
+            schema: {$ref: '#/components/schemas/Pet'}
+  /pets/{petId}/photo:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+    put:
+      tags: [photos]
+      requestBody:
+        content:
+          multipart/form-data; boundary=x:
+            schema:
+              type: object
+              properties:
+                caption: {type: string, example: Rex}
+                file: {type: string, format: binary}
+  /health:
+    get: {}
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      required: true
+      example: acme
+  schemas:
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          required: [kind]
+          properties:
+            id: {type: integer, readOnly: true}
+            kind: {type: string, enum: [dog, cat]}
+            born: {type: string, format: date, example: 2024-05-01}
+            owner: {$ref: '#/components/schemas/Person'}
+    Named:
+      properties:
+        name: {type: string}
+    Person:
+      type: object
+      properties:
+        email: {type: string, format: email}
+        friends:
+          type: array
+          items: {$ref: '#/components/schemas/Person'}
+`
+
+const swaggerTestSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy/API"},
+  "host": "legacy.example.com",
+  "basePath": "/api",
+  "schemes": ["http"],
+  "consumes": ["application/json"],
+  "definitions": {
+    "Order": {"type": "object", "properties": {"qty": {"type": "integer", "example": 2}}}
+  },
+  "paths": {
+    "/orders": {
+      "post": {
+        "parameters": [
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Order"}},
+          {"name": "dryRun", "in": "query", "type": "boolean", "default": false}
+        ]
+      }
+    },
+    "/orders/{id}/receipt": {
+      "post": {
+        "summary": "Upload receipt",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "note", "in": "formData", "type": "string"},
+          {"name": "scan", "in": "formData", "type": "file"}
+        ]
+      }
+    }
+  }
+}`
+
+// writeSpec writes a spec to a temporary file and returns its path
+func writeSpec(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// importedRequest looks up a request in an imported collection
+func importedRequest(t *testing.T, c *collection, path ...string) requestSpec {
+	t.Helper()
+	req, ok := lookupRequest([]*collection{c}, append([]string{c.Name}, path...))
+	if !ok {
+		b, _ := json.MarshalIndent(c.folder, "", "  ")
+		t.Fatalf("no request %v in\n%s", path, b)
+	}
+	return req.requestSpec
+}
+
+// TestImportOpenAPI tests converting an OpenAPI 3 document into a collection
+func TestImportOpenAPI(t *testing.T) {
+	c, _, err := importFile(writeSpec(t, "petstore.yaml", openAPITestSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "Pet Store" {
+		t.Errorf("name = %q, want the title", c.Name)
+	}
+
+	list := importedRequest(t, c, "pets", "List pets")
+	if list.Method != "GET" || list.URL != "https://api.example.com/v1/pets?limit=20&status=available&status=sold" {
+		t.Errorf("list = %s %s", list.Method, list.URL)
+	}
+	if len(list.Headers) != 1 || list.Headers["X-Tenant"] != "acme" {
+		t.Errorf("only required headers should be set, got %v", list.Headers)
+	}
+
+	create := importedRequest(t, c, "pets", "createPet")
+	if create.BodyType != bodyJSON || create.Headers["Content-Type"] != "application/json; charset=utf-8" {
+		t.Errorf("JSON with parameters should be preferred, got body type %q headers %v", create.BodyType, create.Headers)
+	}
+	want := `{
+  "born": "2024-05-01",
+  "kind": "dog",
+  "name": "string",
+  "owner": {
+    "email": "user@example.com",
+    "friends": []
+  }
+}`
+	if create.Body != want {
+		t.Errorf("body =\n%s\nwant\n%s", create.Body, want)
+	}
+
+	photo := importedRequest(t, c, "photos", "PUT pets {petId} photo")
+	if photo.URL != "https://api.example.com/v1/pets/${petId}/photo" || photo.BodyType != bodyMultipart || photo.Headers != nil {
+		t.Errorf("photo = %s body type %q headers %v", photo.URL, photo.BodyType, photo.Headers)
+	}
+	if want := []formField{{Key: "caption", Value: "Rex"}, {Key: "file", File: true}}; len(photo.Form) != 2 || photo.Form[0] != want[0] || photo.Form[1] != want[1] {
+		t.Errorf("form = %+v, want %+v", photo.Form, want)
+	}
+
+	health := importedRequest(t, c, "GET health")
+	if health.URL != "https://api.example.com/v1/health" || health.hasBody() {
+		t.Errorf("untagged operations belong at the top, got %+v", health)
+	}
+}
+
+// TestImportSwagger tests converting a Swagger 2 document into a collection
+func TestImportSwagger(t *testing.T) {
+	c, _, err := importFile(writeSpec(t, "legacy.json", swaggerTestSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "Legacy API" {
+		t.Errorf("name = %q, slashes should be replaced", c.Name)
+	}
+
+	order := importedRequest(t, c, "POST orders")
+	if order.URL != "http://legacy.example.com/api/orders?dryRun=false" || order.Body != "{\n  \"qty\": 2\n}" {
+		t.Errorf("order = %s\n%s", order.URL, order.Body)
+	}
+
+	receipt := importedRequest(t, c, "Upload receipt")
+	if receipt.URL != "http://legacy.example.com/api/orders/${id}/receipt" || receipt.BodyType != bodyMultipart {
+		t.Errorf("receipt = %s body type %q", receipt.URL, receipt.BodyType)
+	}
+	if len(receipt.Form) != 2 || receipt.Form[0].Key != "note" || !receipt.Form[1].File {
+		t.Errorf("form = %+v", receipt.Form)
+	}
+
+	for _, tt := range []struct{ name, content, want string }{
+		{"notes.yaml", "title: not an API\n", "not an OpenAPI"},
+		{"empty.json", `{"openapi": "3.1.0", "paths": {}}`, "no operations"},
+		{"broken.yaml", "openapi: [3", "broken.yaml"},
+	} {
+		if _, _, err := importFile(writeSpec(t, tt.name, tt.content)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("importFile(%s) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// TestImportCollectionPrompt tests importing from the Saved tab
+func TestImportCollectionPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := writeSpec(t, "petstore.yaml", openAPITestSpec)
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneSidebar
+	m.sidebarTab = sidebarSaved
+	press := func(msg tea.Msg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	importSpec := func() {
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(path)})
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	importSpec()
+	importSpec()
+	if m.err != nil {
+		t.Fatal(m.err)
+	}
+	cols, err := loadCollections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 2 || cols[0].Name != "Pet Store" || cols[1].Name != "Pet Store 2" {
+		t.Fatalf("a second import should get a new name, got %d collections", len(cols))
+	}
+	if n, ok := m.selectedNode(); !ok || n.key() != "Pet Store 2" {
+		t.Errorf("the imported collection should be selected, got %v", n.key())
+	}
+}
@@ -381,6 +381,28 @@ func TestSyncURLFromParams(t *testing.T) {
 	})
 }
 
+// TestParamsKeepPlaceholders tests that editing params doesn't escape ${VAR} placeholders
+func TestParamsKeepPlaceholders(t *testing.T) {
+	m := New().(model)
+	m.url.SetValue("${BASE_URL}/users?limit=10#top")
+	m.syncParamsFromURL()
+
+	if len(m.params) != 1 || m.params[0].key.Value() != "limit" {
+		t.Fatalf("params not parsed from URL with placeholder base")
+	}
+
+	m.params[0].value.SetValue("${LIMIT}")
+	m.addParamRow()
+	m.params[1].key.SetValue("q")
+	m.params[1].value.SetValue("a b")
+	m.syncURLFromParams()
+
+	want := "${BASE_URL}/users?limit=${LIMIT}&q=a+b#top"
+	if m.url.Value() != want {
+		t.Errorf("URL = %q, want %q", m.url.Value(), want)
+	}
+}
+
 // TestParamsTwoWaySync tests that entering params tab syncs from URL
 func TestParamsTwoWaySync(t *testing.T) {
 	m := New().(model)
@@ -0,0 +1,517 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// postmanSchema identifies the collection format written on export
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// isPostmanCollection reports whether a decoded document is a Postman collection
+func isPostmanCollection(root map[string]any) bool {
+	return jsonObject(root["info"]) != nil && root["item"] != nil
+}
+
+// isPostmanEnvironment reports whether a decoded document is a Postman environment
+func isPostmanEnvironment(root map[string]any) bool {
+	return root["values"] != nil && root["item"] == nil
+}
+
+// postmanValue returns a Postman value as a string with its variables rewritten
+func postmanValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return fromBraceVars(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// postmanPairs returns the enabled key/value entries of a Postman list such
+// as headers, query parameters or variables
+func postmanPairs(list any) []map[string]any {
+	var pairs []map[string]any
+	for _, raw := range jsonArray(list) {
+		p := jsonObject(raw)
+		if p == nil || p["disabled"] == true || p["enabled"] == false || jsonString(p["key"]) == "" {
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs
+}
+
+// importPostman converts a Postman v2.0 or v2.1 collection. name is used
+// when the collection has none.
+func importPostman(root map[string]any, name string) (*collection, error) {
+	c := &collection{folder: folder{Name: importName(cmp.Or(jsonString(jsonObject(root["info"])["name"]), name))}}
+	for _, v := range postmanPairs(root["variable"]) {
+		if c.Variables == nil {
+			c.Variables = map[string]string{}
+		}
+		c.Variables[jsonString(v["key"])] = postmanValue(v["value"])
+	}
+	importPostmanItems(&c.folder, jsonArray(root["item"]), jsonObject(root["auth"]))
+	if len(c.Requests) == 0 && len(c.Folders) == 0 {
+		return nil, fmt.Errorf("the collection has no requests")
+	}
+	return c, nil
+}
+
+// importPostmanItems adds Postman items to f, folders becoming sub folders.
+// Postman inherits auth from the enclosing folders, which getboy doesn't, so
+// each request gets the auth in effect for it.
+func importPostmanItems(f *folder, items []any, auth map[string]any) {
+	for _, raw := range items {
+		item := jsonObject(raw)
+		if item == nil {
+			continue
+		}
+		name := importName(jsonString(item["name"]))
+		if children, ok := item["item"]; ok {
+			name = uniqueName(name, func(n string) bool { return f.folderIndex(n) >= 0 })
+			sub := f.ensureFolder(name)
+			importPostmanItems(sub, jsonArray(children), postmanEffectiveAuth(item["auth"], auth))
+			continue
+		}
+		req := jsonObject(item["request"])
+		if req == nil {
+			// A request may be given as just its URL
+			req = map[string]any{"url": item["request"]}
+		}
+		spec := importPostmanRequest(req, postmanEffectiveAuth(req["auth"], auth))
+		name = uniqueName(name, func(n string) bool { return f.requestIndex(n) >= 0 })
+		f.Requests = append(f.Requests, savedRequest{Name: name, requestSpec: spec})
+	}
+}
+
+// postmanEffectiveAuth returns an item's own auth, or the inherited one if
+// it has none
+func postmanEffectiveAuth(own any, inherited map[string]any) map[string]any {
+	a := jsonObject(own)
+	if a == nil || jsonString(a["type"]) == "inherit" {
+		return inherited
+	}
+	return a
+}
+
+// importPostmanRequest converts a Postman request
+func importPostmanRequest(req, auth map[string]any) requestSpec {
+	spec := requestSpec{
+		Method: strings.ToUpper(cmp.Or(jsonString(req["method"]), "GET")),
+		URL:    importPostmanURL(req["url"]),
+		Auth:   importPostmanAuth(auth),
+	}
+
+	switch h := req["header"].(type) {
+	case string:
+		// Older collections keep headers as "Key: Value" lines
+		for line := range strings.SplitSeq(h, "\n") {
+			if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) != "" {
+				setHeader(&spec, strings.TrimSpace(k), fromBraceVars(strings.TrimSpace(v)))
+			}
+		}
+	default:
+		for _, p := range postmanPairs(h) {
+			setHeader(&spec, postmanValue(p["key"]), postmanValue(p["value"]))
+		}
+	}
+
+	body := jsonObject(req["body"])
+	if body == nil || body["disabled"] == true {
+		return spec
+	}
+	switch jsonString(body["mode"]) {
+	case "raw":
+		spec.Body = postmanValue(body["raw"])
+		spec.BodyType = postmanRawType(jsonString(jsonObject(jsonObject(body["options"])["raw"])["language"]), spec)
+	case "urlencoded":
+		spec.BodyType = bodyURLEncoded
+		for _, p := range postmanPairs(body["urlencoded"]) {
+			spec.Form = append(spec.Form, formField{Key: postmanValue(p["key"]), Value: postmanValue(p["value"])})
+		}
+	case "formdata":
+		spec.BodyType = bodyMultipart
+		for _, p := range postmanPairs(body["formdata"]) {
+			field := formField{Key: postmanValue(p["key"]), Value: postmanValue(p["value"])}
+			if jsonString(p["type"]) == "file" {
+				field.File = true
+				field.Value = postmanFileSrc(p["src"])
+			}
+			spec.Form = append(spec.Form, field)
+		}
+	case "file":
+		spec.BodyType = bodyBinary
+		spec.BodyFile = postmanFileSrc(jsonObject(body["file"])["src"])
+	case "graphql":
+		gql := jsonObject(body["graphql"])
+		payload := map[string]any{"query": postmanValue(gql["query"])}
+		if vars := jsonString(gql["variables"]); strings.TrimSpace(vars) != "" {
+			payload["variables"] = json.RawMessage(fromBraceVars(vars))
+		}
+		b, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			// Variables that aren't valid JSON are sent as a string
+			payload["variables"] = fromBraceVars(jsonString(gql["variables"]))
+			b, _ = json.MarshalIndent(payload, "", "  ")
+		}
+		spec.Body = string(b)
+	}
+	return spec
+}
+
+// setHeader adds a header to a request
+func setHeader(spec *requestSpec, key, value string) {
+	if spec.Headers == nil {
+		spec.Headers = map[string]string{}
+	}
+	spec.Headers[key] = value
+}
+
+// postmanRawType picks the body type of a raw body from its language, or
+// from its Content-Type header or content when the language isn't set
+func postmanRawType(language string, spec requestSpec) string {
+	switch language {
+	case "json":
+		return bodyJSON
+	case "xml":
+		return bodyXML
+	case "":
+		contentType := ""
+		for k, v := range spec.Headers {
+			if strings.EqualFold(k, "Content-Type") {
+				contentType = v
+			}
+		}
+		switch {
+		case strings.Contains(contentType, "json"):
+			return bodyJSON
+		case strings.Contains(contentType, "xml"):
+			return bodyXML
+		case contentType == "" && detectContentType(spec.Body) == contentJSON:
+			return bodyJSON
+		}
+	}
+	return bodyText
+}
+
+// postmanFileSrc returns the path of a file in a Postman body, which may
+// be a list for multi-file fields
+func postmanFileSrc(src any) string {
+	if list := jsonArray(src); len(list) > 0 {
+		src = list[0]
+	}
+	return jsonString(src)
+}
+
+// importPostmanURL converts a Postman URL, given as a string or as its parts.
+// Path variables such as :id are replaced with their values, or ${id}.
+func importPostmanURL(u any) string {
+	obj := jsonObject(u)
+	if obj == nil {
+		return postmanValue(u)
+	}
+	raw := jsonString(obj["raw"])
+	if raw == "" {
+		var host, path []string
+		for _, h := range jsonArray(obj["host"]) {
+			host = append(host, jsonString(h))
+		}
+		for _, p := range jsonArray(obj["path"]) {
+			path = append(path, jsonString(p))
+		}
+		raw = strings.Join(host, ".")
+		if protocol := jsonString(obj["protocol"]); protocol != "" {
+			raw = protocol + "://" + raw
+		}
+		if len(path) > 0 {
+			raw += "/" + strings.Join(path, "/")
+		}
+		var query []string
+		for _, p := range postmanPairs(obj["query"]) {
+			query = append(query, jsonString(p["key"])+"="+jsonString(p["value"]))
+		}
+		if len(query) > 0 {
+			raw += "?" + strings.Join(query, "&")
+		}
+	}
+
+	base, rawQuery, fragment := splitURL(fromBraceVars(raw))
+	variables := map[string]string{}
+	for _, v := range postmanPairs(obj["variable"]) {
+		key := jsonString(v["key"])
+		variables[key] = cmp.Or(postmanValue(v["value"]), "${"+key+"}")
+	}
+	segments := strings.Split(base, "/")
+	for i, s := range segments {
+		if name, ok := strings.CutPrefix(s, ":"); ok && name != "" && i > 0 {
+			segments[i] = cmp.Or(variables[name], "${"+name+"}")
+		}
+	}
+	base = strings.Join(segments, "/")
+	if rawQuery != "" {
+		base += "?" + rawQuery
+	}
+	return base + fragment
+}
+
+// importPostmanAuth converts a Postman auth object
+func importPostmanAuth(a map[string]any) requestAuth {
+	typ := jsonString(a["type"])
+	params := map[string]string{}
+	switch p := a[typ].(type) {
+	case []any:
+		// v2.1 lists parameters as key/value entries
+		for _, kv := range p {
+			kv := jsonObject(kv)
+			params[jsonString(kv["key"])] = postmanValue(kv["value"])
+		}
+	case map[string]any:
+		// v2.0 keeps them in an object
+		for k, v := range p {
+			params[k] = postmanValue(v)
+		}
+	}
+
+	switch typ {
+	case "basic":
+		return requestAuth{Type: authBasic, Username: params["username"], Password: params["password"]}
+	case "bearer":
+		return requestAuth{Type: authBearer, Token: params["token"]}
+	case "apikey":
+		in := apiKeyInHeader
+		if params["in"] == "query" {
+			in = apiKeyInQuery
+		}
+		return requestAuth{Type: authAPIKey, Key: params["key"], Value: params["value"], In: in}
+	case "oauth2":
+		grant := grantClientCredentials
+		switch params["grant_type"] {
+		case "password_credentials":
+			grant = grantPassword
+		case "authorization_code", "authorization_code_with_pkce":
+			grant = grantAuthorizationCode
+		}
+		return requestAuth{Type: authOAuth2, Username: params["username"], Password: params["password"], OAuth: oauthConfig{
+			Grant:        grant,
+			TokenURL:     params["accessTokenUrl"],
+			AuthURL:      params["authUrl"],
+			ClientID:     params["clientId"],
+			ClientSecret: params["clientSecret"],
+			Scope:        params["scope"],
+			RedirectURL:  params["redirect_uri"],
+		}}
+	}
+	return requestAuth{}
+}
+
+// importPostmanEnvironment converts a Postman environment. name is used when
+// the environment has none.
+func importPostmanEnvironment(root map[string]any, name string) *environment {
+	e := &environment{Name: importName(cmp.Or(jsonString(root["name"]), name)), Variables: map[string]string{}}
+	for _, v := range postmanPairs(root["values"]) {
+		e.Variables[jsonString(v["key"])] = postmanValue(v["value"])
+	}
+	return e
+}
+
+// postmanCollection is a Postman v2.1 collection as written on export
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// postmanItem is a folder, with Item set, or a request
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    *[]postmanItem  `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body,omitempty"`
+	URL    postmanURLParts   `json:"url"`
+	Auth   *postmanAuthSpec  `json:"auth,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Src   string `json:"src,omitempty"`
+}
+
+type postmanURLParts struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     []string          `json:"host,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue `json:"formdata,omitempty"`
+	File       *postmanFile      `json:"file,omitempty"`
+	Options    *postmanOptions   `json:"options,omitempty"`
+}
+
+type postmanFile struct {
+	Src string `json:"src"`
+}
+
+type postmanOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanAuthSpec struct {
+	Type   string            `json:"type"`
+	Basic  []postmanKeyValue `json:"basic,omitempty"`
+	Bearer []postmanKeyValue `json:"bearer,omitempty"`
+	APIKey []postmanKeyValue `json:"apikey,omitempty"`
+	OAuth2 []postmanKeyValue `json:"oauth2,omitempty"`
+}
+
+// exportPostman converts a collection to a Postman v2.1 collection
+func exportPostman(c *collection) postmanCollection {
+	out := postmanCollection{
+		Info: postmanInfo{Name: c.Name, Schema: postmanSchema},
+		Item: exportPostmanItems(&c.folder),
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Variables)) {
+		out.Variable = append(out.Variable, postmanKeyValue{Key: k, Value: toBraceVars(c.Variables[k])})
+	}
+	return out
+}
+
+// exportPostmanItems converts a folder's sub folders and requests to Postman items
+func exportPostmanItems(f *folder) []postmanItem {
+	items := []postmanItem{}
+	for _, sub := range f.Folders {
+		children := exportPostmanItems(sub)
+		items = append(items, postmanItem{Name: sub.Name, Item: &children})
+	}
+	for _, r := range f.Requests {
+		items = append(items, postmanItem{Name: r.Name, Request: exportPostmanRequest(r.requestSpec)})
+	}
+	return items
+}
+
+// exportPostmanRequest converts a request to a Postman request
+func exportPostmanRequest(spec requestSpec) *postmanRequest {
+	req := &postmanRequest{
+		Method: spec.Method,
+		Header: []postmanKeyValue{},
+		URL:    exportPostmanURL(spec.URL),
+		Auth:   exportPostmanAuth(spec.Auth),
+	}
+	for _, k := range slices.Sorted(maps.Keys(spec.Headers)) {
+		req.Header = append(req.Header, postmanKeyValue{Key: toBraceVars(k), Value: toBraceVars(spec.Headers[k])})
+	}
+
+	switch {
+	case !spec.hasBody():
+	case isRawBody(spec.BodyType):
+		req.Body = &postmanBody{Mode: "raw", Raw: toBraceVars(spec.Body), Options: &postmanOptions{}}
+		req.Body.Options.Raw.Language = map[string]string{bodyJSON: "json", bodyXML: "xml", bodyText: "text"}[spec.BodyType]
+	case spec.BodyType == bodyURLEncoded:
+		req.Body = &postmanBody{Mode: "urlencoded"}
+		for _, f := range spec.Form {
+			req.Body.URLEncoded = append(req.Body.URLEncoded, postmanKeyValue{Key: toBraceVars(f.Key), Value: toBraceVars(f.Value)})
+		}
+	case spec.BodyType == bodyMultipart:
+		req.Body = &postmanBody{Mode: "formdata"}
+		for _, f := range spec.Form {
+			field := postmanKeyValue{Key: toBraceVars(f.Key), Value: toBraceVars(f.Value), Type: "text"}
+			if f.File {
+				field = postmanKeyValue{Key: toBraceVars(f.Key), Type: "file", Src: f.Value}
+			}
+			req.Body.FormData = append(req.Body.FormData, field)
+		}
+	case spec.BodyType == bodyBinary:
+		req.Body = &postmanBody{Mode: "file", File: &postmanFile{Src: spec.BodyFile}}
+	}
+	return req
+}
+
+// exportPostmanURL splits a URL into the parts Postman stores
+func exportPostmanURL(rawURL string) postmanURLParts {
+	raw := toBraceVars(rawURL)
+	u := postmanURLParts{Raw: raw}
+	base, rawQuery, _ := splitURL(raw)
+	if protocol, rest, ok := strings.Cut(base, "://"); ok {
+		u.Protocol, base = protocol, rest
+	}
+	host, path, _ := strings.Cut(base, "/")
+	if host != "" {
+		u.Host = strings.Split(host, ".")
+	}
+	if path != "" {
+		u.Path = strings.Split(path, "/")
+	}
+	for _, p := range parseQuery(rawQuery) {
+		u.Query = append(u.Query, postmanKeyValue{Key: p.Key, Value: p.Value})
+	}
+	return u
+}
+
+// exportPostmanAuth converts a request's auth to Postman's, or nil for none
+func exportPostmanAuth(a requestAuth) *postmanAuthSpec {
+	params := func(kv ...string) []postmanKeyValue {
+		var out []postmanKeyValue
+		for i := 0; i < len(kv); i += 2 {
+			if kv[i+1] != "" {
+				out = append(out, postmanKeyValue{Key: kv[i], Value: toBraceVars(kv[i+1]), Type: "string"})
+			}
+		}
+		return out
+	}
+	switch a.Type {
+	case authBasic:
+		return &postmanAuthSpec{Type: "basic", Basic: params("username", a.Username, "password", a.Password)}
+	case authBearer:
+		return &postmanAuthSpec{Type: "bearer", Bearer: params("token", a.Token)}
+	case authAPIKey:
+		in := "header"
+		if a.In == apiKeyInQuery {
+			in = "query"
+		}
+		return &postmanAuthSpec{Type: "apikey", APIKey: params("key", a.Key, "value", a.Value, "in", in)}
+	case authOAuth2:
+		grant := map[string]string{
+			grantClientCredentials: "client_credentials",
+			grantPassword:          "password_credentials",
+			grantAuthorizationCode: "authorization_code_with_pkce",
+		}[cmp.Or(a.OAuth.Grant, grantClientCredentials)]
+		return &postmanAuthSpec{Type: "oauth2", OAuth2: params(
+			"grant_type", grant,
+			"accessTokenUrl", a.OAuth.TokenURL,
+			"authUrl", a.OAuth.AuthURL,
+			"clientId", a.OAuth.ClientID,
+			"clientSecret", a.OAuth.ClientSecret,
+			"scope", a.OAuth.Scope,
+			"redirect_uri", a.OAuth.RedirectURL,
+			"username", a.Username,
+			"password", a.Password,
+		)}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const postmanTestCollection = `{
+  "info": {
+    "name": "Shop",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [
+    {"key": "host", "value": "https://shop.example.com"},
+    {"key": "old", "value": "x", "disabled": true}
+  ],
+  "item": [
+    {
+      "name": "Orders",
+      "auth": {"type": "basic", "basic": {"username": "ada", "password": "{{pw}}"}},
+      "item": [
+        {
+          "name": "Get order",
+          "request": {
+            "method": "GET",
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "url": {
+              "raw": "{{host}}/orders/:id?expand=items",
+              "host": ["{{host}}"],
+              "path": ["orders", ":id"],
+              "variable": [{"key": "id", "value": "42"}]
+            }
+          }
+        },
+        {
+          "name": "Create order",
+          "request": {
+            "method": "POST",
+            "auth": {"type": "inherit"},
+            "header": [],
+            "body": {"mode": "raw", "raw": "{\"sku\": \"{{sku}}\"}", "options": {"raw": {"language": "json"}}},
+            "url": "{{host}}/orders"
+          }
+        }
+      ]
+    },
+    {
+      "name": "Login",
+      "request": {
+        "method": "POST",
+        "auth": {"type": "noauth"},
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "user", "value": "ada"}, {"key": "otp", "value": "", "disabled": true}]},
+        "url": {"protocol": "https", "host": ["auth", "example", "com"], "path": ["login"], "variable": []}
+      }
+    },
+    {
+      "name": "Upload",
+      "request": {
+        "method": "PUT",
+        "body": {"mode": "formdata", "formdata": [
+          {"key": "title", "value": "Cat", "type": "text"},
+          {"key": "image", "type": "file", "src": "/tmp/cat.png"}
+        ]},
+        "url": "{{host}}/images"
+      }
+    },
+    {
+      "name": "Search",
+      "request": {
+        "method": "POST",
+        "body": {"mode": "graphql", "graphql": {"query": "{ shop { name } }", "variables": "{\"first\": 2}"}},
+        "url": "{{host}}/graphql"
+      }
+    },
+    {"name": "Ping", "request": "{{host}}/ping"}
+  ]
+}`
+
+// TestImportPostman tests converting a Postman collection
+func TestImportPostman(t *testing.T) {
+	c, env, err := importFile(writeSpec(t, "shop.postman_collection.json", postmanTestCollection))
+	if err != nil || env != nil {
+		t.Fatal(err, env)
+	}
+	if c.Name != "Shop" || !reflect.DeepEqual(c.Variables, map[string]string{"host": "https://shop.example.com"}) {
+		t.Errorf("collection %q variables %v", c.Name, c.Variables)
+	}
+
+	get := importedRequest(t, c, "Orders", "Get order")
+	if get.URL != "${host}/orders/42?expand=items" || !reflect.DeepEqual(get.Headers, map[string]string{"Accept": "application/json"}) {
+		t.Errorf("get = %s %v", get.URL, get.Headers)
+	}
+	if get.Auth != (requestAuth{Type: authBasic, Username: "ada", Password: "${pw}"}) {
+		t.Errorf("requests should inherit their folder's auth, got %+v", get.Auth)
+	}
+
+	create := importedRequest(t, c, "Orders", "Create order")
+	if create.Body != `{"sku": "${sku}"}` || create.BodyType != bodyJSON || create.Auth.Type != authBasic {
+		t.Errorf("create = %q type %q auth %q", create.Body, create.BodyType, create.Auth.Type)
+	}
+
+	login := importedRequest(t, c, "Login")
+	if login.URL != "https://auth.example.com/login" || login.Auth.Type != authNone {
+		t.Errorf("login = %s auth %q", login.URL, login.Auth.Type)
+	}
+	if login.BodyType != bodyURLEncoded || !reflect.DeepEqual(login.Form, []formField{{Key: "user", Value: "ada"}}) {
+		t.Errorf("login body %q %+v", login.BodyType, login.Form)
+	}
+
+	upload := importedRequest(t, c, "Upload")
+	if want := []formField{{Key: "title", Value: "Cat"}, {Key: "image", Value: "/tmp/cat.png", File: true}}; upload.BodyType != bodyMultipart || !reflect.DeepEqual(upload.Form, want) {
+		t.Errorf("upload body %q %+v", upload.BodyType, upload.Form)
+	}
+	if upload.Auth != (requestAuth{Type: authBearer, Token: "${token}"}) {
+		t.Errorf("requests should inherit the collection's auth, got %+v", upload.Auth)
+	}
+
+	search := importedRequest(t, c, "Search")
+	if want := "{\n  \"query\": \"{ shop { name } }\",\n  \"variables\": {\n    \"first\": 2\n  }\n}"; search.Body != want {
+		t.Errorf("graphql body =\n%s\nwant\n%s", search.Body, want)
+	}
+	if ping := importedRequest(t, c, "Ping"); ping.Method != "GET" || ping.URL != "${host}/ping" {
+		t.Errorf("ping = %s %s", ping.Method, ping.URL)
+	}
+}
+
+// TestExportPostman tests that an exported collection imports back the same
+func TestExportPostman(t *testing.T) {
+	c, _, err := importFile(writeSpec(t, "shop.json", postmanTestCollection))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Requests = append(c.Requests,
+		savedRequest{Name: "Key", requestSpec: requestSpec{Method: "DELETE", URL: "${host}/keys?id=1", Auth: requestAuth{Type: authAPIKey, Key: "X-Key", Value: "${key}", In: apiKeyInQuery}}},
+		savedRequest{Name: "Raw file", requestSpec: requestSpec{Method: "POST", URL: "${host}/raw", BodyType: bodyBinary, BodyFile: "dump.bin"}},
+	)
+
+	data, err := json.MarshalIndent(exportPostman(c), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{postmanSchema, `"raw": "{{host}}/orders/42?expand=items"`, `"language": "json"`, `"mode": "file"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("export is missing %s:\n%s", want, data)
+		}
+	}
+
+	back, _, err := importFile(writeSpec(t, "back.json", string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.folder, c.folder) {
+		want, _ := json.MarshalIndent(c.folder, "", "  ")
+		got, _ := json.MarshalIndent(back.folder, "", "  ")
+		t.Errorf("round trip =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestImportExportPostmanFiles tests importing an environment and exporting
+// a collection from the Saved tab, and that collection variables are used
+// when sending
+func TestImportExportPostmanFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	envPath := writeSpec(t, "staging.postman_environment.json", `{
+		"name": "Staging",
+		"values": [{"key": "host", "value": "https://staging.example.com", "enabled": true}, {"key": "off", "value": "1", "enabled": false}],
+		"_postman_variable_scope": "environment"
+	}`)
+	colPath := writeSpec(t, "shop.json", postmanTestCollection)
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	m.pane = paneSidebar
+	m.sidebarTab = sidebarSaved
+	press := func(msg tea.Msg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	prompt := func(key, value string) {
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m.prompt.SetValue(value)
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+		if m.err != nil {
+			t.Fatal(m.err)
+		}
+	}
+
+	prompt("I", envPath)
+	prompt("I", colPath)
+	envs, _ := loadEnvironments()
+	if len(envs.Environments) != 1 || envs.Environments[0].Name != "Staging" || len(envs.Environments[0].Variables) != 1 || envs.Active != "" {
+		t.Errorf("environments = %+v", envs)
+	}
+
+	// The environment overrides the collection's variables
+	m.loadedPath = "Shop/Ping"
+	if vars := m.requestVars(); vars["host"] != "https://shop.example.com" {
+		t.Errorf("host = %q, want the collection's", vars["host"])
+	}
+	m.selectEnvironment(1)
+	if vars := m.requestVars(); vars["host"] != "https://staging.example.com" {
+		t.Errorf("host = %q, want the environment's", vars["host"])
+	}
+
+	prompt("E", dir)
+	data, err := os.ReadFile(filepath.Join(dir, "shop.postman_collection.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"name": "Shop"`) {
+		t.Errorf("export =\n%s", data)
+	}
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxBufferedBody is the most of a response kept in memory; larger
+	// responses should be downloaded to disk instead
+	maxBufferedBody = 64 << 20
+
+	// responseRenderLimit is how much of a text body each "load more" step
+	// renders, since highlighting and wrapping huge bodies freezes the TUI
+	responseRenderLimit = 256 << 10
+
+	// hexPreviewLimit is how many bytes of a binary body each step dumps
+	hexPreviewLimit = 4 << 10
+)
+
+// textMediaTypes are the non text/* media types that are shown as text
+var textMediaTypes = []string{
+	"application/json", "application/xml", "application/javascript", "application/ecmascript",
+	"application/x-www-form-urlencoded", "application/yaml", "application/x-yaml", "application/toml",
+	"application/graphql", "application/x-ndjson", "application/problem+json", "image/svg+xml",
+}
+
+// isTextMediaType reports whether a media type holds text
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	for _, t := range textMediaTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// responseMediaType returns the declared media type of a response, or one
+// sniffed from the body when the server didn't say
+func responseMediaType(headers http.Header, body string) string {
+	if mediaType, _, err := mime.ParseMediaType(headers.Get("Content-Type")); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType([]byte(body[:min(len(body), 512)])))
+	return mediaType
+}
+
+// binaryMediaTypes are media types, or prefixes of them, that are never text
+var binaryMediaTypes = []string{
+	"image/", "audio/", "video/", "font/", "application/pdf", "application/zip",
+	"application/gzip", "application/x-tar", "application/wasm", "application/protobuf",
+	"application/x-protobuf", "application/vnd.google.protobuf", "application/msgpack",
+}
+
+// isBinaryBody reports whether a body would garble the terminal if shown as
+// text, going by its media type and then by sniffing its first bytes
+func isBinaryBody(headers http.Header, body string) bool {
+	if body == "" {
+		return false
+	}
+	if mediaType := responseMediaType(headers, body); !isTextMediaType(mediaType) {
+		for _, t := range binaryMediaTypes {
+			if strings.HasPrefix(mediaType, t) {
+				return true
+			}
+		}
+	}
+	return looksBinary(body[:min(len(body), 8<<10)])
+}
+
+// looksBinary reports whether a sample of a body holds NUL bytes, invalid
+// UTF-8 or many control characters
+func looksBinary(sample string) bool {
+	controls := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRuneInString(sample[i:])
+		if r == utf8.RuneError && size == 1 && len(sample)-i >= utf8.UTFMax {
+			// Invalid UTF-8, except for a rune cut off at the end of the sample
+			return true
+		}
+		switch {
+		case r == 0:
+			return true
+		case r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != 0x1b:
+			controls++
+		}
+		i += size
+	}
+	return controls > len(sample)/100
+}
+
+// renderResponseBody renders the body of a response for the viewport,
+// rendering at most pages steps of a large text or binary body
+func renderResponseBody(resp *httpDoneMsg, pages int) string {
+	faintStyle := lipgloss.NewStyle().Faint(true)
+	var notes []string
+	switch {
+	case resp.Truncated && resp.Restored:
+		notes = append(notes, faintStyle.Render(fmt.Sprintf("Only the first %s were kept in history", formatBytes(int64(len(resp.Body))))))
+	case resp.Truncated:
+		notes = append(notes, faintStyle.Render(fmt.Sprintf("Only the first %s were received; set a download path in the Settings tab to save it all", formatBytes(maxBufferedBody))))
+	}
+
+	body := resp.Body
+	if isBinaryBody(resp.Headers, body) {
+		summary := fmt.Sprintf("Binary response: %s, %s", responseMediaType(resp.Headers, body), formatBytes(int64(len(body))))
+		shown := min(len(body), pages*hexPreviewLimit)
+		out := []string{summary, ""}
+		out = append(out, notes...)
+		out = append(out, strings.TrimRight(hex.Dump([]byte(body[:shown])), "\n"))
+		if shown < len(body) {
+			out = append(out, "", faintStyle.Render(fmt.Sprintf("Showing %s of %s  m: load more  s: save body", formatBytes(int64(shown)), formatBytes(int64(len(body))))))
+		}
+		return strings.Join(out, "\n")
+	}
+
+	limit := pages * responseRenderLimit
+	if len(body) <= limit {
+		return strings.Join(append(notes, renderResponse(sanitizeText(body))), "\n")
+	}
+	// Cut at a line break so the shown part ends cleanly, unless that would
+	// hide most of a long line
+	shown := body[:limit]
+	if i := strings.LastIndexByte(shown, '\n'); i > limit/2 {
+		shown = shown[:i+1]
+	}
+	var rendered string
+	switch detectContentType(shown) {
+	case contentHTML:
+		rendered = highlight(sanitizeText(shown), "html")
+	case contentXML:
+		rendered = highlight(sanitizeText(shown), "xml")
+	default:
+		// A JSON prefix doesn't parse, so it is shown as sent
+		rendered = sanitizeText(shown)
+	}
+	more := faintStyle.Render(fmt.Sprintf("Showing %s of %s  m: load more  s: save body", formatBytes(int64(len(shown))), formatBytes(int64(len(body)))))
+	return strings.Join(append(notes, strings.TrimRight(rendered, "\n"), "", more), "\n")
+}
+
+// sanitizeText replaces control characters that would move the cursor or
+// restyle the terminal with the Unicode replacement character
+func sanitizeText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r == '\r':
+			return -1
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return utf8.RuneError
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestIsBinaryBody tests detecting binary bodies by media type and content
+func TestIsBinaryBody(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"json", "application/json", `{"a":1}`, false},
+		{"declared image", "image/png", png, true},
+		{"sniffed image", "", png, true},
+		{"svg is text", "image/svg+xml", "<svg/>", false},
+		{"octet-stream text", "application/octet-stream", "plain words\n", false},
+		{"text with NUL", "text/plain", "a\x00b", true},
+		{"invalid utf-8", "", "abc\xff\xfe\xfddef", true},
+		{"utf-8 text", "", "héllo wörld ✓\n", false},
+		{"ansi colours", "text/plain", "\x1b[31mred\x1b[0m\n", false},
+		{"empty", "image/png", "", false},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.contentType != "" {
+			h.Set("Content-Type", tt.contentType)
+		}
+		if got := isBinaryBody(h, tt.body); got != tt.want {
+			t.Errorf("%s: isBinaryBody = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRenderBinaryResponse tests the summary and hex dump of binary bodies
+func TestRenderBinaryResponse(t *testing.T) {
+	body := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00\x01", hexPreviewLimit)
+	resp := &httpDoneMsg{Headers: http.Header{"Content-Type": {"image/png"}}, Body: body}
+
+	out := renderResponseBody(resp, 1)
+	if !strings.Contains(out, "image/png, 8.0 KB") {
+		t.Errorf("binary body should be summarized:\n%s", out)
+	}
+	if !strings.Contains(out, "00000000  89 50 4e 47 0d 0a 1a 0a") || strings.Contains(out, "\x00") {
+		t.Errorf("binary body should be shown as a hex dump:\n%s", out)
+	}
+	if !strings.Contains(out, "Showing 4.0 KB of 8.0 KB") {
+		t.Errorf("the preview should say more can be loaded:\n%s", out)
+	}
+	if out := renderResponseBody(resp, 3); strings.Contains(out, "load more") {
+		t.Errorf("everything loaded, no more to load:\n%s", out)
+	}
+}
+
+// TestRenderLargeResponse tests capping a huge text body and loading more of it
+func TestRenderLargeResponse(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	body := strings.Repeat(line, 3*responseRenderLimit/len(line))
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	press := func(msg tea.Msg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	press(httpDoneMsg{Status: "200 OK", StatusCode: 200, Body: body, Headers: http.Header{"Content-Type": {"text/plain"}}})
+	lines := m.view.TotalLineCount()
+	if lines > responseRenderLimit/len(line)+3 {
+		t.Errorf("rendered %d lines, want the first %d KB only", lines, responseRenderLimit>>10)
+	}
+
+	m.view.SetYOffset(10)
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if got := m.view.TotalLineCount(); got <= lines || m.view.YOffset != 10 {
+		t.Errorf("load more rendered %d lines at offset %d, want more than %d at 10", got, m.view.YOffset, lines)
+	}
+}
+
+// TestTruncatedResponse tests that huge responses aren't buffered whole
+func TestTruncatedResponse(t *testing.T) {
+	chunk := strings.Repeat("y", 1<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range maxBufferedBody>>20 + 2 {
+			if _, err := w.Write([]byte(chunk)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	msg := doHTTP(requestSpec{Method: "GET", URL: server.URL}, nil)().(httpDoneMsg)
+	if msg.Err != nil {
+		t.Fatalf("request failed: %v", msg.Err)
+	}
+	if !msg.Truncated || len(msg.Body) != maxBufferedBody {
+		t.Errorf("kept %d bytes, truncated = %v, want %d", len(msg.Body), msg.Truncated, maxBufferedBody)
+	}
+	if out := renderResponseBody(&msg, 1); !strings.Contains(out, "Only the first 64.0 MB") {
+		t.Errorf("truncation should be noted:\n%.300s", out)
+	}
+}
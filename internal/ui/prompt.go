@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptKind identifies what a footer prompt is asking for
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptSaveRequest
+	promptRenameNode
+	promptDeleteNode // y/N confirmation, no text input
+	promptNewFolder
+	promptNewCollection
+	promptCookieValue
+	promptSaveResponse
+	promptImport
+	promptExportCollection
+	promptImportHistory
+	promptExportHistory
+)
+
+// newPromptInput creates the single-line input used by footer prompts
+func newPromptInput() textinput.Model {
+	p := textinput.New()
+	p.CharLimit = 256
+	p.Prompt = ""
+	return p
+}
+
+// isConfirm reports whether the prompt is a y/N confirmation
+func (k promptKind) isConfirm() bool {
+	return k == promptDeleteNode
+}
+
+// openPrompt shows a footer prompt with the given label and initial value
+func (m *model) openPrompt(kind promptKind, label, value string) {
+	m.promptKind = kind
+	m.promptLabel = label
+	m.insertMode = false
+	m.applyFocus()
+	m.prompt.SetValue(value)
+	m.prompt.CursorEnd()
+	if !kind.isConfirm() {
+		m.prompt.Focus()
+	}
+}
+
+// closePrompt hides the footer prompt
+func (m *model) closePrompt() {
+	m.promptKind = promptNone
+	m.promptLabel = ""
+	m.promptTarget = ""
+	m.prompt.Blur()
+	m.prompt.SetValue("")
+}
+
+// updatePrompt routes key presses to the open prompt
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.promptKind.isConfirm() {
+		if msg.String() == "y" || msg.String() == "Y" {
+			m.submitPrompt()
+		}
+		m.closePrompt()
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.closePrompt()
+		return m, nil
+	case "enter":
+		m.submitPrompt()
+		m.closePrompt()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+// submitPrompt applies the prompt's value
+func (m *model) submitPrompt() {
+	value := strings.TrimSpace(m.prompt.Value())
+
+	switch m.promptKind {
+	case promptSaveRequest:
+		if len(splitPath(value)) == 0 || strings.HasSuffix(value, "/") {
+			m.status = "Name cannot be empty"
+			return
+		}
+		m.saveCurrentRequest(value)
+	case promptRenameNode:
+		m.renameNode(m.promptTarget, value)
+	case promptDeleteNode:
+		m.deleteNode(m.promptTarget)
+	case promptNewFolder:
+		m.createFolder(value)
+	case promptNewCollection:
+		m.createCollection(value)
+	case promptCookieValue:
+		m.setCookieValue(value)
+	case promptSaveResponse:
+		m.saveResponseBody(value)
+	case promptImport:
+		m.importFromFile(value)
+	case promptExportCollection:
+		m.exportCollection(m.promptTarget, value)
+	case promptImportHistory:
+		m.importHistory(value)
+	case promptExportHistory:
+		m.exportHistory(value)
+	}
+}
+
+// viewPrompt renders the prompt line shown in place of the status footer
+func (m model) viewPrompt() string {
+	if m.promptKind.isConfirm() {
+		return m.promptLabel + " [y/N]"
+	}
+	return m.promptLabel + " " + m.prompt.View() + "  enter: confirm  esc: cancel"
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"net/url"
+	"strings"
+)
+
+// queryPair is a single decoded query parameter, kept in URL order
+type queryPair struct {
+	Key   string
+	Value string
+}
+
+// splitURL splits a URL into the part before the query string, the raw
+// query (without '?') and the fragment (with '#').
+// Unlike url.Parse it never fails, so ${VAR} placeholders survive.
+func splitURL(u string) (base, rawQuery, fragment string) {
+	if i := strings.Index(u, "#"); i >= 0 {
+		u, fragment = u[:i], u[i:]
+	}
+	if i := strings.Index(u, "?"); i >= 0 {
+		return u[:i], u[i+1:], fragment
+	}
+	return u, "", fragment
+}
+
+// parseQuery decodes a raw query string into ordered pairs.
+// Malformed escapes are kept verbatim.
+func parseQuery(rawQuery string) []queryPair {
+	var pairs []queryPair
+	for part := range strings.SplitSeq(rawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		pairs = append(pairs, queryPair{Key: unescapeQuery(k), Value: unescapeQuery(v)})
+	}
+	return pairs
+}
+
+// unescapeQuery decodes a query component, returning it unchanged if malformed
+func unescapeQuery(s string) string {
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// encodeQuery encodes pairs into a query string, leaving ${VAR} placeholders
+// unescaped so they can still be expanded later
+func encodeQuery(pairs []queryPair) string {
+	parts := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		parts = append(parts, escapeQueryKeepVars(p.Key)+"="+escapeQueryKeepVars(p.Value))
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeQueryKeepVars query-escapes s except for ${VAR} placeholders
+func escapeQueryKeepVars(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range envVarPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(url.QueryEscape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(url.QueryEscape(s[last:]))
+	return b.String()
+}
@@ -1,16 +1,179 @@
 package ui
 
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfnilsson/getboy/internal/ui/theme"
+)
+
+// responseTab selects what the response pane shows
+type responseTab int
+
+const (
+	respBody responseTab = iota
+	respHeaders
+	respInfo
+	respTiming
+)
+
+// responseTabCount is the number of response pane tabs
+const responseTabCount = 4
+
 // viewResponse renders the response pane containing the HTTP response.
 func (m model) viewResponse() string {
 	content := m.view.View()
 
-	respBox := titledPane(
+	tabs := []string{"Body", "Headers", "Info", "Timing"}
+
+	respBox := titledPaneWithTabs(
 		content,
 		m.rightPaneWidth(),
 		m.responseHeight(),
 		m.pane == paneResponse,
 		paneBadge(3),
 		"Response",
+		tabs,
+		int(m.respTab),
 	)
 	return respBox
 }
+
+// nextResponseTab cycles the response pane tabs
+func (m *model) nextResponseTab() {
+	m.respTab = (m.respTab + 1) % responseTabCount
+	m.refreshResponseView()
+}
+
+// prevResponseTab cycles the response pane tabs backwards
+func (m *model) prevResponseTab() {
+	m.respTab = (m.respTab + responseTabCount - 1) % responseTabCount
+	m.refreshResponseView()
+}
+
+// refreshResponseView renders the last response into the viewport for the active tab
+func (m *model) refreshResponseView() {
+	if m.resp == nil {
+		return
+	}
+	var content string
+	switch m.respTab {
+	case respBody:
+		if m.resp.Err != nil {
+			content = fmt.Sprintf("Error: %v", m.resp.Err)
+		} else if m.resp.SavedTo != "" {
+			content = fmt.Sprintf("Saved %s to %s", formatBytes(int64(m.resp.Size)), m.resp.SavedTo)
+		} else if m.respFilterExpr != "" {
+			content = renderFilteredBody(m.resp.Body, m.respFilterExpr)
+		} else {
+			content = renderResponseBody(m.resp, m.respPages)
+		}
+	case respHeaders:
+		content = renderResponseHeaders(m.resp)
+	case respInfo:
+		content = renderResponseInfo(m.resp)
+	case respTiming:
+		content = renderTiming(m.resp.Timing, m.view.Width)
+	}
+	m.respMatchIdx = 0
+	m.setResponseContent(content)
+	m.view.GotoTop()
+}
+
+// loadMoreResponse renders another step of a large body, keeping the scroll position
+func (m *model) loadMoreResponse() {
+	if m.resp == nil || m.respTab != respBody {
+		return
+	}
+	offset := m.view.YOffset
+	m.respPages++
+	m.refreshResponseView()
+	m.view.SetYOffset(offset)
+}
+
+// renderResponseHeaders lists every response header, sorted by name
+func renderResponseHeaders(resp *httpDoneMsg) string {
+	if len(resp.Headers) == 0 {
+		return "No response headers"
+	}
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Current.Title)
+
+	names := make([]string, 0, len(resp.Headers))
+	for k := range resp.Headers {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	var lines []string
+	for _, k := range names {
+		for _, v := range resp.Headers[k] {
+			lines = append(lines, keyStyle.Render(k+":")+" "+v)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderResponseInfo shows status and connection metadata
+func renderResponseInfo(resp *httpDoneMsg) string {
+	if resp.Err != nil {
+		return fmt.Sprintf("Error: %v", resp.Err)
+	}
+
+	declared := "unknown"
+	if resp.ContentLength >= 0 {
+		declared = formatBytes(resp.ContentLength)
+	}
+	tlsVersion := resp.TLSVersion
+	if tlsVersion == "" {
+		tlsVersion = "none"
+	}
+
+	rows := [][2]string{
+		{"Status", resp.Status},
+		{"Status code", fmt.Sprint(resp.StatusCode)},
+		{"Protocol", resp.Proto},
+		{"URL", resp.FinalURL},
+		{"Remote address", resp.RemoteAddr},
+		{"TLS", tlsVersion},
+		{"Size", formatBytes(int64(resp.Size))},
+		{"Content-Length", declared},
+		{"Saved to", cmp.Or(resp.SavedTo, "not saved")},
+		{"Time", formatDuration(resp.Timing.Total)},
+	}
+	if resp.Restored {
+		rows = append(rows, [2]string{"Sent", resp.SentAt.Local().Format("2006-01-02 15:04:05") + " (from history)"})
+	}
+	return renderInfoRows(rows)
+}
+
+// renderInfoRows renders label/value rows with aligned values
+func renderInfoRows(rows [][2]string) string {
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Current.Title)
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r[0]))
+	}
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = keyStyle.Render(fmt.Sprintf("%-*s", width, r[0])) + "  " + r[1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatBytes renders a byte count in human readable units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
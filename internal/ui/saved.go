@@ -0,0 +1,350 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	collectionsDirName    = "collections"
+	legacySavedFileName   = "saved.json"
+	defaultCollectionName = "Default"
+)
+
+// savedRequest is a named request in a saved collection
+type savedRequest struct {
+	Name string `json:"name"`
+	requestSpec
+}
+
+// folder groups saved requests and nested folders
+type folder struct {
+	Name      string            `json:"name"`
+	Variables map[string]string `json:"variables,omitempty"` // collection only, see collectionVars
+	Folders   []*folder         `json:"folders,omitempty"`
+	Requests  []savedRequest    `json:"requests,omitempty"`
+}
+
+// collection is a top-level folder persisted in its own file
+type collection struct {
+	folder
+	file string    // file name the collection was loaded from, empty if never written
+	http *httpFile // request file the collection was opened from, see Open
+}
+
+// folderIndex returns the index of the sub folder with the given name, or -1
+func (f *folder) folderIndex(name string) int {
+	for i, sub := range f.Folders {
+		if sub.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// requestIndex returns the index of the request with the given name, or -1
+func (f *folder) requestIndex(name string) int {
+	for i, r := range f.Requests {
+		if r.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ensureFolder returns the sub folder with the given name, creating it if needed
+func (f *folder) ensureFolder(name string) *folder {
+	if i := f.folderIndex(name); i >= 0 {
+		return f.Folders[i]
+	}
+	sub := &folder{Name: name}
+	f.Folders = append(f.Folders, sub)
+	return sub
+}
+
+// upsertRequest stores a request, replacing any request with the same name
+func (f *folder) upsertRequest(req savedRequest) {
+	if i := f.requestIndex(req.Name); i >= 0 {
+		f.Requests[i] = req
+		return
+	}
+	f.Requests = append(f.Requests, req)
+}
+
+// splitPath splits a slash separated saved path into its segments
+func splitPath(p string) []string {
+	var parts []string
+	for _, s := range strings.Split(p, "/") {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return parts
+}
+
+// joinPath joins path segments into a slash separated saved path
+func joinPath(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// findCollection returns the collection with the given name, or nil
+func findCollection(cols []*collection, name string) *collection {
+	for _, c := range cols {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// collectionVars returns the variables of the collection a saved path is
+// in, which the environment's variables override
+func collectionVars(cols []*collection, path []string) map[string]string {
+	if len(path) == 0 {
+		return nil
+	}
+	if c := findCollection(cols, path[0]); c != nil {
+		return c.Variables
+	}
+	return nil
+}
+
+// lookupFolder walks path (collection, then folders) and returns the
+// collection and the folder it ends at, or nils if any segment is missing
+func lookupFolder(cols []*collection, path []string) (*collection, *folder) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	c := findCollection(cols, path[0])
+	if c == nil {
+		return nil, nil
+	}
+	f := &c.folder
+	for _, name := range path[1:] {
+		i := f.folderIndex(name)
+		if i < 0 {
+			return nil, nil
+		}
+		f = f.Folders[i]
+	}
+	return c, f
+}
+
+// lookupRequest returns the saved request at path (folder path plus request name)
+func lookupRequest(cols []*collection, path []string) (*savedRequest, bool) {
+	if len(path) < 2 {
+		return nil, false
+	}
+	_, f := lookupFolder(cols, path[:len(path)-1])
+	if f == nil {
+		return nil, false
+	}
+	i := f.requestIndex(path[len(path)-1])
+	if i < 0 {
+		return nil, false
+	}
+	return &f.Requests[i], true
+}
+
+// saveRequestAt stores spec at path, creating the collection and folders as
+// needed. A bare name is saved into the default collection.
+func saveRequestAt(cols []*collection, path []string, spec requestSpec) ([]*collection, *collection, error) {
+	if len(path) == 0 {
+		return cols, nil, fmt.Errorf("name cannot be empty")
+	}
+	if len(path) == 1 {
+		path = append([]string{defaultCollectionName}, path...)
+	}
+
+	c := findCollection(cols, path[0])
+	if c == nil {
+		var err error
+		if cols, c, err = addCollection(cols, path[0]); err != nil {
+			return cols, nil, err
+		}
+	}
+	f := &c.folder
+	for _, name := range path[1 : len(path)-1] {
+		f = f.ensureFolder(name)
+	}
+	f.upsertRequest(savedRequest{Name: path[len(path)-1], requestSpec: spec})
+	return cols, c, nil
+}
+
+// addCollection creates a new, empty collection
+func addCollection(cols []*collection, name string) ([]*collection, *collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, "/") {
+		return cols, nil, fmt.Errorf("invalid collection name %q", name)
+	}
+	for _, c := range cols {
+		if c.Name == name || collectionFileName(c.Name) == collectionFileName(name) {
+			return cols, nil, fmt.Errorf("a collection named %q already exists", c.Name)
+		}
+	}
+	c := &collection{folder: folder{Name: name}}
+	cols = append(cols, c)
+	sortCollections(cols)
+	return cols, c, nil
+}
+
+// sortCollections orders collections by name
+func sortCollections(cols []*collection) {
+	sort.SliceStable(cols, func(i, j int) bool {
+		return strings.ToLower(cols[i].Name) < strings.ToLower(cols[j].Name)
+	})
+}
+
+// collectionFileName derives a stable file name from a collection name
+func collectionFileName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" {
+		slug = "collection"
+	}
+	return slug + ".json"
+}
+
+// getCollectionsDir returns the path to ~/.getboy/collections, creating it
+// readable only by the user if needed, as requests hold credentials
+func getCollectionsDir() (string, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, collectionsDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// loadCollections reads every collection file from disk, migrating the
+// old single-file saved list into the default collection on first run
+func loadCollections() ([]*collection, error) {
+	dir, err := getCollectionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cols []*collection
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		c := &collection{file: filepath.Base(path)}
+		if err := json.Unmarshal(data, &c.folder); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if c.Name == "" {
+			c.Name = strings.TrimSuffix(c.file, ".json")
+		}
+		cols = append(cols, c)
+	}
+
+	if len(cols) == 0 {
+		if c, err := migrateLegacySaved(); err != nil {
+			return nil, err
+		} else if c != nil {
+			cols = append(cols, c)
+		}
+	}
+
+	sortCollections(cols)
+	return cols, nil
+}
+
+// migrateLegacySaved moves requests from the old saved.json into the
+// default collection. Returns nil if there is nothing to migrate.
+func migrateLegacySaved() (*collection, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, legacySavedFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var saved []savedRequest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+
+	c := &collection{folder: folder{Name: defaultCollectionName, Requests: saved}}
+	if err := writeCollection(c); err != nil {
+		return nil, err
+	}
+	return c, os.Rename(path, path+".migrated")
+}
+
+// writeCollection writes a collection to its file, readable only by the
+// user, removing the old file if the collection was renamed
+func writeCollection(c *collection) error {
+	if c.http != nil {
+		return c.http.write(c)
+	}
+	dir, err := getCollectionsDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c.folder, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := collectionFileName(c.Name)
+	if err := writePrivateFile(filepath.Join(dir, file), data); err != nil {
+		return err
+	}
+	if c.file != "" && c.file != file {
+		_ = os.Remove(filepath.Join(dir, c.file)) // Best-effort cleanup after rename
+	}
+	c.file = file
+	return nil
+}
+
+// removeCollectionFile deletes a collection's file from disk
+func removeCollectionFile(c *collection) error {
+	if c.file == "" {
+		return nil
+	}
+	dir, err := getCollectionsDir()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(dir, c.file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,425 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestSplitPath tests parsing slash separated saved paths
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"name", 1},
+		{"Coll/Folder/name", 3},
+		{" Coll / name ", 2},
+		{"Coll//name", 2},
+	}
+	for _, tt := range tests {
+		if got := splitPath(tt.in); len(got) != tt.want {
+			t.Errorf("splitPath(%q) = %v, want %d segments", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestCollectionFileName tests deriving file names from collection names
+func TestCollectionFileName(t *testing.T) {
+	tests := map[string]string{
+		"Default":         "default.json",
+		"Billing API":     "billing-api.json",
+		"  users / v2  ":  "users-v2.json",
+		"!!!":             "collection.json",
+		"snake_case_name": "snake_case_name.json",
+	}
+	for in, want := range tests {
+		if got := collectionFileName(in); got != want {
+			t.Errorf("collectionFileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestSaveRequestAt tests saving into nested folders
+func TestSaveRequestAt(t *testing.T) {
+	var cols []*collection
+
+	t.Run("bare name goes to default collection", func(t *testing.T) {
+		var err error
+		cols, _, err = saveRequestAt(cols, []string{"ping"}, requestSpec{Method: "GET", URL: "https://a.com/ping"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := lookupRequest(cols, []string{defaultCollectionName, "ping"}); !ok {
+			t.Error("request not found in default collection")
+		}
+	})
+
+	t.Run("creates collection and folders", func(t *testing.T) {
+		var err error
+		cols, _, err = saveRequestAt(cols, []string{"Billing", "Invoices", "Drafts", "list"}, requestSpec{Method: "GET", URL: "https://b.com"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req, ok := lookupRequest(cols, []string{"Billing", "Invoices", "Drafts", "list"})
+		if !ok {
+			t.Fatal("request not found at nested path")
+		}
+		if req.URL != "https://b.com" {
+			t.Errorf("URL = %q, want %q", req.URL, "https://b.com")
+		}
+		if len(cols) != 2 || cols[0].Name != "Billing" {
+			t.Errorf("collections should be sorted by name, got %v", cols)
+		}
+	})
+
+	t.Run("same path replaces request", func(t *testing.T) {
+		cols, _, _ = saveRequestAt(cols, []string{"Billing", "Invoices", "Drafts", "list"}, requestSpec{Method: "POST", URL: "https://b.com"})
+		_, f := lookupFolder(cols, []string{"Billing", "Invoices", "Drafts"})
+		if len(f.Requests) != 1 || f.Requests[0].Method != "POST" {
+			t.Errorf("requests = %+v, want one POST", f.Requests)
+		}
+	})
+}
+
+// TestFlattenTree tests that only expanded folders are descended into
+func TestFlattenTree(t *testing.T) {
+	cols, _, _ := saveRequestAt(nil, []string{"API", "users", "get"}, requestSpec{Method: "GET"})
+	cols, _, _ = saveRequestAt(cols, []string{"API", "health"}, requestSpec{Method: "GET"})
+
+	nodes := flattenTree(cols, map[string]bool{})
+	if len(nodes) != 1 || nodes[0].kind != nodeCollection {
+		t.Fatalf("collapsed tree = %+v, want only the collection", nodes)
+	}
+
+	nodes = flattenTree(cols, map[string]bool{"API": true})
+	if len(nodes) != 3 {
+		t.Fatalf("expanded collection rows = %d, want 3", len(nodes))
+	}
+	// Folders are listed before requests
+	if nodes[1].kind != nodeFolder || nodes[1].name() != "users" {
+		t.Errorf("second row = %+v, want folder 'users'", nodes[1])
+	}
+	if nodes[2].kind != nodeRequest || nodes[2].name() != "health" {
+		t.Errorf("third row = %+v, want request 'health'", nodes[2])
+	}
+
+	nodes = flattenTree(cols, map[string]bool{"API": true, "API/users": true})
+	if len(nodes) != 4 || nodes[2].key() != "API/users/get" || nodes[2].depth != 2 {
+		t.Errorf("fully expanded rows = %+v", nodes)
+	}
+}
+
+// TestCollectionsPersistOneFileEach tests the on-disk layout of collections
+func TestCollectionsPersistOneFileEach(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	cols, a, _ := saveRequestAt(nil, []string{"Service A", "ping"}, requestSpec{Method: "GET", URL: "https://a"})
+	cols, b, _ := saveRequestAt(cols, []string{"Service B", "folder", "ping"}, requestSpec{Method: "GET", URL: "https://b"})
+	if err := writeCollection(a); err != nil {
+		t.Fatalf("writeCollection failed: %v", err)
+	}
+	if err := writeCollection(b); err != nil {
+		t.Fatalf("writeCollection failed: %v", err)
+	}
+
+	dir := filepath.Join(tmpDir, ".getboy", "collections")
+	for _, name := range []string{"service-a.json", "service-b.json"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("collection file %s not written: %v", name, err)
+		} else if info.Mode().Perm() != 0600 {
+			t.Errorf("collection file %s mode = %v, want 0600", name, info.Mode().Perm())
+		}
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("collections directory should only be readable by the user, got %v", err)
+	}
+
+	loaded, err := loadCollections()
+	if err != nil {
+		t.Fatalf("loadCollections failed: %v", err)
+	}
+	if len(loaded) != len(cols) {
+		t.Fatalf("loaded %d collections, want %d", len(loaded), len(cols))
+	}
+	if req, ok := lookupRequest(loaded, []string{"Service B", "folder", "ping"}); !ok || req.URL != "https://b" {
+		t.Errorf("nested request not restored: %+v", req)
+	}
+
+	// Renaming a collection moves its file
+	b.Name = "Service C"
+	if err := writeCollection(b); err != nil {
+		t.Fatalf("writeCollection failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".getboy", "collections", "service-b.json")); !os.IsNotExist(err) {
+		t.Error("old collection file should be removed after rename")
+	}
+}
+
+// TestMigrateLegacySaved tests that the old flat saved list is imported
+func TestMigrateLegacySaved(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	dir := filepath.Join(tmpDir, ".getboy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `[{"name":"users","method":"GET","url":"https://api.com/users"}]`
+	if err := os.WriteFile(filepath.Join(dir, "saved.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := loadCollections()
+	if err != nil {
+		t.Fatalf("loadCollections failed: %v", err)
+	}
+	if _, ok := lookupRequest(cols, []string{defaultCollectionName, "users"}); !ok {
+		t.Error("legacy request not migrated into default collection")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "saved.json")); !os.IsNotExist(err) {
+		t.Error("legacy file should be moved aside after migration")
+	}
+}
+
+// TestSaveRequestFromEditor tests the save keybinding and path prompt
+func TestSaveRequestFromEditor(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.setMethod("PUT")
+	m.url.SetValue("https://api.com/things/1")
+	m.body.SetValue(`{"x":1}`)
+
+	// Press 's' to open the save prompt
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updated.(model)
+	if m.promptKind != promptSaveRequest {
+		t.Fatalf("promptKind = %v, want promptSaveRequest", m.promptKind)
+	}
+
+	// Type a path and confirm
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("API/things/update")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if m.promptKind != promptNone {
+		t.Error("prompt should close after enter")
+	}
+	req, ok := lookupRequest(m.collections, []string{"API", "things", "update"})
+	if !ok {
+		t.Fatal("request was not saved at API/things/update")
+	}
+	if req.Method != "PUT" || req.URL != "https://api.com/things/1" {
+		t.Errorf("saved request = %+v", req)
+	}
+	if m.loadedPath != "API/things/update" {
+		t.Errorf("loadedPath = %q, want %q", m.loadedPath, "API/things/update")
+	}
+
+	// Survives a restart
+	m2 := New().(model)
+	if _, ok := lookupRequest(m2.collections, []string{"API", "things", "update"}); !ok {
+		t.Error("saved request not reloaded from disk")
+	}
+}
+
+// TestSavePromptEscCancels tests that esc closes the prompt without saving
+func TestSavePromptEscCancels(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.url.SetValue("https://api.com")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+
+	if m.promptKind != promptNone {
+		t.Error("prompt should close on esc")
+	}
+	if len(m.collections) != 0 {
+		t.Errorf("nothing should be saved, got %d collections", len(m.collections))
+	}
+}
+
+// TestSavedTreeNavigation tests expanding, loading, renaming and deleting from the tree
+func TestSavedTreeNavigation(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.url.SetValue("https://api.com/users")
+	m.saveCurrentRequest("API/users/list")
+	m.url.SetValue("")
+	m.loadedPath = ""
+
+	// Start collapsed, as after a restart
+	m.expanded = map[string]bool{}
+	m.pane = paneSidebar
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updated.(model)
+	if len(m.tree) != 1 {
+		t.Fatalf("collapsed tree rows = %d, want 1", len(m.tree))
+	}
+
+	// Enter expands the collection, then the folder
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if len(m.tree) != 3 {
+		t.Fatalf("expanded tree rows = %d, want 3", len(m.tree))
+	}
+
+	// Enter on the request loads it
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.url.Value() != "https://api.com/users" {
+		t.Errorf("url = %q, want %q", m.url.Value(), "https://api.com/users")
+	}
+	if m.loadedPath != "API/users/list" {
+		t.Errorf("loadedPath = %q, want %q", m.loadedPath, "API/users/list")
+	}
+
+	// 'r' renames the folder and keeps the loaded path in sync
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updated.(model)
+	if m.promptKind != promptRenameNode {
+		t.Fatalf("promptKind = %v, want promptRenameNode", m.promptKind)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-v2")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if _, ok := lookupRequest(m.collections, []string{"API", "users-v2", "list"}); !ok {
+		t.Error("request should live under the renamed folder")
+	}
+	if m.loadedPath != "API/users-v2/list" {
+		t.Errorf("loadedPath = %q, want %q", m.loadedPath, "API/users-v2/list")
+	}
+	if !m.expanded["API/users-v2"] {
+		t.Error("renamed folder should stay expanded")
+	}
+
+	// 'd' asks for confirmation, anything but y keeps the folder
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	if _, ok := lookupRequest(m.collections, []string{"API", "users-v2", "list"}); !ok {
+		t.Fatal("folder should survive a declined delete")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	if _, f := lookupFolder(m.collections, []string{"API", "users-v2"}); f != nil {
+		t.Error("folder should be deleted")
+	}
+	if m.loadedPath != "" {
+		t.Errorf("loadedPath = %q, want empty after deleting its folder", m.loadedPath)
+	}
+
+	loaded, _ := loadCollections()
+	if _, f := lookupFolder(loaded, []string{"API", "users-v2"}); f != nil {
+		t.Error("delete should persist to disk")
+	}
+}
+
+// TestSavedTreeMoveRequest tests moving a request between folders
+func TestSavedTreeMoveRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.url.SetValue("https://api.com/a")
+	m.saveCurrentRequest("API/a")
+	m.createCollection("Other")
+	m.pane = paneSidebar
+	m.sidebarTab = sidebarSaved
+	m.refreshTree()
+
+	// Mark the request for moving
+	m.selectTreeNode([]string{"API", "a"})
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	m = updated.(model)
+	if m.moving != "API/a" {
+		t.Fatalf("moving = %q, want %q", m.moving, "API/a")
+	}
+
+	// Paste into the other collection
+	m.selectTreeNode([]string{"Other"})
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updated.(model)
+
+	if _, ok := lookupRequest(m.collections, []string{"API", "a"}); ok {
+		t.Error("request should be removed from its old folder")
+	}
+	if _, ok := lookupRequest(m.collections, []string{"Other", "a"}); !ok {
+		t.Error("request should be in its new folder")
+	}
+	if m.loadedPath != "Other/a" {
+		t.Errorf("loadedPath = %q, want %q", m.loadedPath, "Other/a")
+	}
+
+	loaded, _ := loadCollections()
+	if _, ok := lookupRequest(loaded, []string{"Other", "a"}); !ok {
+		t.Error("move should persist to disk")
+	}
+}
+
+// TestSavedTreeCreateFolder tests creating folders and collections from the sidebar
+func TestSavedTreeCreateFolder(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneSidebar
+	m.sidebarTab = sidebarSaved
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Payments")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if findCollection(m.collections, "Payments") == nil {
+		t.Fatal("collection was not created")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("refunds")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if _, f := lookupFolder(m.collections, []string{"Payments", "refunds"}); f == nil {
+		t.Error("folder was not created inside the selected collection")
+	}
+
+	view := m.viewSidebar()
+	if !containsAny(view, "refunds") {
+		t.Error("sidebar should render the new folder")
+	}
+}
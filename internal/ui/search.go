@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	searchMatchStyle   = "\x1b[7m"      // reverse video
+	searchCurrentStyle = "\x1b[30;103m" // black on bright yellow
+	sgrReset           = "\x1b[0m"
+)
+
+// ansiPattern matches terminal escape sequences in rendered text
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;:?]*[ -/]*[@-~]`)
+
+// searchMatch is a hit in the response, as byte offsets into a line's text
+// with the escape sequences removed
+type searchMatch struct {
+	line, start, end int
+}
+
+// findMatches finds every occurrence of query in rendered text. A query in
+// lower case matches regardless of case.
+func findMatches(content, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	fold := strings.ToLower(query) == query
+	var matches []searchMatch
+	for i, line := range strings.Split(content, "\n") {
+		plain := ansiPattern.ReplaceAllString(line, "")
+		if fold {
+			// Offsets only carry over if lowering kept the byte length
+			if lower := strings.ToLower(plain); len(lower) == len(plain) {
+				plain = lower
+			}
+		}
+		for from := 0; ; {
+			j := strings.Index(plain[from:], query)
+			if j < 0 {
+				break
+			}
+			start := from + j
+			matches = append(matches, searchMatch{line: i, start: start, end: start + len(query)})
+			from = start + len(query)
+		}
+	}
+	return matches
+}
+
+// highlightMatches marks the matches in rendered text, the current one
+// standing out, while keeping the text's own colours intact
+func highlightMatches(content string, matches []searchMatch, current int) string {
+	if len(matches) == 0 {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(matches); {
+		// Matches are ordered, so each line's matches are a contiguous run
+		j := i
+		for j < len(matches) && matches[j].line == matches[i].line {
+			j++
+		}
+		lines[matches[i].line] = highlightLine(lines[matches[i].line], matches[i:j], current-i)
+		i = j
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightLine wraps the matches of one line in highlight styles. Escape
+// sequences inside a match are kept and the highlight re-applied after
+// them; after a match the line's own styles are restored.
+func highlightLine(line string, matches []searchMatch, current int) string {
+	escapes := ansiPattern.FindAllStringIndex(line, -1)
+	var b strings.Builder
+	var active []string // styles in effect, replayed after a match
+	style := ""
+	p, mi, ei := 0, 0, 0 // offset in the plain text, next match, next escape
+	for i := 0; ; {
+		if style != "" && p == matches[mi].end {
+			b.WriteString(sgrReset + strings.Join(active, ""))
+			style = ""
+			mi++
+		}
+		if style == "" && mi < len(matches) && p == matches[mi].start {
+			style = searchMatchStyle
+			if mi == current {
+				style = searchCurrentStyle
+			}
+			b.WriteString(style)
+		}
+		if i >= len(line) {
+			break
+		}
+		if ei < len(escapes) && escapes[ei][0] == i {
+			seq := line[i:escapes[ei][1]]
+			b.WriteString(seq)
+			if strings.HasSuffix(seq, "m") {
+				if seq == sgrReset || seq == "\x1b[m" {
+					active = nil
+				} else {
+					active = append(active, seq)
+				}
+				if style != "" {
+					b.WriteString(style)
+				}
+			}
+			i = escapes[ei][1]
+			ei++
+			continue
+		}
+		b.WriteByte(line[i])
+		i++
+		p++
+	}
+	if style != "" {
+		b.WriteString(sgrReset)
+	}
+	return b.String()
+}
+
+// setResponseContent shows rendered text in the response viewport, marking
+// search matches in it
+func (m *model) setResponseContent(content string) {
+	m.respContent = content
+	m.respMatches = findMatches(content, m.respSearch.Value())
+	m.respMatchIdx = min(m.respMatchIdx, max(len(m.respMatches)-1, 0))
+	m.view.SetContent(highlightMatches(content, m.respMatches, m.respMatchIdx))
+}
+
+// startResponseSearch opens the search input of the response pane
+func (m *model) startResponseSearch() {
+	m.respSearching = true
+	m.respSearchFrom = m.view.YOffset
+	m.respSearch.SetValue("")
+	m.respSearch.Focus()
+	m.setResponseContent(m.respContent)
+}
+
+// updateResponseSearch handles keys while typing a search, jumping to the
+// first match below where the search started as the query changes
+func (m *model) updateResponseSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.respSearching = false
+		m.respSearch.Blur()
+		return nil
+	case "esc":
+		m.respSearching = false
+		m.respSearch.Blur()
+		m.respSearch.SetValue("")
+		m.setResponseContent(m.respContent)
+		m.view.SetYOffset(m.respSearchFrom)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.respSearch, cmd = m.respSearch.Update(msg)
+	m.respMatches = findMatches(m.respContent, m.respSearch.Value())
+	m.respMatchIdx = 0
+	for i, match := range m.respMatches {
+		if match.line >= m.respSearchFrom {
+			m.respMatchIdx = i
+			break
+		}
+	}
+	m.view.SetContent(highlightMatches(m.respContent, m.respMatches, m.respMatchIdx))
+	if len(m.respMatches) == 0 {
+		m.view.SetYOffset(m.respSearchFrom)
+	} else {
+		m.showMatch()
+	}
+	return cmd
+}
+
+// stepResponseMatch moves to the next (1) or previous (-1) match, wrapping around
+func (m *model) stepResponseMatch(delta int) {
+	if len(m.respMatches) == 0 {
+		if m.respSearch.Value() != "" {
+			m.status = "Pattern not found: " + m.respSearch.Value()
+		}
+		return
+	}
+	n := len(m.respMatches)
+	m.respMatchIdx = ((m.respMatchIdx+delta)%n + n) % n
+	m.view.SetContent(highlightMatches(m.respContent, m.respMatches, m.respMatchIdx))
+	m.showMatch()
+}
+
+// showMatch scrolls the current match into view
+func (m *model) showMatch() {
+	line := m.respMatches[m.respMatchIdx].line
+	if line < m.view.YOffset || line >= m.view.YOffset+m.view.Height {
+		m.view.SetYOffset(max(line-m.view.Height/3, 0))
+	}
+}
+
+// searchCounter describes the search for the status line, e.g. "/id 2/14"
+func (m model) searchCounter() string {
+	query := m.respSearch.Value()
+	if query == "" {
+		return ""
+	}
+	if len(m.respMatches) == 0 {
+		return fmt.Sprintf("/%s no matches", query)
+	}
+	return fmt.Sprintf("/%s %d/%d", query, m.respMatchIdx+1, len(m.respMatches))
+}
+
+// newSearchInput creates the response pane's search input
+func newSearchInput() textinput.Model {
+	in := textinput.New()
+	in.Prompt = "/"
+	in.CharLimit = 256
+	return in
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestFindMatches tests finding hits in coloured text with smart case
+func TestFindMatches(t *testing.T) {
+	content := "\x1b[31m\"id\"\x1b[0m: 1,\n\x1b[31m\"ID\"\x1b[0m: \x1b[32mIdaho\x1b[0m"
+
+	tests := []struct {
+		query string
+		want  []searchMatch
+	}{
+		{"id", []searchMatch{{0, 1, 3}, {1, 1, 3}, {1, 6, 8}}},
+		{"ID", []searchMatch{{1, 1, 3}}},
+		{"\": ", []searchMatch{{0, 3, 6}, {1, 3, 6}}},
+		{"31m", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := findMatches(content, tt.query); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("findMatches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestHighlightMatches tests that highlighting keeps the text's own colours
+func TestHighlightMatches(t *testing.T) {
+	line := "\x1b[31mhello\x1b[0m world"
+	matches := findMatches(line, "lo w")
+
+	got := highlightMatches(line, matches, 0)
+	want := "\x1b[31mhel" + searchCurrentStyle + "lo\x1b[0m" + searchCurrentStyle + " w" + sgrReset + "orld"
+	if got != want {
+		t.Errorf("highlight =\n%q\nwant\n%q", got, want)
+	}
+	if plain := ansiPattern.ReplaceAllString(got, ""); plain != "hello world" {
+		t.Errorf("highlighting changed the text: %q", plain)
+	}
+
+	// The line's colour resumes after a match inside it
+	got = highlightMatches("\x1b[32mabcabc\x1b[0m", findMatches("\x1b[32mabcabc\x1b[0m", "b"), 1)
+	want = "\x1b[32ma" + searchMatchStyle + "b" + sgrReset + "\x1b[32mca" + searchCurrentStyle + "b" + sgrReset + "\x1b[32mc\x1b[0m"
+	if got != want {
+		t.Errorf("highlight =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestResponseSearch tests searching the response pane and stepping through hits
+func TestResponseSearch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+	press := func(msg tea.Msg) {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[5], lines[80] = "needle one", "needle two"
+	press(key("3"))
+	press(httpDoneMsg{Status: "200 OK", StatusCode: 200, Body: strings.Join(lines, "\n"), Headers: http.Header{"Content-Type": {"text/plain"}}})
+
+	press(key("/"))
+	for _, r := range "needle" {
+		press(key(string(r)))
+	}
+	if !strings.Contains(m.View(), "/needle 1/2") {
+		t.Errorf("status should count matches while typing:\n%s", m.View())
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.respSearching {
+		t.Fatal("enter should confirm the search")
+	}
+
+	press(key("n"))
+	if m.respMatchIdx != 1 || m.view.YOffset > 80 || m.view.YOffset+m.view.Height <= 80 {
+		t.Errorf("n should scroll to line 80, match %d at offset %d", m.respMatchIdx, m.view.YOffset)
+	}
+	if !strings.Contains(m.View(), "/needle 2/2") {
+		t.Errorf("status should show the second match:\n%s", m.View())
+	}
+	press(key("n"))
+	press(key("N"))
+	if m.respMatchIdx != 1 {
+		t.Errorf("n then N should wrap around and back, at match %d", m.respMatchIdx)
+	}
+
+	// The search is kept across tabs and cleared with esc
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	if len(m.respMatches) != 0 {
+		t.Errorf("headers tab should have no needles, got %v", m.respMatches)
+	}
+	press(key("/"))
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.searchCounter() != "" || strings.Contains(m.view.View(), searchMatchStyle) {
+		t.Error("esc should clear the search")
+	}
+}
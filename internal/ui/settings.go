@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	settingsFileName = "settings.json"
+
+	defaultTimeoutSec   = 12
+	defaultMaxRedirects = 10
+)
+
+// requestSettings controls how the client sends a request. Unset fields
+// inherit from the global settings, and then from the built-in defaults.
+type requestSettings struct {
+	TimeoutSec      *int  `json:"timeout_sec,omitempty"` // 0 disables the timeout
+	FollowRedirects *bool `json:"follow_redirects,omitempty"`
+	MaxRedirects    *int  `json:"max_redirects,omitempty"`
+	KeepAlive       *bool `json:"keep_alive,omitempty"`
+	Insecure        *bool `json:"insecure,omitempty"` // skip TLS certificate verification
+	Cookies         *bool `json:"cookies,omitempty"`  // send and store cookies with the environment's jar
+
+	// DownloadTo streams the response body to this file, or into this
+	// directory, instead of keeping it in memory. It is set per request only.
+	DownloadTo string `json:"download_to,omitempty"`
+}
+
+// isZero reports whether no setting is overridden
+func (s requestSettings) isZero() bool {
+	return s.TimeoutSec == nil && s.FollowRedirects == nil && s.MaxRedirects == nil &&
+		s.KeepAlive == nil && s.Insecure == nil && s.Cookies == nil && s.DownloadTo == ""
+}
+
+// over returns s with unset fields taken from base. DownloadTo is never
+// inherited, so a stray global value can't divert every response.
+func (s requestSettings) over(base requestSettings) requestSettings {
+	if s.TimeoutSec == nil {
+		s.TimeoutSec = base.TimeoutSec
+	}
+	if s.FollowRedirects == nil {
+		s.FollowRedirects = base.FollowRedirects
+	}
+	if s.MaxRedirects == nil {
+		s.MaxRedirects = base.MaxRedirects
+	}
+	if s.KeepAlive == nil {
+		s.KeepAlive = base.KeepAlive
+	}
+	if s.Insecure == nil {
+		s.Insecure = base.Insecure
+	}
+	if s.Cookies == nil {
+		s.Cookies = base.Cookies
+	}
+	return s
+}
+
+// timeout returns the request timeout, zero meaning no timeout
+func (s requestSettings) timeout() time.Duration {
+	if s.TimeoutSec == nil {
+		return defaultTimeoutSec * time.Second
+	}
+	return time.Duration(max(*s.TimeoutSec, 0)) * time.Second
+}
+
+// followRedirects reports whether redirects are followed
+func (s requestSettings) followRedirects() bool {
+	return s.FollowRedirects == nil || *s.FollowRedirects
+}
+
+// maxRedirects returns how many redirect hops are followed before giving up
+func (s requestSettings) maxRedirects() int {
+	if s.MaxRedirects == nil {
+		return defaultMaxRedirects
+	}
+	return max(*s.MaxRedirects, 0)
+}
+
+// keepAlive reports whether connections are reused between requests
+func (s requestSettings) keepAlive() bool {
+	return s.KeepAlive == nil || *s.KeepAlive
+}
+
+// insecure reports whether TLS certificates are accepted without verification
+func (s requestSettings) insecure() bool {
+	return s.Insecure != nil && *s.Insecure
+}
+
+// cookies reports whether the cookie jar is used
+func (s requestSettings) cookies() bool {
+	return s.Cookies == nil || *s.Cookies
+}
+
+// transportKey identifies a shared transport by the settings that shape it
+type transportKey struct {
+	keepAlive bool
+	insecure  bool
+}
+
+var (
+	transportsMu sync.Mutex
+	transports   = map[transportKey]*http.Transport{}
+)
+
+// transportFor returns a shared transport, so connections are reused
+// between requests with the same settings
+func transportFor(s requestSettings) http.RoundTripper {
+	key := transportKey{keepAlive: s.keepAlive(), insecure: s.insecure()}
+	if key == (transportKey{keepAlive: true}) {
+		return http.DefaultTransport
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if t, ok := transports[key]; ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DisableKeepAlives = !key.keepAlive
+	if key.insecure {
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	transports[key] = t
+	return t
+}
+
+// newHTTPClient builds a client that applies the settings. Downloads get
+// no overall timeout, since streaming a large body takes as long as it
+// takes; executeRequest times them out when data stops arriving instead.
+func newHTTPClient(s requestSettings) *http.Client {
+	client := &http.Client{Timeout: s.timeout(), Transport: transportFor(s)}
+	if s.DownloadTo != "" {
+		client.Timeout = 0
+	}
+
+	follow, hops := s.followRedirects(), s.maxRedirects()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > hops {
+			return fmt.Errorf("stopped after %d redirects", hops)
+		}
+		return nil
+	}
+	return client
+}
+
+// loadSettings reads the global settings from disk
+func loadSettings() (requestSettings, error) {
+	dir, err := getDataDir()
+	if err != nil {
+		return requestSettings{}, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, settingsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return requestSettings{}, nil // Built-in defaults
+		}
+		return requestSettings{}, err
+	}
+
+	var s requestSettings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return requestSettings{}, err
+	}
+	return s, nil
+}
+
+// saveSettings writes the global settings to disk
+func saveSettings(s requestSettings) error {
+	dir, err := getDataDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, settingsFileName), data, 0644)
+}
+
+// settingsField identifies a row in the Settings tab
+type settingsField int
+
+const (
+	setTimeout settingsField = iota
+	setFollowRedirects
+	setMaxRedirects
+	setKeepAlive
+	setInsecure
+	setCookies
+	setDownloadTo
+	settingsFieldCount
+)
+
+// isToggle reports whether the row is an on/off switch rather than an input
+func (f settingsField) isToggle() bool {
+	return f == setFollowRedirects || f == setKeepAlive || f == setInsecure || f == setCookies
+}
+
+// editedSettings returns the settings the Settings tab is editing
+func (m *model) editedSettings() *requestSettings {
+	if m.settingsGlobal {
+		return &m.settings
+	}
+	return &m.reqSettings
+}
+
+// syncSettingsInputs fills the inputs from the edited settings
+func (m *model) syncSettingsInputs() {
+	s := m.editedSettings()
+	m.timeoutInput.SetValue(formatOptionalInt(s.TimeoutSec))
+	m.maxRedirectsInput.SetValue(formatOptionalInt(s.MaxRedirects))
+	m.downloadToInput.SetValue(s.DownloadTo)
+
+	// Show what an empty field falls back to
+	base := requestSettings{}
+	if !m.settingsGlobal {
+		base = m.settings
+	}
+	m.timeoutInput.Placeholder = fmt.Sprintf("%d", int(base.timeout().Seconds()))
+	m.maxRedirectsInput.Placeholder = fmt.Sprintf("%d", base.maxRedirects())
+}
+
+// formatOptionalInt renders an unset value as empty
+func formatOptionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// parseOptionalInt parses a number input, empty meaning unset
+func parseOptionalInt(s string) *int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// intPtr returns a pointer to v, for optional settings
+func intPtr(v int) *int { return &v }
+
+// boolPtr returns a pointer to v, for optional settings
+func boolPtr(v bool) *bool { return &v }
+
+// isDigits reports whether s is non-empty and only contains ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// applySettingsInputs stores the inputs into the edited settings
+func (m *model) applySettingsInputs() {
+	s := m.editedSettings()
+	s.TimeoutSec = parseOptionalInt(m.timeoutInput.Value())
+	s.MaxRedirects = parseOptionalInt(m.maxRedirectsInput.Value())
+	if !m.settingsGlobal {
+		s.DownloadTo = strings.TrimSpace(m.downloadToInput.Value())
+	}
+	m.settingsChanged()
+}
+
+// toggleSetting cycles an on/off row. Per-request rows also have an
+// "inherit" state that defers to the global setting.
+func (m *model) toggleSetting(f settingsField) {
+	s := m.editedSettings()
+	base := requestSettings{}
+	if !m.settingsGlobal {
+		base = m.settings
+	}
+
+	var p **bool
+	var inherited bool
+	switch f {
+	case setFollowRedirects:
+		p, inherited = &s.FollowRedirects, base.followRedirects()
+	case setKeepAlive:
+		p, inherited = &s.KeepAlive, base.keepAlive()
+	case setInsecure:
+		p, inherited = &s.Insecure, base.insecure()
+	case setCookies:
+		p, inherited = &s.Cookies, base.cookies()
+	default:
+		return
+	}
+
+	// Request rows go inherit -> flipped -> inherited value -> inherit
+	cur := inherited
+	if *p != nil {
+		cur = **p
+	}
+	next := !cur
+	if *p != nil && !m.settingsGlobal && cur == inherited {
+		*p = nil
+	} else {
+		*p = &next
+	}
+	m.settingsChanged()
+}
+
+// settingsChanged persists global edits
+func (m *model) settingsChanged() {
+	if !m.settingsGlobal {
+		return
+	}
+	if err := saveSettings(m.settings); err != nil {
+		m.err = err
+	}
+}
+
+// toggleSettingsScope switches the Settings tab between this request and the global settings
+func (m *model) toggleSettingsScope() {
+	m.settingsGlobal = !m.settingsGlobal
+	m.syncSettingsInputs()
+}
+
+// setRequestSettings loads per-request settings into the editor
+func (m *model) setRequestSettings(s requestSettings) {
+	m.reqSettings = s
+	m.syncSettingsInputs()
+}
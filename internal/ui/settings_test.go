@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestRequestSettingsDefaults tests the built-in defaults and inheritance
+func TestRequestSettingsDefaults(t *testing.T) {
+	var s requestSettings
+	if !s.isZero() {
+		t.Error("empty settings should be zero")
+	}
+	if s.timeout() != 12*time.Second {
+		t.Errorf("timeout = %v, want 12s", s.timeout())
+	}
+	if !s.followRedirects() || s.maxRedirects() != 10 || !s.keepAlive() {
+		t.Errorf("defaults = follow %v, max %d, keep-alive %v; want true, 10, true",
+			s.followRedirects(), s.maxRedirects(), s.keepAlive())
+	}
+
+	global := requestSettings{TimeoutSec: intPtr(60), KeepAlive: boolPtr(false)}
+	req := requestSettings{TimeoutSec: intPtr(0), FollowRedirects: boolPtr(false)}
+	got := req.over(global)
+	if got.timeout() != 0 {
+		t.Errorf("timeout = %v, want 0 (disabled) from the request", got.timeout())
+	}
+	if got.followRedirects() {
+		t.Error("follow redirects should come from the request")
+	}
+	if got.keepAlive() {
+		t.Error("keep-alive should be inherited from the global settings")
+	}
+	if got.maxRedirects() != 10 {
+		t.Errorf("max redirects = %d, want the built-in 10", got.maxRedirects())
+	}
+}
+
+// TestDoHTTPRedirectSettings tests following, not following and capping redirects
+func TestDoHTTPRedirectSettings(t *testing.T) {
+	// /hop/N redirects to /hop/N-1 until /hop/0 answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, "/hop/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("arrived"))
+	}))
+	defer server.Close()
+
+	send := func(s requestSettings) httpDoneMsg {
+		return doHTTP(requestSpec{Method: "GET", URL: server.URL + "/hop/3", Settings: s}, nil)().(httpDoneMsg)
+	}
+
+	t.Run("follows by default", func(t *testing.T) {
+		msg := send(requestSettings{})
+		if msg.Err != nil || msg.Body != "arrived" {
+			t.Errorf("got body %q, err %v; want the final response", msg.Body, msg.Err)
+		}
+	})
+
+	t.Run("disabled returns the redirect", func(t *testing.T) {
+		msg := send(requestSettings{FollowRedirects: boolPtr(false)})
+		if msg.Err != nil {
+			t.Fatalf("unexpected error: %v", msg.Err)
+		}
+		if msg.StatusCode != http.StatusFound {
+			t.Errorf("StatusCode = %d, want 302", msg.StatusCode)
+		}
+		if msg.Headers.Get("Location") != "/hop/2" {
+			t.Errorf("Location = %q, want /hop/2", msg.Headers.Get("Location"))
+		}
+	})
+
+	t.Run("max hops", func(t *testing.T) {
+		if msg := send(requestSettings{MaxRedirects: intPtr(3)}); msg.Err != nil {
+			t.Errorf("3 hops with a max of 3 failed: %v", msg.Err)
+		}
+		msg := send(requestSettings{MaxRedirects: intPtr(2)})
+		if msg.Err == nil || !strings.Contains(msg.Err.Error(), "stopped after 2 redirects") {
+			t.Errorf("err = %v, want stopped after 2 redirects", msg.Err)
+		}
+	})
+}
+
+// TestDoHTTPKeepAliveSetting tests that disabling keep-alive opens a new connection each time
+func TestDoHTTPKeepAliveSetting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	send := func(s requestSettings) httpDoneMsg {
+		return doHTTP(requestSpec{Method: "GET", URL: server.URL, Settings: s}, nil)().(httpDoneMsg)
+	}
+
+	send(requestSettings{})
+	if msg := send(requestSettings{}); !msg.Timing.Reused {
+		t.Error("second request with keep-alive should reuse the connection")
+	}
+
+	off := requestSettings{KeepAlive: boolPtr(false)}
+	send(off)
+	if msg := send(off); msg.Timing.Reused {
+		t.Error("request without keep-alive should not reuse a connection")
+	}
+}
+
+// TestNewHTTPClientTimeout tests that the timeout setting reaches the client
+func TestNewHTTPClientTimeout(t *testing.T) {
+	if c := newHTTPClient(requestSettings{TimeoutSec: intPtr(300)}); c.Timeout != 300*time.Second {
+		t.Errorf("Timeout = %v, want 5m", c.Timeout)
+	}
+	if c := newHTTPClient(requestSettings{TimeoutSec: intPtr(0)}); c.Timeout != 0 {
+		t.Errorf("Timeout = %v, want 0 (none)", c.Timeout)
+	}
+}
+
+// TestSettingsTabEditsRequest tests editing per-request settings from the editor
+func TestSettingsTabEditsRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneEditor
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, k := range keys {
+			updated, _ := m.Update(k)
+			m = updated.(model)
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(runes("t"))
+	if m.activeTab != tabSettings || m.editorPart != edSettings {
+		t.Fatalf("'t' should open the Settings tab, got tab %v", m.activeTab)
+	}
+
+	// Type a timeout; letters are ignored
+	press(runes("i"), runes("9"), runes("x"), runes("0"), tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.currentRequest().Settings.TimeoutSec; got == nil || *got != 90 {
+		t.Errorf("TimeoutSec = %v, want 90", got)
+	}
+
+	// Follow redirects cycles inherit -> off -> on -> inherit
+	press(runes("j"), runes(" "))
+	if got := m.reqSettings.FollowRedirects; got == nil || *got {
+		t.Errorf("after one toggle FollowRedirects = %v, want off", got)
+	}
+	press(runes(" "), runes(" "))
+	if m.reqSettings.FollowRedirects != nil {
+		t.Error("after three toggles FollowRedirects should inherit again")
+	}
+
+	view := m.viewSettingsTab()
+	if !strings.Contains(view, "Timeout") || !strings.Contains(view, "on (global)") {
+		t.Errorf("settings tab should show the fields and inherited values, got:\n%s", view)
+	}
+
+	// Settings are saved with the request and restored when it is loaded
+	m.saveCurrentRequest("API/report")
+	m.setRequestSettings(requestSettings{})
+	m.sidebarTab = sidebarSaved
+	m.refreshTree()
+	m.selectTreeNode([]string{"API", "report"})
+	m.activateNode()
+	if got := m.reqSettings.TimeoutSec; got == nil || *got != 90 {
+		t.Errorf("loaded TimeoutSec = %v, want 90", got)
+	}
+	if m.timeoutInput.Value() != "90" {
+		t.Errorf("timeout input = %q, want 90", m.timeoutInput.Value())
+	}
+}
+
+// TestSettingsTabEditsGlobal tests that global settings are persisted and used
+func TestSettingsTabEditsGlobal(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.pane = paneEditor
+	m.activeTab = tabSettings
+	m.resetEditorPartForTab()
+
+	m.toggleSettingsScope()
+	m.settingsIdx = setKeepAlive
+	m.toggleSetting(setKeepAlive)
+	m.settingsIdx = setTimeout
+	m.timeoutInput.SetValue("120")
+	m.applySettingsInputs()
+
+	loaded, err := loadSettings()
+	if err != nil {
+		t.Fatalf("loadSettings failed: %v", err)
+	}
+	if loaded.keepAlive() || loaded.timeout() != 120*time.Second {
+		t.Errorf("persisted settings = keep-alive %v, timeout %v; want false, 2m", loaded.keepAlive(), loaded.timeout())
+	}
+
+	// A fresh session picks them up, and the request only overrides what it sets
+	m = New().(model)
+	m.reqSettings = requestSettings{TimeoutSec: intPtr(5)}
+	got := m.currentRequest().Settings.over(m.settings)
+	if got.timeout() != 5*time.Second || got.keepAlive() {
+		t.Errorf("effective settings = timeout %v, keep-alive %v; want 5s, false", got.timeout(), got.keepAlive())
+	}
+}
+
+// TestHistoryStoresSettings tests that history entries keep per-request settings
+func TestHistoryStoresSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.history = nil
+	m.pane = paneEditor
+	m.url.SetValue("https://example.com/report")
+	m.reqSettings = requestSettings{TimeoutSec: intPtr(300)}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	loaded, _ := loadHistory()
+	if len(loaded) != 1 || loaded[0].Settings.TimeoutSec == nil || *loaded[0].Settings.TimeoutSec != 300 {
+		t.Fatalf("persisted history = %+v, want timeout 300", loaded)
+	}
+
+	// Same request with different settings is a separate entry
+	plain := historyEntry{Method: "GET", URL: "https://example.com/report"}
+	if plain.hash() == loaded[0].hash() {
+		t.Error("settings should be part of the history hash")
+	}
+
+	m.setRequestSettings(requestSettings{})
+	m.loadItem(historyToItems(loaded)[0])
+	if got := m.reqSettings.TimeoutSec; got == nil || *got != 300 {
+		t.Errorf("loaded TimeoutSec = %v, want 300", got)
+	}
+}
@@ -19,10 +19,14 @@ func (m model) viewSidebar() string {
 			content = m.sidebar.View()
 		}
 	case sidebarSaved:
-		emptyStyle := lipgloss.NewStyle().
-			Faint(true).
-			Padding(1, 2)
-		content = emptyStyle.Render("Saved requests\ncoming soon...")
+		if len(m.collections) == 0 {
+			emptyStyle := lipgloss.NewStyle().
+				Faint(true).
+				Padding(1, 2)
+			content = emptyStyle.Render("No saved requests.\nPress s in the editor\nto save one.")
+		} else {
+			content = m.viewSavedTree()
+		}
 	}
 
 	tabs := []string{"[H]istory", "[S]aved"}
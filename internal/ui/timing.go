@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfnilsson/getboy/internal/ui/theme"
+)
+
+// span is a phase of a request, relative to when the request started
+type span struct {
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// requestTiming breaks down where the time of a request went
+type requestTiming struct {
+	DNS      span
+	Connect  span
+	TLS      span
+	Wait     span // request written until first response byte (server processing)
+	Transfer span // first response byte until the body was fully read
+	Total    time.Duration
+	Reused   bool // an idle keep-alive connection was reused
+}
+
+// timingTracer collects httptrace events into a requestTiming.
+// Dial callbacks may run concurrently, so all access is locked.
+type timingTracer struct {
+	mu         sync.Mutex
+	start      time.Time
+	timing     requestTiming
+	remoteAddr string
+
+	dnsStart, connectStart, tlsStart, wroteAt, firstByteAt time.Time
+}
+
+// newTimingTracer starts timing a request now
+func newTimingTracer() *timingTracer {
+	return &timingTracer{start: time.Now()}
+}
+
+// since returns the offset of t from the start of the request
+func (t *timingTracer) since(at time.Time) time.Duration {
+	return at.Sub(t.start)
+}
+
+// clientTrace returns the httptrace hooks feeding this tracer
+func (t *timingTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNS = span{t.since(t.dnsStart), time.Since(t.dnsStart)}
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && t.timing.Connect.Duration == 0 {
+				t.timing.Connect = span{t.since(t.connectStart), time.Since(t.connectStart)}
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Reused = info.Reused
+			t.remoteAddr = info.Conn.RemoteAddr().String()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLS = span{t.since(t.tlsStart), time.Since(t.tlsStart)}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteAt = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByteAt = time.Now()
+			if !t.wroteAt.IsZero() {
+				t.timing.Wait = span{t.since(t.wroteAt), t.firstByteAt.Sub(t.wroteAt)}
+			}
+		},
+	}
+}
+
+// connAddr returns the remote address of the connection the request used
+func (t *timingTracer) connAddr() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remoteAddr
+}
+
+// finish records the end of the body transfer and returns the timing
+func (t *timingTracer) finish() requestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if !t.firstByteAt.IsZero() {
+		t.timing.Transfer = span{t.since(t.firstByteAt), now.Sub(t.firstByteAt)}
+	}
+	t.timing.Total = now.Sub(t.start)
+	return t.timing
+}
+
+// formatDuration renders a duration with a precision suited to its size
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	}
+}
+
+// renderTiming draws a waterfall of the request phases, width columns wide
+func renderTiming(t requestTiming, width int) string {
+	if t.Total == 0 {
+		return "No timing information"
+	}
+	barStyle := lipgloss.NewStyle().Foreground(theme.Current.TabActive)
+
+	phases := []struct {
+		label string
+		span  span
+	}{
+		{"DNS lookup", t.DNS},
+		{"TCP connect", t.Connect},
+		{"TLS handshake", t.TLS},
+		{"Waiting (TTFB)", t.Wait},
+		{"Content transfer", t.Transfer},
+	}
+
+	const labelWidth, durWidth = 17, 10
+	barWidth := max(width-labelWidth-durWidth-2, 10)
+	scale := float64(barWidth) / float64(t.Total)
+
+	var lines []string
+	for i, p := range phases {
+		dur := "-"
+		bar := ""
+		if p.span.Duration > 0 {
+			dur = formatDuration(p.span.Duration)
+			offset := min(int(float64(p.span.Start)*scale), barWidth-1)
+			length := min(max(int(float64(p.span.Duration)*scale), 1), barWidth-offset)
+			bar = strings.Repeat(" ", offset) + barStyle.Render(strings.Repeat("█", length))
+		} else if t.Reused && i < 3 {
+			// Connection setup phases are skipped on a reused connection
+			dur = "reused"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s%*s  ", labelWidth, p.label, durWidth, dur)+bar)
+	}
+	lines = append(lines, fmt.Sprintf("%-*s%*s", labelWidth, "Total", durWidth, formatDuration(t.Total)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestDoHTTPTiming tests that request phases are measured
+func TestDoHTTPTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	result, ok := doHTTP(requestSpec{Method: "GET", URL: server.URL}, nil)().(httpDoneMsg)
+	if !ok {
+		t.Fatal("expected httpDoneMsg")
+	}
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+
+	timing := result.Timing
+	if timing.Connect.Duration <= 0 {
+		t.Errorf("Connect = %v, want > 0 for a fresh connection", timing.Connect.Duration)
+	}
+	if timing.Wait.Duration < 30*time.Millisecond {
+		t.Errorf("Wait = %v, want >= 30ms of server processing", timing.Wait.Duration)
+	}
+	if timing.Wait.Start < timing.Connect.Start {
+		t.Errorf("Wait starts at %v, before Connect at %v", timing.Wait.Start, timing.Connect.Start)
+	}
+	if timing.Total < timing.Wait.Start+timing.Wait.Duration {
+		t.Errorf("Total = %v, shorter than the phases it contains", timing.Total)
+	}
+	// Connecting to an IP literal needs no DNS or TLS
+	if timing.DNS.Duration != 0 || timing.TLS.Duration != 0 {
+		t.Errorf("DNS = %v, TLS = %v, want 0", timing.DNS.Duration, timing.TLS.Duration)
+	}
+}
+
+// TestRenderTiming tests the timing waterfall
+func TestRenderTiming(t *testing.T) {
+	timing := requestTiming{
+		Connect:  span{Start: 0, Duration: 2 * time.Millisecond},
+		Wait:     span{Start: 3 * time.Millisecond, Duration: 40 * time.Millisecond},
+		Transfer: span{Start: 43 * time.Millisecond, Duration: 7 * time.Millisecond},
+		Total:    50 * time.Millisecond,
+	}
+	out := renderTiming(timing, 80)
+
+	for _, want := range []string{"DNS lookup", "TCP connect", "2.0ms", "Waiting (TTFB)", "40.0ms", "Content transfer", "Total", "50.0ms", "█"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("waterfall missing %q:\n%s", want, out)
+		}
+	}
+
+	reused := renderTiming(requestTiming{Reused: true, Wait: timing.Wait, Total: timing.Total}, 80)
+	if strings.Count(reused, "reused") != 3 {
+		t.Errorf("reused connection should mark DNS, connect and TLS as reused:\n%s", reused)
+	}
+
+	if got := renderTiming(requestTiming{}, 80); got != "No timing information" {
+		t.Errorf("empty timing = %q", got)
+	}
+}
+
+// TestFormatDuration tests duration formatting
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		500 * time.Microsecond:  "500µs",
+		1500 * time.Microsecond: "1.5ms",
+		2500 * time.Millisecond: "2.50s",
+	}
+	for d, want := range tests {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+// TestHistoryRecordsDuration tests that the total time is stored with the history entry
+func TestHistoryRecordsDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	m := New().(model)
+	m.history = nil
+	m.pane = paneEditor
+	m.url.SetValue("https://example.com/slow")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(httpDoneMsg{ID: m.reqID, Status: "200 OK", Timing: requestTiming{Total: 1234 * time.Millisecond}})
+	m = updated.(model)
+
+	if m.history[0].DurationMs != 1234 {
+		t.Errorf("DurationMs = %d, want 1234", m.history[0].DurationMs)
+	}
+
+	loaded, _ := loadHistory()
+	if len(loaded) != 1 || loaded[0].DurationMs != 1234 {
+		t.Errorf("persisted history = %+v, want duration 1234ms", loaded)
+	}
+}
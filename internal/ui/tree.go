@@ -0,0 +1,470 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfnilsson/getboy/internal/ui/theme"
+)
+
+// nodeKind identifies the type of a row in the saved tree
+type nodeKind int
+
+const (
+	nodeCollection nodeKind = iota
+	nodeFolder
+	nodeRequest
+)
+
+// treeNode is a visible row in the saved requests tree
+type treeNode struct {
+	kind   nodeKind
+	path   []string // collection, folders and (for requests) the request name
+	depth  int
+	method string // request method, empty for folders
+}
+
+// key returns the node's path as a single string
+func (n treeNode) key() string {
+	return joinPath(n.path...)
+}
+
+// name returns the last segment of the node's path
+func (n treeNode) name() string {
+	return n.path[len(n.path)-1]
+}
+
+// folderPath returns the path of the folder a node is in, or the node
+// itself for folders and collections
+func (n treeNode) folderPath() []string {
+	if n.kind == nodeRequest {
+		return n.path[:len(n.path)-1]
+	}
+	return n.path
+}
+
+// flattenTree lists the visible rows of the saved tree, descending only
+// into expanded collections and folders
+func flattenTree(cols []*collection, expanded map[string]bool) []treeNode {
+	var nodes []treeNode
+	for _, c := range cols {
+		path := []string{c.Name}
+		nodes = append(nodes, treeNode{kind: nodeCollection, path: path})
+		if expanded[c.Name] {
+			nodes = appendFolderNodes(nodes, &c.folder, path, 1, expanded)
+		}
+	}
+	return nodes
+}
+
+// appendFolderNodes appends the sub folders and requests of f, folders first
+func appendFolderNodes(nodes []treeNode, f *folder, path []string, depth int, expanded map[string]bool) []treeNode {
+	for _, sub := range f.Folders {
+		p := append(slices.Clone(path), sub.Name)
+		nodes = append(nodes, treeNode{kind: nodeFolder, path: p, depth: depth})
+		if expanded[joinPath(p...)] {
+			nodes = appendFolderNodes(nodes, sub, p, depth+1, expanded)
+		}
+	}
+	for _, r := range f.Requests {
+		p := append(slices.Clone(path), r.Name)
+		nodes = append(nodes, treeNode{kind: nodeRequest, path: p, depth: depth, method: r.Method})
+	}
+	return nodes
+}
+
+// refreshTree rebuilds the visible tree rows and keeps the cursor in range
+func (m *model) refreshTree() {
+	m.tree = flattenTree(m.collections, m.expanded)
+	if m.treeIdx >= len(m.tree) {
+		m.treeIdx = len(m.tree) - 1
+	}
+	if m.treeIdx < 0 {
+		m.treeIdx = 0
+	}
+}
+
+// selectTreeNode moves the cursor to the node with the given path, if visible
+func (m *model) selectTreeNode(path []string) {
+	key := joinPath(path...)
+	for i, n := range m.tree {
+		if n.key() == key {
+			m.treeIdx = i
+			return
+		}
+	}
+}
+
+// expandPath marks every folder along path as expanded
+func (m *model) expandPath(path []string) {
+	for i := 1; i <= len(path); i++ {
+		m.expanded[joinPath(path[:i]...)] = true
+	}
+}
+
+// selectedNode returns the node under the tree cursor
+func (m model) selectedNode() (treeNode, bool) {
+	if m.treeIdx >= 0 && m.treeIdx < len(m.tree) {
+		return m.tree[m.treeIdx], true
+	}
+	return treeNode{}, false
+}
+
+// activateNode loads a request or toggles a folder
+func (m *model) activateNode() {
+	n, ok := m.selectedNode()
+	if !ok {
+		return
+	}
+	if n.kind != nodeRequest {
+		m.setExpanded(n, !m.expanded[n.key()])
+		return
+	}
+	req, ok := lookupRequest(m.collections, n.path)
+	if !ok {
+		return
+	}
+	m.setRequest(req.requestSpec)
+	m.loadedPath = n.key()
+	m.status = fmt.Sprintf("Loaded '%s'", n.key())
+}
+
+// setExpanded expands or collapses a folder or collection
+func (m *model) setExpanded(n treeNode, open bool) {
+	if n.kind == nodeRequest {
+		return
+	}
+	if open {
+		m.expanded[n.key()] = true
+	} else {
+		delete(m.expanded, n.key())
+	}
+	m.refreshTree()
+}
+
+// collapseNode collapses the selected folder, or moves to its parent
+func (m *model) collapseNode() {
+	n, ok := m.selectedNode()
+	if !ok {
+		return
+	}
+	if n.kind != nodeRequest && m.expanded[n.key()] {
+		m.setExpanded(n, false)
+		return
+	}
+	if len(n.path) > 1 {
+		m.selectTreeNode(n.path[:len(n.path)-1])
+	}
+}
+
+// savePathFor suggests a save path: the loaded request's path, or the
+// folder under the tree cursor
+func (m model) savePathFor() string {
+	if m.loadedPath != "" {
+		return m.loadedPath
+	}
+	if n, ok := m.selectedNode(); ok {
+		return joinPath(n.folderPath()...) + "/"
+	}
+	return ""
+}
+
+// saveCurrentRequest stores the editor state at a slash separated path
+func (m *model) saveCurrentRequest(pathStr string) {
+	path := splitPath(pathStr)
+	cols, c, err := saveRequestAt(m.collections, path, m.currentRequest())
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.collections = cols
+	if len(path) == 1 {
+		path = append([]string{defaultCollectionName}, path...)
+	}
+	m.loadedPath = joinPath(path...)
+	m.expandPath(path[:len(path)-1])
+	m.persistCollection(c, fmt.Sprintf("Saved '%s'", m.loadedPath))
+	m.selectTreeNode(path)
+}
+
+// renameNode renames a collection, folder or request
+func (m *model) renameNode(pathStr, newName string) {
+	path := splitPath(pathStr)
+	if newName == "" || strings.Contains(newName, "/") {
+		m.err = fmt.Errorf("invalid name %q", newName)
+		return
+	}
+	if len(path) == 0 || path[len(path)-1] == newName {
+		return
+	}
+
+	c, parent := lookupFolder(m.collections, path[:len(path)-1])
+	switch {
+	case len(path) == 1:
+		c = findCollection(m.collections, path[0])
+		if c == nil {
+			return
+		}
+		for _, other := range m.collections {
+			if other != c && collectionFileName(other.Name) == collectionFileName(newName) {
+				m.err = fmt.Errorf("a collection named %q already exists", other.Name)
+				return
+			}
+		}
+		c.Name = newName
+		sortCollections(m.collections)
+	case parent == nil:
+		return
+	case parent.requestIndex(path[len(path)-1]) >= 0:
+		if parent.requestIndex(newName) >= 0 {
+			m.err = fmt.Errorf("a request named %q already exists", newName)
+			return
+		}
+		parent.Requests[parent.requestIndex(path[len(path)-1])].Name = newName
+		if c.http != nil {
+			c.http.renameRequest(path[len(path)-1], newName)
+		}
+	case parent.folderIndex(path[len(path)-1]) >= 0:
+		if parent.folderIndex(newName) >= 0 {
+			m.err = fmt.Errorf("a folder named %q already exists", newName)
+			return
+		}
+		parent.Folders[parent.folderIndex(path[len(path)-1])].Name = newName
+	default:
+		return
+	}
+
+	newPath := append(slices.Clone(path[:len(path)-1]), newName)
+	m.renamePrefix(pathStr, joinPath(newPath...))
+	m.persistCollection(c, fmt.Sprintf("Renamed '%s' to '%s'", pathStr, newName))
+	m.selectTreeNode(newPath)
+}
+
+// renamePrefix rewrites expanded state and the loaded path after a rename
+// or move. An empty newKey forgets them instead, for deletions.
+func (m *model) renamePrefix(oldKey, newKey string) {
+	rewrite := func(k string) (string, bool) {
+		switch {
+		case k == oldKey:
+			return newKey, true
+		case strings.HasPrefix(k, oldKey+"/") && newKey != "":
+			return newKey + k[len(oldKey):], true
+		case strings.HasPrefix(k, oldKey+"/"):
+			return "", true
+		}
+		return k, false
+	}
+
+	var renamed []string
+	for k := range m.expanded {
+		if nk, ok := rewrite(k); ok {
+			delete(m.expanded, k)
+			renamed = append(renamed, nk)
+		}
+	}
+	for _, k := range renamed {
+		if k != "" {
+			m.expanded[k] = true
+		}
+	}
+	if nk, ok := rewrite(m.loadedPath); ok {
+		m.loadedPath = nk
+	}
+}
+
+// deleteNode removes a collection, folder or request
+func (m *model) deleteNode(pathStr string) {
+	path := splitPath(pathStr)
+	if len(path) == 0 {
+		return
+	}
+
+	if len(path) == 1 {
+		c := findCollection(m.collections, path[0])
+		if c == nil {
+			return
+		}
+		if err := removeCollectionFile(c); err != nil {
+			m.err = err
+			return
+		}
+		m.collections = slices.DeleteFunc(m.collections, func(o *collection) bool { return o == c })
+		m.renamePrefix(pathStr, "")
+		m.refreshTree()
+		m.status = fmt.Sprintf("Deleted '%s'", pathStr)
+		if c.http != nil {
+			m.status = fmt.Sprintf("Closed '%s'", pathStr) // the file itself is kept
+		}
+		return
+	}
+
+	c, parent := lookupFolder(m.collections, path[:len(path)-1])
+	if parent == nil {
+		return
+	}
+	name := path[len(path)-1]
+	if i := parent.requestIndex(name); i >= 0 {
+		parent.Requests = slices.Delete(parent.Requests, i, i+1)
+	} else if i := parent.folderIndex(name); i >= 0 {
+		parent.Folders = slices.Delete(parent.Folders, i, i+1)
+	} else {
+		return
+	}
+	m.renamePrefix(pathStr, "")
+	m.persistCollection(c, fmt.Sprintf("Deleted '%s'", pathStr))
+}
+
+// createFolder adds a folder inside the folder under the tree cursor
+func (m *model) createFolder(name string) {
+	n, ok := m.selectedNode()
+	if !ok {
+		return
+	}
+	if name == "" || strings.Contains(name, "/") {
+		m.err = fmt.Errorf("invalid folder name %q", name)
+		return
+	}
+	parentPath := n.folderPath()
+	c, parent := lookupFolder(m.collections, parentPath)
+	if parent == nil {
+		return
+	}
+	if c.http != nil {
+		m.err = fmt.Errorf("%s can't hold folders", c.Name)
+		return
+	}
+	if parent.folderIndex(name) >= 0 {
+		m.err = fmt.Errorf("a folder named %q already exists", name)
+		return
+	}
+	parent.ensureFolder(name)
+	m.expandPath(parentPath)
+	m.persistCollection(c, fmt.Sprintf("Created folder '%s'", name))
+	m.selectTreeNode(append(slices.Clone(parentPath), name))
+}
+
+// createCollection adds a new, empty collection
+func (m *model) createCollection(name string) {
+	cols, c, err := addCollection(m.collections, name)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.collections = cols
+	m.persistCollection(c, fmt.Sprintf("Created collection '%s'", c.Name))
+	m.selectTreeNode([]string{c.Name})
+}
+
+// pasteMovingRequest moves the request marked with m into the folder under
+// the tree cursor
+func (m *model) pasteMovingRequest() {
+	n, ok := m.selectedNode()
+	if !ok || m.moving == "" {
+		return
+	}
+	from := splitPath(m.moving)
+	to := n.folderPath()
+	m.moving = ""
+
+	srcCol, src := lookupFolder(m.collections, from[:len(from)-1])
+	dstCol, dst := lookupFolder(m.collections, to)
+	if src == nil || dst == nil {
+		return
+	}
+	if src == dst {
+		return
+	}
+	name := from[len(from)-1]
+	i := src.requestIndex(name)
+	if i < 0 {
+		return
+	}
+	if dst.requestIndex(name) >= 0 {
+		m.err = fmt.Errorf("'%s' already contains a request named %q", joinPath(to...), name)
+		return
+	}
+
+	dst.Requests = append(dst.Requests, src.Requests[i])
+	src.Requests = slices.Delete(src.Requests, i, i+1)
+
+	newPath := append(slices.Clone(to), name)
+	m.renamePrefix(joinPath(from...), joinPath(newPath...))
+	m.expandPath(to)
+	if srcCol != dstCol {
+		if err := writeCollection(srcCol); err != nil {
+			m.err = err
+			return
+		}
+	}
+	m.persistCollection(dstCol, fmt.Sprintf("Moved '%s' to '%s'", name, joinPath(to...)))
+	m.selectTreeNode(newPath)
+}
+
+// persistCollection writes a collection to disk and refreshes the tree
+func (m *model) persistCollection(c *collection, status string) {
+	if err := writeCollection(c); err != nil {
+		m.err = err
+		return
+	}
+	m.status = status
+	m.refreshTree()
+}
+
+// methodAbbrev shortens a method name to fit the tree's method column
+func methodAbbrev(method string) string {
+	switch method {
+	case "PATCH":
+		return "PTCH"
+	case "DELETE":
+		return "DEL"
+	case "OPTIONS":
+		return "OPT"
+	default:
+		return method
+	}
+}
+
+// viewSavedTree renders the visible rows of the saved tree
+func (m model) viewSavedTree() string {
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Current.ListSelectedText)
+
+	width := m.sidebarWidth() - 4
+	visibleRows := max(m.contentHeight()-2, 1)
+	total := len(m.tree)
+
+	// Calculate scroll window to keep selected row visible
+	startIdx := 0
+	if total > visibleRows {
+		startIdx = min(max(m.treeIdx-visibleRows/2, 0), total-visibleRows)
+	}
+	endIdx := min(startIdx+visibleRows, total)
+
+	var lines []string
+	for i := startIdx; i < endIdx; i++ {
+		n := m.tree[i]
+
+		var label string
+		switch n.kind {
+		case nodeRequest:
+			label = fmt.Sprintf("%-4s %s", methodAbbrev(n.method), n.name())
+		default:
+			icon := "▸ "
+			if m.expanded[n.key()] {
+				icon = "▾ "
+			}
+			label = icon + n.name()
+		}
+		if n.key() == m.moving {
+			label += " (moving)"
+		}
+		line := truncateURL(strings.Repeat("  ", n.depth)+label, width)
+
+		if i == m.treeIdx {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -16,6 +16,8 @@ const (
 	edParams
 	edHeaders
 	edBody
+	edAuth
+	edSettings
 )
 
 // headerField tracks which part of a header row is focused
@@ -33,6 +35,9 @@ const (
 	tabParams
 	tabHeaders
 	tabBody
+	tabAuth
+	tabSettings
+	requestTabCount
 )
 
 type sidebarTab int
@@ -42,13 +47,27 @@ const (
 	sidebarSaved
 )
 
+// requestSpec is the persisted description of a request, shared by saved
+// collections and the editor.
+type requestSpec struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Body    string            `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	BodyType string      `json:"body_type,omitempty"` // raw JSON when empty
+	Form     []formField `json:"form,omitempty"`      // URL-encoded and multipart bodies
+	BodyFile string      `json:"body_file,omitempty"` // binary bodies
+
+	Auth     requestAuth     `json:"auth,omitzero"`
+	Settings requestSettings `json:"settings,omitzero"`
+}
+
 type reqItem struct {
-	title   string
-	desc    string
-	method  string
-	url     string
-	body    string
-	headers map[string]string
+	title string
+	desc  string
+	spec  requestSpec
+	hash  string // history entry the item shows, if any
 }
 
 func (i reqItem) Title() string {
@@ -60,5 +79,5 @@ func (i reqItem) Description() string {
 }
 
 func (i reqItem) FilterValue() string {
-	return i.title + " " + i.url
+	return i.title + " " + i.spec.URL
 }
@@ -15,6 +15,30 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// An open prompt captures all keys until confirmed or cancelled
+		if m.promptKind != promptNone {
+			return m.updatePrompt(msg)
+		}
+
+		// An open dialog captures all keys until closed
+		if m.modal != modalNone {
+			return m.updateModal(msg)
+		}
+
+		// A response search or filter captures typing until confirmed or cleared
+		if m.respSearching {
+			return m, m.updateResponseSearch(msg)
+		}
+		if m.respFiltering {
+			return m, m.updateResponseFilter(msg)
+		}
+
+		// ctrl+x, or esc outside insert mode, cancels a request in flight
+		if m.loading && (msg.String() == "ctrl+x" || (msg.String() == "esc" && !m.insertMode)) {
+			m.cancelRequest()
+			return m, nil
+		}
+
 		// Handle escape to exit insert mode first
 		if m.insertMode && msg.String() == "esc" {
 			m.insertMode = false
@@ -43,6 +67,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if msg.String() == "enter" {
 					m.insertMode = false
 					m.applyFocus()
+					// A pasted curl command fills in the whole request
+					if isCurlCommand(m.url.Value()) {
+						m.importCurl(m.url.Value())
+					}
 					return m, nil
 				}
 				m.url, cmd = m.url.Update(msg)
@@ -160,12 +188,38 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, cmd
 			case edBody:
-				// Convert tab key to actual tab character
-				if msg.String() == "tab" {
-					m.body.InsertString("\t")
+				cmd = m.updateBodyInsert(msg)
+			case edAuth:
+				if msg.String() == "enter" {
+					m.insertMode = false
+					m.applyFocus()
+					return m, nil
+				}
+				if in := m.authInput(m.selectedAuthField()); in != nil {
+					*in, cmd = in.Update(msg)
+				}
+			case edSettings:
+				if msg.String() == "enter" {
+					m.insertMode = false
+					m.applyFocus()
+					return m, nil
+				}
+				if m.settingsIdx == setDownloadTo {
+					m.downloadToInput, cmd = m.downloadToInput.Update(msg)
+					m.applySettingsInputs()
+					return m, cmd
+				}
+				// Number fields only accept digits
+				if msg.Type == tea.KeyRunes && !isDigits(string(msg.Runes)) {
 					return m, nil
 				}
-				m.body, cmd = m.body.Update(msg)
+				switch m.settingsIdx {
+				case setTimeout:
+					m.timeoutInput, cmd = m.timeoutInput.Update(msg)
+				case setMaxRedirects:
+					m.maxRedirectsInput, cmd = m.maxRedirectsInput.Update(msg)
+				}
+				m.applySettingsInputs()
 			}
 			return m, cmd
 		}
@@ -188,30 +242,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.insertMode = false
 			m.applyFocus()
 			return m, nil
+		case "e":
+			m.openModal(modalEnvironments)
+			return m, nil
+		case "c":
+			m.openCookies()
+			return m, nil
 		case "enter":
+			if m.pane == paneSidebar && m.sidebarTab == sidebarSaved {
+				m.activateNode()
+				return m, nil
+			}
 			if m.pane == paneSidebar {
 				if it, ok := m.sidebar.SelectedItem().(reqItem); ok {
-					m.setMethod(it.method)
-					m.url.SetValue(it.url)
-					m.body.SetValue(it.body)
-					m.setHeadersFromMap(it.headers)
-					m.status = fmt.Sprintf("Loaded '%s'", it.title)
+					m.loadItem(it)
 				}
 				return m, nil
 			}
+			if isCurlCommand(m.url.Value()) {
+				m.importCurl(m.url.Value())
+				return m, nil
+			}
 			method := m.methodValue()
 			url := m.ensureURL(m.url.Value())
 			if strings.TrimSpace(url) == "" {
 				m.status = "Enter a URL first"
 				return m, nil
 			}
-			headers := m.getHeaders()
+			spec := m.currentRequest()
+			spec.URL = url
 			// Add to history before sending
-			m.addToHistoryAndSave(method, url, m.body.Value(), headers)
-			m.err = nil
-			m.loading = true
+			m.sentHash = m.addToHistoryAndSave(spec)
 			m.status = fmt.Sprintf("%s %s…", method, url)
-			return m, doHTTP(method, url, m.body.Value(), headers)
+			return m, m.sendRequest(spec)
 		}
 
 		var cmd tea.Cmd
@@ -228,14 +291,63 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updateSidebarItems()
 				return m, nil
 			}
+			if m.sidebarTab == sidebarSaved {
+				return m.updateSavedTree(msg)
+			}
+			if !m.sidebar.SettingFilter() {
+				switch msg.String() {
+				case " ":
+					m.toggleHistoryMark()
+					return m, nil
+				case "I":
+					m.openPrompt(promptImportHistory, "Import HAR file into history:", "")
+					return m, nil
+				case "E":
+					m.openExportHistory()
+					return m, nil
+				}
+			}
 			m.sidebar, cmd = m.sidebar.Update(msg)
 			return m, cmd
 		case paneEditor:
 			switch msg.String() {
 			case "i":
+				if m.activeTab == tabSettings && m.settingsIdx.isToggle() {
+					// Switches have nothing to type into
+					m.toggleSetting(m.settingsIdx)
+					return m, nil
+				}
+				if m.activeTab == tabSettings && m.settingsIdx == setDownloadTo && m.settingsGlobal {
+					// Downloads are set per request only
+					return m, nil
+				}
+				if m.activeTab == tabAuth && m.authInput(m.selectedAuthField()) == nil {
+					m.cycleAuthField(m.selectedAuthField())
+					return m, nil
+				}
+				if m.activeTab == tabBody && m.bodyType == bodyNone {
+					return m, nil
+				}
 				m.insertMode = true
 				m.applyFocus()
 				return m, nil
+			case " ":
+				if m.activeTab == tabSettings {
+					m.toggleSetting(m.settingsIdx)
+				}
+				if m.activeTab == tabAuth {
+					m.cycleAuthField(m.selectedAuthField())
+				}
+				if m.activeTab == tabBody {
+					m.cycleBodyType()
+				}
+				return m, nil
+			case "g":
+				// Switch between this request's and the global settings
+				if m.activeTab == tabSettings {
+					m.toggleSettingsScope()
+				}
+				return m, nil
 			case "up", "k":
 				m.prevEditorPart()
 				return m, nil
@@ -262,7 +374,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.activeTab = tabBody
 				m.resetEditorPartForTab()
 				return m, nil
+			case "u":
+				// Switch to Auth tab
+				m.activeTab = tabAuth
+				m.resetEditorPartForTab()
+				return m, nil
+			case "t":
+				// Switch to Settings tab
+				m.activeTab = tabSettings
+				m.resetEditorPartForTab()
+				return m, nil
 			case "r":
+				if m.activeTab == tabAuth {
+					m.forgetOAuthToken()
+					return m, nil
+				}
 				// Toggle raw mode in headers tab
 				if m.activeTab == tabHeaders {
 					if m.headersRaw {
@@ -276,6 +402,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.applyFocus()
 				}
 				return m, nil
+			case "x":
+				// Export as curl or a code snippet
+				m.openExport()
+				return m, nil
+			case "C":
+				// Import a pasted curl command
+				m.openModal(modalImportCurl)
+				return m, nil
+			case "s":
+				// Save the current request to the collection
+				m.openPrompt(promptSaveRequest, "Save as (collection/folder/name):", m.savePathFor())
+				return m, nil
 			case "a":
 				// Add new row in params or headers tab
 				switch m.activeTab {
@@ -284,6 +422,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.syncURLFromParams()
 				case tabHeaders:
 					m.addHeaderRow()
+				case tabBody:
+					if isFormBody(m.bodyType) {
+						m.addFormRow()
+					}
 				}
 				return m, nil
 			case "d":
@@ -294,6 +436,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.syncURLFromParams()
 				case tabHeaders:
 					m.deleteHeaderRow()
+				case tabBody:
+					if isFormBody(m.bodyType) {
+						m.deleteFormRow()
+					}
 				}
 				return m, nil
 			case "l":
@@ -303,28 +449,122 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.paramField = headerValue
 				case tabHeaders:
 					m.headerField = headerValue
+				case tabBody:
+					m.formField = headerValue
+				}
+				return m, nil
+			case "f":
+				// Switch a multipart field between a text value and a file
+				if m.activeTab == tabBody && m.bodyType == bodyMultipart && m.formIdx < len(m.formRows) {
+					row := &m.formRows[m.formIdx]
+					row.setFormFile(!row.file)
 				}
 				return m, nil
 			}
 			// Don't pass unhandled keys to text inputs when not in insert mode
 			return m, nil
 		case paneResponse:
+			switch msg.String() {
+			case "tab":
+				m.nextResponseTab()
+				return m, nil
+			case "shift+tab":
+				m.prevResponseTab()
+				return m, nil
+			case "s":
+				m.openSaveResponse()
+				return m, nil
+			case "m":
+				m.loadMoreResponse()
+				return m, nil
+			case "/":
+				m.startResponseSearch()
+				return m, nil
+			case "f":
+				m.startResponseFilter()
+				return m, nil
+			case "n":
+				m.stepResponseMatch(1)
+				return m, nil
+			case "N":
+				m.stepResponseMatch(-1)
+				return m, nil
+			}
 			m.view, cmd = m.view.Update(msg)
 			return m, cmd
 		}
 
 	case httpDoneMsg:
+		// Ignore responses to cancelled or superseded requests
+		if msg.ID != m.reqID {
+			return m, nil
+		}
 		m.loading = false
+		m.cancel = nil
+		m.resp = &msg
+		m.respPages = 1
+		m.refreshResponseView()
 		if msg.Err != nil {
 			m.err = msg.Err
-			m.view.SetContent(fmt.Sprintf("Error: %v", msg.Err))
 			m.status = "Request failed"
 			return m, nil
 		}
-		m.view.SetContent(renderResponse(msg.Body))
+		m.recordHistoryResponse(m.sentHash, msg)
 		m.status = msg.Status
 		return m, nil
 	}
 
 	return m, nil
 }
+
+// updateSavedTree handles keys in the sidebar's Saved tab
+func (m model) updateSavedTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n, ok := m.selectedNode()
+
+	switch msg.String() {
+	case "up", "k":
+		if m.treeIdx > 0 {
+			m.treeIdx--
+		}
+	case "down", "j":
+		if m.treeIdx < len(m.tree)-1 {
+			m.treeIdx++
+		}
+	case " ", "right":
+		if ok {
+			m.setExpanded(n, true)
+		}
+	case "left":
+		m.collapseNode()
+	case "N":
+		m.openPrompt(promptNewCollection, "New collection:", "")
+	case "I":
+		m.openPrompt(promptImport, "Import OpenAPI, Swagger, Postman or HAR file:", "")
+	case "E":
+		m.openExportCollection()
+	case "n":
+		if ok {
+			m.openPrompt(promptNewFolder, "New folder in '"+joinPath(n.folderPath()...)+"':", "")
+		}
+	case "r":
+		if ok {
+			m.openPrompt(promptRenameNode, "Rename to:", n.name())
+			m.promptTarget = n.key()
+		}
+	case "d":
+		if ok {
+			m.openPrompt(promptDeleteNode, fmt.Sprintf("Delete '%s'?", n.key()), "")
+			m.promptTarget = n.key()
+		}
+	case "m":
+		if ok && n.kind == nodeRequest {
+			m.moving = n.key()
+			m.status = fmt.Sprintf("Moving '%s'", n.name())
+		}
+	case "p":
+		m.pasteMovingRequest()
+	case "esc":
+		m.moving = ""
+	}
+	return m, nil
+}
@@ -14,26 +14,70 @@ func (m model) View() string {
 
 	// ===== Footer / Status ====================================================
 	var status string
-	if m.insertMode {
+	if m.promptKind != promptNone {
+		status = m.viewPrompt()
+	} else if m.respSearching {
+		status = m.respSearch.View() + "  " + m.searchCounter() + "  enter: confirm  esc: clear"
+	} else if m.respFiltering {
+		status = m.respFilter.View() + "  enter: apply (empty clears)  esc: cancel"
+	} else if m.insertMode {
 		status = "-- INSERT --  esc: exit"
 	} else {
 		switch m.pane {
 		case paneSidebar:
 			status = "1/2/3: panes  j/k: select  enter: load"
+			if m.sidebarTab == sidebarHistory {
+				status += "  space: mark  I/E: import/export HAR"
+			}
+			if m.sidebarTab == sidebarSaved {
+				if m.moving != "" {
+					status += "  p: paste here  esc: cancel move"
+				} else {
+					status += "/toggle  n/N: new folder/collection  r: rename  d: delete  m: move  I/E: import/export"
+				}
+			}
 		case paneEditor:
-			status = "1/2/3: panes  i: insert  j/k: fields"
+			status = "1/2/3: panes  i: insert  j/k: fields  s: save  x: export  C: import curl"
 			if m.activeTab == tabParams {
 				status += "  a: add  d: delete"
 			}
 			if m.activeTab == tabHeaders {
 				status += "  a: add  d: delete  r: toggle view"
 			}
+			if m.activeTab == tabBody {
+				status += "  space: body type"
+				if isFormBody(m.bodyType) {
+					status += "  a: add  d: delete"
+				}
+				if m.bodyType == bodyMultipart {
+					status += "  f: file/text"
+				}
+			}
+			if m.activeTab == tabAuth {
+				status += "  space: change type"
+				if m.authType == authOAuth2 {
+					status += "  r: forget token"
+				}
+			}
+			if m.activeTab == tabSettings {
+				status += "  space: toggle  g: request/global"
+			}
 		case paneResponse:
-			status = "1/2/3: panes  j/k: scroll"
+			status = "1/2/3: panes  j/k: scroll  tab: body/headers/info/timing  /: search  n/N: next/prev  f: filter  s: save body"
+			if m.respFilterExpr != "" {
+				status += "  ·  filter: " + m.respFilterExpr
+			}
+			if counter := m.searchCounter(); counter != "" {
+				status += "  ·  " + counter
+			}
 		}
+		status += "  e: env  c: cookies"
+	}
+	if m.envs.hasActive() {
+		status += "  ·  env: " + m.envs.Active
 	}
 	if m.loading {
-		status += "  ·  loading…"
+		status += "  ·  loading…  ctrl+x: cancel"
 	}
 	if m.err != nil {
 		status += "  ·  error: " + m.err.Error()
@@ -42,5 +86,8 @@ func (m model) View() string {
 
 	// ===== Layout =============================================================
 	content := lipgloss.JoinHorizontal(lipgloss.Top, sbBox, right)
+	if m.modal != modalNone {
+		content = m.viewModal()
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, content, footer)
 }
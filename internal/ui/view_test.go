@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"net/http"
 	"strings"
 	"testing"
 
@@ -275,3 +276,75 @@ func TestViewNoSelectionIndicatorInOtherPanes(t *testing.T) {
 		t.Error("editor view should not show selection indicator when not in editor pane")
 	}
 }
+
+// TestViewResponseTabs tests switching between response body, headers and info
+func TestViewResponseTabs(t *testing.T) {
+	m := New().(model)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+
+	updated, _ = m.Update(httpDoneMsg{
+		Status:        "200 OK",
+		StatusCode:    200,
+		Proto:         "HTTP/2.0",
+		Body:          "plain body",
+		Headers:       http.Header{"X-Cache": {"HIT"}, "Access-Control-Allow-Origin": {"*"}},
+		ContentLength: 10,
+		Size:          10,
+		FinalURL:      "https://example.com/final",
+		RemoteAddr:    "93.184.216.34:443",
+		TLSVersion:    "TLS 1.3",
+	})
+	m = updated.(model)
+	m.pane = paneResponse
+
+	if !strings.Contains(m.viewResponse(), "plain body") {
+		t.Error("body tab should show the response body")
+	}
+
+	// tab switches to headers
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if m.respTab != respHeaders {
+		t.Fatalf("respTab = %v, want respHeaders", m.respTab)
+	}
+	view := m.viewResponse()
+	for _, want := range []string{"X-Cache:", "HIT", "Access-Control-Allow-Origin:"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("headers tab missing %q", want)
+		}
+	}
+
+	// tab again switches to info
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	view = m.viewResponse()
+	for _, want := range []string{"HTTP/2.0", "93.184.216.34:443", "TLS 1.3", "10 B", "https://example.com/final"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("info tab missing %q", want)
+		}
+	}
+
+	// shift+tab goes back
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	m = updated.(model)
+	if m.respTab != respHeaders {
+		t.Errorf("respTab = %v, want respHeaders after shift+tab", m.respTab)
+	}
+}
+
+// TestFormatBytes tests human readable byte sizes
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		512:             "512 B",
+		1024:            "1.0 KB",
+		1536:            "1.5 KB",
+		1024 * 1024 * 3: "3.0 MB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML decodes a YAML document to the same types as encoding/json with
+// UseNumber
+func parseYAML(data string) (any, error) {
+	var v any
+	if err := yaml.Unmarshal([]byte(data), &v); err != nil {
+		return nil, err
+	}
+	return normalizeYAML(v), nil
+}
+
+// normalizeYAML converts the maps with non-string keys, numbers and
+// timestamps that yaml.v3 decodes to their encoding/json equivalents
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeYAML(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeYAML(e)
+		}
+		return v
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil // JSON has no NaN or infinity
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		if v.Equal(v.Truncate(24*time.Hour)) && v.Location() == time.UTC {
+			return v.Format(time.DateOnly) // an unquoted date such as 2024-01-01
+		}
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}